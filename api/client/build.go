@@ -65,6 +65,7 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	flCPUSetCpus := cmd.String([]string{"-cpuset-cpus"}, "", "CPUs in which to allow execution (0-3, 0,1)")
 	flCPUSetMems := cmd.String([]string{"-cpuset-mems"}, "", "MEMs in which to allow execution (0-3, 0,1)")
 	flCgroupParent := cmd.String([]string{"-cgroup-parent"}, "", "Optional parent cgroup for the container")
+	flShmSize := cmd.String([]string{"-shm-size"}, "", "Size of /dev/shm, default value is 64MB")
 	flBuildArg := opts.NewListOpts(opts.ValidateEnv)
 	cmd.Var(&flBuildArg, []string{"-build-arg"}, "Set build-time variables")
 
@@ -201,6 +202,19 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 			memorySwap = parsedMemorySwap
 		}
 	}
+
+	var shmSize int64
+	if *flShmSize != "" {
+		parsedShmSize, err := units.RAMInBytes(*flShmSize)
+		if err != nil {
+			return err
+		}
+		if parsedShmSize <= 0 {
+			return fmt.Errorf("--shm-size: SHM size must be greater than 0")
+		}
+		shmSize = parsedShmSize
+	}
+
 	// Send the build context
 	v := &url.Values{}
 
@@ -250,6 +264,7 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	v.Set("memory", strconv.FormatInt(memory, 10))
 	v.Set("memswap", strconv.FormatInt(memorySwap, 10))
 	v.Set("cgroupparent", *flCgroupParent)
+	v.Set("shmsize", strconv.FormatInt(shmSize, 10))
 
 	v.Set("dockerfile", relDockerfile)
 
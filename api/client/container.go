@@ -0,0 +1,69 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/units"
+)
+
+// CmdContainer is the parent subcommand for all container commands
+//
+// Usage: docker container <COMMAND> <OPTS>
+func (cli *DockerCli) CmdContainer(args ...string) error {
+	description := "Manage containers\n\nCommands:\n"
+	commands := [][]string{
+		{"prune", "Remove all stopped containers"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker container COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("container", []string{"[COMMAND]"}, description, true)
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return nil
+}
+
+// CmdContainerPrune removes all the stopped containers matching the filters.
+//
+// Usage: docker container prune [OPTIONS]
+func (cli *DockerCli) CmdContainerPrune(args ...string) error {
+	cmd := Cli.Subcmd("container prune", nil, "Remove all stopped containers", true)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'until=24h')")
+	cmd.Require(flag.Exact, 0)
+
+	cmd.ParseFlags(args, true)
+
+	pruneFilters, err := parsePruneFilters(flFilter.GetAll())
+	if err != nil {
+		return err
+	}
+
+	if !cli.confirmPrune("This will remove all stopped containers", *force) {
+		return nil
+	}
+
+	var report types.ContainersPruneReport
+	if err := cli.prune("/containers/prune", pruneFilters, &report); err != nil {
+		return err
+	}
+
+	if len(report.ContainersDeleted) > 0 {
+		fmt.Fprintln(cli.out, "Deleted Containers:")
+		for _, id := range report.ContainersDeleted {
+			fmt.Fprintln(cli.out, id)
+		}
+		fmt.Fprintln(cli.out)
+	}
+	fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(float64(report.SpaceReclaimed)))
+	return nil
+}
@@ -1,9 +1,15 @@
 package client
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	eventtypes "github.com/docker/docker/api/types/events"
 	Cli "github.com/docker/docker/cli"
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
@@ -52,12 +58,63 @@ func (cli *DockerCli) CmdEvents(args ...string) error {
 		}
 		v.Set("filters", filterJSON)
 	}
-	sopts := &streamOpts{
-		rawTerminal: true,
-		out:         cli.out,
-	}
-	if _, err := cli.stream("GET", "/events?"+v.Encode(), sopts); err != nil {
+	serverResp, err := cli.call("GET", "/events?"+v.Encode(), nil, nil)
+	if err != nil {
 		return err
 	}
+	defer serverResp.body.Close()
+
+	return streamEvents(serverResp.body, cli.out)
+}
+
+// streamEvents decodes the events from input and prints them to output.
+func streamEvents(input io.Reader, output io.Writer) error {
+	dec := json.NewDecoder(input)
+	for {
+		var event eventtypes.Message
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		printOutput(event, output)
+	}
 	return nil
 }
+
+// printOutput prints the event with its time, its type, its action, the ID
+// of its actor and its attributes sorted by name. The events of the daemons
+// before they had a type are printed as they used to be.
+func printOutput(event eventtypes.Message, output io.Writer) {
+	if event.TimeNano != 0 {
+		fmt.Fprintf(output, "%s ", time.Unix(0, event.TimeNano).Format(timeutils.RFC3339NanoFixed))
+	} else if event.Time != 0 {
+		fmt.Fprintf(output, "%s ", time.Unix(event.Time, 0).Format(timeutils.RFC3339NanoFixed))
+	}
+
+	if event.Type == "" {
+		if event.From != "" {
+			fmt.Fprintf(output, "%s: (from %s) %s\n", event.ID, event.From, event.Status)
+		} else {
+			fmt.Fprintf(output, "%s: %s\n", event.ID, event.Status)
+		}
+		return
+	}
+
+	fmt.Fprintf(output, "%s %s %s", event.Type, event.Action, event.Actor.ID)
+
+	if len(event.Actor.Attributes) > 0 {
+		var attrs []string
+		var keys []string
+		for k := range event.Actor.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			attrs = append(attrs, fmt.Sprintf("%s=%s", k, event.Actor.Attributes[k]))
+		}
+		fmt.Fprintf(output, " (%s)", strings.Join(attrs, ", "))
+	}
+	fmt.Fprint(output, "\n")
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/units"
+)
+
+// CmdImage is the parent subcommand for all image commands
+//
+// Usage: docker image <COMMAND> <OPTS>
+func (cli *DockerCli) CmdImage(args ...string) error {
+	description := "Manage images\n\nCommands:\n"
+	commands := [][]string{
+		{"prune", "Remove unused images"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker image COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("image", []string{"[COMMAND]"}, description, true)
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return nil
+}
+
+// CmdImagePrune removes the dangling images, or all the images which no
+// container uses, matching the filters.
+//
+// Usage: docker image prune [OPTIONS]
+func (cli *DockerCli) CmdImagePrune(args ...string) error {
+	cmd := Cli.Subcmd("image prune", nil, "Remove unused images", true)
+	all := cmd.Bool([]string{"a", "-all"}, false, "Remove all unused images, not just dangling ones")
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'until=24h')")
+	cmd.Require(flag.Exact, 0)
+
+	cmd.ParseFlags(args, true)
+
+	pruneFilters, err := parsePruneFilters(flFilter.GetAll())
+	if err != nil {
+		return err
+	}
+
+	warning := "This will remove all dangling images"
+	if *all {
+		pruneFilters["dangling"] = []string{"false"}
+		warning = "This will remove all images without at least one container associated to them"
+	}
+	if !cli.confirmPrune(warning, *force) {
+		return nil
+	}
+
+	var report types.ImagesPruneReport
+	if err := cli.prune("/images/prune", pruneFilters, &report); err != nil {
+		return err
+	}
+
+	if len(report.ImagesDeleted) > 0 {
+		fmt.Fprintln(cli.out, "Deleted Images:")
+		for _, del := range report.ImagesDeleted {
+			if del.Deleted != "" {
+				fmt.Fprintf(cli.out, "Deleted: %s\n", del.Deleted)
+			} else {
+				fmt.Fprintf(cli.out, "Untagged: %s\n", del.Untagged)
+			}
+		}
+		fmt.Fprintln(cli.out)
+	}
+	fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(float64(report.SpaceReclaimed)))
+	return nil
+}
@@ -62,6 +62,16 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 		fmt.Fprintf(cli.out, " Docker Root Dir: %s\n", info.DockerRootDir)
 	}
 
+	if len(info.UIDMaps) > 0 {
+		fmt.Fprintln(cli.out, "User Namespace Remapping:")
+		for _, m := range info.UIDMaps {
+			fmt.Fprintf(cli.out, " UID Map: %d %d %d\n", m.ContainerID, m.HostID, m.Size)
+		}
+		for _, m := range info.GIDMaps {
+			fmt.Fprintf(cli.out, " GID Map: %d %d %d\n", m.ContainerID, m.HostID, m.Size)
+		}
+	}
+
 	ioutils.FprintfIfNotEmpty(cli.out, "Http Proxy: %s\n", info.HTTPProxy)
 	ioutils.FprintfIfNotEmpty(cli.out, "Https Proxy: %s\n", info.HTTPSProxy)
 	ioutils.FprintfIfNotEmpty(cli.out, "No Proxy: %s\n", info.NoProxy)
@@ -83,6 +93,9 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 			if !info.SwapLimit {
 				fmt.Fprintf(cli.err, "WARNING: No swap limit support\n")
 			}
+			if !info.PidsLimit {
+				fmt.Fprintf(cli.err, "WARNING: No pids limit support\n")
+			}
 			if !info.IPv4Forwarding {
 				fmt.Fprintf(cli.err, "WARNING: IPv4 forwarding is disabled\n")
 			}
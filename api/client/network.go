@@ -3,6 +3,12 @@
 package client
 
 import (
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
 	nwclient "github.com/docker/libnetwork/client"
 )
 
@@ -12,3 +18,39 @@ func (cli *DockerCli) CmdNetwork(args ...string) error {
 	args = append([]string{"network"}, args...)
 	return nCli.Cmd("docker", args...)
 }
+
+// CmdNetworkPrune removes all the networks matching the filters which no
+// container is connected to, but for the ones created by the daemon.
+//
+// Usage: docker network prune [OPTIONS]
+func (cli *DockerCli) CmdNetworkPrune(args ...string) error {
+	cmd := Cli.Subcmd("network prune", nil, "Remove all unused networks", true)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'until=24h')")
+	cmd.Require(flag.Exact, 0)
+
+	cmd.ParseFlags(args, true)
+
+	pruneFilters, err := parsePruneFilters(flFilter.GetAll())
+	if err != nil {
+		return err
+	}
+
+	if !cli.confirmPrune("This will remove all networks not used by at least one container", *force) {
+		return nil
+	}
+
+	var report types.NetworksPruneReport
+	if err := cli.prune("/networks/prune", pruneFilters, &report); err != nil {
+		return err
+	}
+
+	if len(report.NetworksDeleted) > 0 {
+		fmt.Fprintln(cli.out, "Deleted Networks:")
+		for _, name := range report.NetworksDeleted {
+			fmt.Fprintln(cli.out, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+// confirmPrune asks the user whether to go on with the removal described by
+// warning, unless force is set. Anything but a yes, including no answer at
+// all, cancels the removal.
+func (cli *DockerCli) confirmPrune(warning string, force bool) bool {
+	if force {
+		return true
+	}
+
+	fmt.Fprintf(cli.out, "WARNING! %s.\nAre you sure you want to continue? [y/N] ", warning)
+	answer, _ := bufio.NewReader(cli.in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// parsePruneFilters parses the filter flags of a prune command.
+func parsePruneFilters(flFilters []string) (filters.Args, error) {
+	pruneFilters := filters.Args{}
+	for _, f := range flFilters {
+		var err error
+		pruneFilters, err = filters.ParseFlag(f, pruneFilters)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pruneFilters, nil
+}
+
+// prune sends a prune request with the filters to the path, and decodes the
+// report of the daemon in report.
+func (cli *DockerCli) prune(path string, pruneFilters filters.Args, report interface{}) error {
+	v := url.Values{}
+	if len(pruneFilters) > 0 {
+		filterJSON, err := filters.ToParam(pruneFilters)
+		if err != nil {
+			return err
+		}
+		v.Set("filters", filterJSON)
+	}
+
+	serverResp, err := cli.call("POST", path+"?"+v.Encode(), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	defer serverResp.body.Close()
+
+	return json.NewDecoder(serverResp.body).Decode(report)
+}
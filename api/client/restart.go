@@ -19,8 +19,11 @@ func (cli *DockerCli) CmdRestart(args ...string) error {
 
 	cmd.ParseFlags(args, true)
 
+	// Without -t, the daemon uses the stop timeout of the container.
 	v := url.Values{}
-	v.Set("t", strconv.Itoa(*nSeconds))
+	if cmd.IsSet("t") || cmd.IsSet("-time") {
+		v.Set("t", strconv.Itoa(*nSeconds))
+	}
 
 	var errNames []string
 	for _, name := range cmd.Args() {
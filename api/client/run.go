@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
@@ -8,6 +9,7 @@ import (
 	"runtime"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
 	Cli "github.com/docker/docker/cli"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/promise"
@@ -53,7 +55,6 @@ func (cli *DockerCli) CmdRun(args ...string) error {
 
 		ErrConflictAttachDetach               = fmt.Errorf("Conflicting options: -a and -d")
 		ErrConflictRestartPolicyAndAutoRemove = fmt.Errorf("Conflicting options: --restart and --rm")
-		ErrConflictDetachAutoRemove           = fmt.Errorf("Conflicting options: --rm and -d")
 	)
 
 	config, hostConfig, cmd, err := runconfig.Parse(cmd, args)
@@ -90,16 +91,15 @@ func (cli *DockerCli) CmdRun(args ...string) error {
 				return ErrConflictAttachDetach
 			}
 		}
-		if *flAutoRemove {
-			return ErrConflictDetachAutoRemove
-		}
-
 		config.AttachStdin = false
 		config.AttachStdout = false
 		config.AttachStderr = false
 		config.StdinOnce = false
 	}
 
+	// The container is removed by the daemon once it exits
+	hostConfig.AutoRemove = *flAutoRemove
+
 	// Disable flSigProxy when in TTY mode
 	sigProxy := *flSigProxy
 	if config.Tty {
@@ -190,13 +190,15 @@ func (cli *DockerCli) CmdRun(args ...string) error {
 		}
 	}
 
-	defer func() {
-		if *flAutoRemove {
-			if _, _, err = readBody(cli.call("DELETE", "/containers/"+createResponse.ID+"?v=1", nil, nil)); err != nil {
-				fmt.Fprintf(cli.err, "Error deleting container: %s\n", err)
-			}
+	// The container is gone once it exited, so its exit code has to be
+	// waited for before it is started.
+	var waitResp *serverResponse
+	if *flAutoRemove && (config.AttachStdout || config.AttachStderr) {
+		if waitResp, err = cli.call("POST", "/containers/"+createResponse.ID+"/wait", nil, nil); err != nil {
+			return err
 		}
-	}()
+		defer waitResp.body.Close()
+	}
 
 	//start the container
 	if _, _, err = readBody(cli.call("POST", "/containers/"+createResponse.ID+"/start", nil, nil)); err != nil {
@@ -227,14 +229,13 @@ func (cli *DockerCli) CmdRun(args ...string) error {
 
 	// Attached mode
 	if *flAutoRemove {
-		// Autoremove: wait for the container to finish, retrieve
-		// the exit code and remove the container
-		if _, _, err := readBody(cli.call("POST", "/containers/"+createResponse.ID+"/wait", nil, nil)); err != nil {
-			return err
-		}
-		if _, status, err = getExitCode(cli, createResponse.ID); err != nil {
+		// Autoremove: the daemon removes the container, retrieve the
+		// exit code it had
+		var res types.ContainerWaitResponse
+		if err := json.NewDecoder(waitResp.body).Decode(&res); err != nil {
 			return err
 		}
+		status = res.StatusCode
 	} else {
 		// No Autoremove: Simply retrieve the exit code
 		if !config.Tty {
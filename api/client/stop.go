@@ -11,7 +11,7 @@ import (
 
 // CmdStop stops one or more running containers.
 //
-// A running container is stopped by first sending SIGTERM and then SIGKILL if the container fails to stop within a grace period (the default is 10 seconds).
+// A running container is stopped by first sending SIGTERM and then SIGKILL if the container fails to stop within a grace period (the default is the stop timeout of the container, 10 seconds unless set).
 //
 // Usage: docker stop [OPTIONS] CONTAINER [CONTAINER...]
 func (cli *DockerCli) CmdStop(args ...string) error {
@@ -21,8 +21,11 @@ func (cli *DockerCli) CmdStop(args ...string) error {
 
 	cmd.ParseFlags(args, true)
 
+	// Without -t, the daemon uses the stop timeout of the container.
 	v := url.Values{}
-	v.Set("t", strconv.Itoa(*nSeconds))
+	if cmd.IsSet("t") || cmd.IsSet("-time") {
+		v.Set("t", strconv.Itoa(*nSeconds))
+	}
 
 	var errNames []string
 	for _, name := range cmd.Args() {
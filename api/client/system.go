@@ -0,0 +1,176 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/docker/pkg/units"
+)
+
+// CmdSystem is the parent subcommand for all system commands
+//
+// Usage: docker system <COMMAND> <OPTS>
+func (cli *DockerCli) CmdSystem(args ...string) error {
+	description := "Manage Docker\n\nCommands:\n"
+	commands := [][]string{
+		{"df", "Show docker disk usage"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker system COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("system", []string{"[COMMAND]"}, description, true)
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return nil
+}
+
+// CmdSystemDf shows the disk used by the images, the containers and the
+// volumes, and how much of it can be reclaimed by removing those not in use.
+//
+// Usage: docker system df [OPTIONS]
+func (cli *DockerCli) CmdSystemDf(args ...string) error {
+	cmd := Cli.Subcmd("system df", nil, "Show docker disk usage", true)
+	verbose := cmd.Bool([]string{"v", "-verbose"}, false, "Show detailed information on space usage")
+	cmd.Require(flag.Exact, 0)
+
+	cmd.ParseFlags(args, true)
+
+	serverResp, err := cli.call("GET", "/system/df", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	defer serverResp.body.Close()
+
+	du := types.DiskUsage{}
+	if err := json.NewDecoder(serverResp.body).Decode(&du); err != nil {
+		return err
+	}
+
+	if *verbose {
+		cli.printVerboseDiskUsage(du)
+		return nil
+	}
+
+	var activeImages int
+	for _, i := range du.Images {
+		if i.Containers > 0 {
+			activeImages++
+		}
+	}
+
+	var activeContainers int
+	var containersSize, reclaimableContainersSize int64
+	for _, c := range du.Containers {
+		if c.SizeRw > 0 {
+			containersSize += c.SizeRw
+		}
+		if c.Running {
+			activeContainers++
+		} else if c.SizeRw > 0 {
+			reclaimableContainersSize += c.SizeRw
+		}
+	}
+
+	var localVolumes, activeVolumes int
+	var volumesSize, reclaimableVolumesSize int64
+	for _, v := range du.Volumes {
+		if v.Size < 0 {
+			continue
+		}
+		localVolumes++
+		volumesSize += v.Size
+		if v.RefCount > 0 {
+			activeVolumes++
+		} else {
+			reclaimableVolumesSize += v.Size
+		}
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+	fmt.Fprintf(w, "Images\t%d\t%d\t%s\t%s\n", len(du.Images), activeImages, units.HumanSize(float64(du.LayersSize)), reclaimable(du.ReclaimableLayersSize, du.LayersSize))
+	fmt.Fprintf(w, "Containers\t%d\t%d\t%s\t%s\n", len(du.Containers), activeContainers, units.HumanSize(float64(containersSize)), reclaimable(reclaimableContainersSize, containersSize))
+	fmt.Fprintf(w, "Local Volumes\t%d\t%d\t%s\t%s\n", localVolumes, activeVolumes, units.HumanSize(float64(volumesSize)), reclaimable(reclaimableVolumesSize, volumesSize))
+	w.Flush()
+	return nil
+}
+
+// reclaimable formats the reclaimable part of a size, with its percentage.
+func reclaimable(reclaimable, size int64) string {
+	percent := 0
+	if size > 0 {
+		percent = int(reclaimable * 100 / size)
+	}
+	return fmt.Sprintf("%s (%d%%)", units.HumanSize(float64(reclaimable)), percent)
+}
+
+// printVerboseDiskUsage lists the space used by every image, container and
+// local volume.
+func (cli *DockerCli) printVerboseDiskUsage(du types.DiskUsage) {
+	now := time.Now().UTC()
+
+	fmt.Fprintln(cli.out, "Images space usage:")
+	fmt.Fprintln(cli.out)
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tSIZE\tSHARED SIZE\tUNIQUE SIZE\tCONTAINERS")
+	for _, i := range du.Images {
+		created := units.HumanDuration(now.Sub(time.Unix(i.Created, 0)))
+		repoTags := i.RepoTags
+		if len(repoTags) == 0 {
+			repoTags = []string{"<none>:<none>"}
+		}
+		for _, repoTag := range repoTags {
+			repo, tag := parsers.ParseRepositoryTag(repoTag)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\t%s\t%s\t%d\n", repo, tag, stringid.TruncateID(i.ID), created,
+				units.HumanSize(float64(i.Size)), units.HumanSize(float64(i.SharedSize)), units.HumanSize(float64(i.Size-i.SharedSize)), i.Containers)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintln(cli.out)
+	fmt.Fprintln(cli.out, "Containers space usage:")
+	fmt.Fprintln(cli.out)
+	w = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tCOMMAND\tSIZE\tCREATED\tSTATUS\tNAMES")
+	for _, c := range du.Containers {
+		size := "N/A"
+		if c.SizeRw >= 0 {
+			size = units.HumanSize(float64(c.SizeRw))
+		}
+		names := make([]string, len(c.Names))
+		for i, name := range c.Names {
+			names[i] = strings.TrimPrefix(name, "/")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s ago\t%s\t%s\n", stringid.TruncateID(c.ID), stringutils.Truncate(c.Image, 12),
+			strconv.Quote(stringutils.Truncate(c.Command, 20)), size, units.HumanDuration(now.Sub(time.Unix(c.Created, 0))), c.Status, strings.Join(names, ","))
+	}
+	w.Flush()
+
+	fmt.Fprintln(cli.out)
+	fmt.Fprintln(cli.out, "Local Volumes space usage:")
+	fmt.Fprintln(cli.out)
+	w = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "VOLUME NAME\tLINKS\tSIZE")
+	for _, v := range du.Volumes {
+		if v.Size < 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", v.Name, v.RefCount, units.HumanSize(float64(v.Size)))
+	}
+	w.Flush()
+}
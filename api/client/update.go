@@ -0,0 +1,50 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/runconfig"
+)
+
+// CmdUpdate updates the resources of one or more containers.
+//
+// Usage: docker update [OPTIONS] CONTAINER [CONTAINER...]
+func (cli *DockerCli) CmdUpdate(args ...string) error {
+	cmd := Cli.Subcmd("update", []string{"CONTAINER [CONTAINER...]"}, "Update resources of one or more containers", true)
+
+	updateConfig, names, err := runconfig.ParseUpdate(cmd, args)
+	if err != nil {
+		cmd.ReportError(err.Error(), true)
+		return Cli.StatusError{StatusCode: 1}
+	}
+
+	var errNames []string
+	for _, name := range names {
+		serverResp, err := cli.call("POST", "/containers/"+name+"/update", updateConfig, nil)
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			errNames = append(errNames, name)
+			continue
+		}
+
+		var response types.ContainerUpdateResponse
+		err = json.NewDecoder(serverResp.body).Decode(&response)
+		serverResp.body.Close()
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			errNames = append(errNames, name)
+			continue
+		}
+		for _, warning := range response.Warnings {
+			fmt.Fprintf(cli.err, "WARNING: %s\n", warning)
+		}
+		fmt.Fprintf(cli.out, "%s\n", name)
+	}
+	if len(errNames) > 0 {
+		return fmt.Errorf("Error: failed to update containers: %v", errNames)
+	}
+	return nil
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/units"
+	"github.com/docker/docker/runconfig"
 )
 
 // CmdVolume is the parent subcommand for all volume commands
@@ -25,6 +27,7 @@ func (cli *DockerCli) CmdVolume(args ...string) error {
 		{"create", "Create a volume"},
 		{"inspect", "Return low-level information on a volume"},
 		{"ls", "List volumes"},
+		{"prune", "Remove all unused volumes"},
 		{"rm", "Remove a volume"},
 	}
 
@@ -181,6 +184,8 @@ func (cli *DockerCli) CmdVolumeCreate(args ...string) error {
 
 	flDriverOpts := opts.NewMapOpts(nil, nil)
 	cmd.Var(flDriverOpts, []string{"o", "-opt"}, "Set driver specific options")
+	flLabels := opts.NewListOpts(opts.ValidateEnv)
+	cmd.Var(&flLabels, []string{"-label"}, "Set metadata for a volume")
 
 	cmd.Require(flag.Exact, 0)
 	cmd.ParseFlags(args, true)
@@ -188,6 +193,7 @@ func (cli *DockerCli) CmdVolumeCreate(args ...string) error {
 	volReq := &types.VolumeCreateRequest{
 		Driver:     *flDriver,
 		DriverOpts: flDriverOpts.GetAll(),
+		Labels:     runconfig.ConvertKVStringsToMap(flLabels.GetAll()),
 	}
 
 	if *flName != "" {
@@ -231,3 +237,41 @@ func (cli *DockerCli) CmdVolumeRm(args ...string) error {
 	}
 	return nil
 }
+
+// CmdVolumePrune removes all the volumes matching the filters which no
+// container uses.
+//
+// Usage: docker volume prune [OPTIONS]
+func (cli *DockerCli) CmdVolumePrune(args ...string) error {
+	cmd := Cli.Subcmd("volume prune", nil, "Remove all unused volumes", true)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'until=24h')")
+	cmd.Require(flag.Exact, 0)
+
+	cmd.ParseFlags(args, true)
+
+	pruneFilters, err := parsePruneFilters(flFilter.GetAll())
+	if err != nil {
+		return err
+	}
+
+	if !cli.confirmPrune("This will remove all volumes not used by at least one container", *force) {
+		return nil
+	}
+
+	var report types.VolumesPruneReport
+	if err := cli.prune("/volumes/prune", pruneFilters, &report); err != nil {
+		return err
+	}
+
+	if len(report.VolumesDeleted) > 0 {
+		fmt.Fprintln(cli.out, "Deleted Volumes:")
+		for _, name := range report.VolumesDeleted {
+			fmt.Fprintln(cli.out, name)
+		}
+		fmt.Fprintln(cli.out)
+	}
+	fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(float64(report.SpaceReclaimed)))
+	return nil
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "engine_daemon_api_request_duration_seconds",
+	Help: "The duration of the API requests, by method and route. The streaming requests last until the stream is closed.",
+}, []string{"method", "route"})
+
+func init() {
+	prometheus.MustRegister(apiRequestDuration)
+}
+
+// instrumentHandler observes the duration of the requests to the route.
+func instrumentHandler(route string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		handler(w, r)
+		apiRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
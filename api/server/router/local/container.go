@@ -1,6 +1,7 @@
 package local
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -196,7 +197,12 @@ func (s *router) postContainersStop(ctx context.Context, w http.ResponseWriter,
 		return fmt.Errorf("Missing parameter")
 	}
 
-	seconds, _ := strconv.Atoi(r.Form.Get("t"))
+	// Without t, the stop timeout of the container applies.
+	var seconds *int
+	if t := r.Form.Get("t"); t != "" {
+		valSeconds, _ := strconv.Atoi(t)
+		seconds = &valSeconds
+	}
 
 	if err := s.daemon.ContainerStop(vars["name"], seconds); err != nil {
 		return err
@@ -250,7 +256,12 @@ func (s *router) postContainersRestart(ctx context.Context, w http.ResponseWrite
 		return fmt.Errorf("Missing parameter")
 	}
 
-	timeout, _ := strconv.Atoi(r.Form.Get("t"))
+	// Without t, the stop timeout of the container applies.
+	var timeout *int
+	if t := r.Form.Get("t"); t != "" {
+		valTimeout, _ := strconv.Atoi(t)
+		timeout = &valTimeout
+	}
 
 	if err := s.daemon.ContainerRestart(vars["name"], timeout); err != nil {
 		return err
@@ -300,12 +311,21 @@ func (s *router) postContainersWait(ctx context.Context, w http.ResponseWriter,
 		return fmt.Errorf("Missing parameter")
 	}
 
-	status, err := s.daemon.ContainerWait(vars["name"], -1*time.Second)
+	wait, err := s.daemon.ContainerWaiter(vars["name"])
 	if err != nil {
 		return err
 	}
 
-	return httputils.WriteJSON(w, http.StatusOK, &types.ContainerWaitResponse{
+	// Send the status code straight away, so that the client knows the
+	// container is watched before it starts it.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outStream := ioutils.NewWriteFlusher(w)
+	outStream.Flush()
+
+	// The wait cannot time out, so it cannot fail either.
+	status, _ := wait(-1 * time.Second)
+	return json.NewEncoder(outStream).Encode(&types.ContainerWaitResponse{
 		StatusCode: status,
 	})
 }
@@ -357,6 +377,32 @@ func (s *router) postContainerRename(ctx context.Context, w http.ResponseWriter,
 	return nil
 }
 
+func (s *router) postContainerUpdate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	if err := httputils.CheckForJSON(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	var updateConfig runconfig.UpdateConfig
+	if err := json.NewDecoder(r.Body).Decode(&updateConfig); err != nil {
+		return err
+	}
+
+	warnings, err := s.daemon.ContainerUpdate(vars["name"], &updateConfig)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, &types.ContainerUpdateResponse{
+		Warnings: warnings,
+	})
+}
+
 func (s *router) postContainersCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -382,6 +428,18 @@ func (s *router) postContainersCreate(ctx context.Context, w http.ResponseWriter
 	return httputils.WriteJSON(w, http.StatusCreated, ccr)
 }
 
+func (s *router) postContainersPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneReport, err := s.daemon.ContainersPrune(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
+}
+
 func (s *router) deleteContainers(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -227,6 +227,18 @@ func (s *router) postImagesLoad(ctx context.Context, w http.ResponseWriter, r *h
 	return s.daemon.Repositories().Load(r.Body, w)
 }
 
+func (s *router) postImagesPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneReport, err := s.daemon.ImagesPrune(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
+}
+
 func (s *router) deleteImages(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -314,6 +326,7 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	buildConfig.CPUSetCpus = r.FormValue("cpusetcpus")
 	buildConfig.CPUSetMems = r.FormValue("cpusetmems")
 	buildConfig.CgroupParent = r.FormValue("cgroupparent")
+	buildConfig.ShmSize = httputils.Int64ValueOrZero(r, "shmsize")
 
 	var buildUlimits = []*ulimit.Ulimit{}
 	ulimitsJSON := r.FormValue("ulimits")
@@ -402,7 +415,8 @@ func (s *router) postImagesTag(ctx context.Context, w http.ResponseWriter, r *ht
 	if err := s.daemon.Repositories().Tag(repo, tag, name, force); err != nil {
 		return err
 	}
-	s.daemon.EventsService.Log("tag", utils.ImageReference(repo, tag), "")
+	ref := utils.ImageReference(repo, tag)
+	s.daemon.LogImageEvent(ref, ref, "tag")
 	w.WriteHeader(http.StatusCreated)
 	return nil
 }
@@ -5,14 +5,15 @@ import (
 	"net/http"
 	"runtime"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/autogen/dockerversion"
+	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/parsers/filters"
@@ -54,6 +55,15 @@ func (s *router) getInfo(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return httputils.WriteJSON(w, http.StatusOK, info)
 }
 
+func (s *router) getSystemDiskUsage(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	du, err := s.daemon.SystemDiskUsage()
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, du)
+}
+
 func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -83,34 +93,27 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 		timer = time.NewTimer(dur)
 	}
 
-	ef, err := filters.FromParam(r.Form.Get("filters"))
+	eventFilters, err := filters.FromParam(r.Form.Get("filters"))
 	if err != nil {
 		return err
 	}
 
-	isFiltered := func(field string, filter []string) bool {
-		if len(field) == 0 {
-			return false
-		}
-		if len(filter) == 0 {
-			return false
-		}
-		for _, v := range filter {
-			if v == field {
-				return false
-			}
-			if strings.Contains(field, ":") {
-				image := strings.Split(field, ":")
-				if image[0] == v {
-					return false
-				}
-			}
+	d := s.daemon
+	es := d.EventsService
+
+	// The containers are also matched by the ID of the one currently known
+	// by the name or the partial ID given, whose past events may have been
+	// logged under another name.
+	for _, cn := range eventFilters["container"] {
+		if c, err := d.Get(cn); err == nil {
+			eventFilters["container"] = append(eventFilters["container"], c.ID)
 		}
-		return true
 	}
+	ef := events.NewFilter(eventFilters)
+	// The clients of the API before 1.21 only know about the events of the
+	// containers and the images, without a type nor an actor.
+	legacy := httputils.VersionFromContext(ctx).LessThan("1.21")
 
-	d := s.daemon
-	es := d.EventsService
 	w.Header().Set("Content-Type", "application/json")
 
 	outStream := ioutils.NewWriteFlusher(w)
@@ -120,41 +123,41 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 	outStream.Write(nil)
 	enc := json.NewEncoder(outStream)
 
-	getContainerID := func(cn string) string {
-		c, err := d.Get(cn)
-		if err != nil {
-			return ""
-		}
-		return c.ID
-	}
-
-	sendEvent := func(ev *jsonmessage.JSONMessage) error {
-		//incoming container filter can be name,id or partial id, convert and replace as a full container id
-		for i, cn := range ef["container"] {
-			ef["container"][i] = getContainerID(cn)
-		}
-
-		if isFiltered(ev.Status, ef["event"]) || (isFiltered(ev.ID, ef["image"]) &&
-			isFiltered(ev.From, ef["image"])) || isFiltered(ev.ID, ef["container"]) {
+	sendEvent := func(ev *eventtypes.Message) error {
+		if !ef.Include(ev) {
 			return nil
 		}
-
+		if legacy {
+			if ev.Type != eventtypes.ContainerEventType && ev.Type != eventtypes.ImageEventType {
+				return nil
+			}
+			return enc.Encode(&jsonmessage.JSONMessage{
+				Status:   ev.Status,
+				ID:       ev.ID,
+				From:     ev.From,
+				Time:     ev.Time,
+				TimeNano: ev.TimeNano,
+			})
+		}
 		return enc.Encode(ev)
 	}
 
-	current, l := es.Subscribe()
+	// The past events are streamed as they are replayed from the journal of
+	// the daemon, if any.
+	var l chan interface{}
 	if since == -1 {
-		current = nil
-	}
-	defer es.Evict(l)
-	for _, ev := range current {
-		if ev.Time < since {
-			continue
+		_, l = es.Subscribe()
+	} else {
+		var untilTime time.Time
+		if until > 0 {
+			untilTime = time.Unix(until, 0)
 		}
-		if err := sendEvent(ev); err != nil {
+		l, err = es.SubscribeSince(time.Unix(since, 0), untilTime, sendEvent)
+		if err != nil {
 			return err
 		}
 	}
+	defer es.Evict(l)
 
 	var closeNotify <-chan bool
 	if closeNotifier, ok := w.(http.CloseNotifier); ok {
@@ -164,7 +167,7 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 	for {
 		select {
 		case ev := <-l:
-			jev, ok := ev.(*jsonmessage.JSONMessage)
+			jev, ok := ev.(*eventtypes.Message)
 			if !ok {
 				continue
 			}
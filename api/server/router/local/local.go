@@ -31,6 +31,11 @@ func (l localRoute) Handler() httputils.APIFunc {
 	return l.handler
 }
 
+// Path returns the path of the route
+func (l localRoute) Path() string {
+	return l.path
+}
+
 // Register adds the filtered handler to the mux.
 func (l localRoute) Register(m *mux.Router, handler http.Handler) {
 	logrus.Debugf("Registering %s, %s", l.method, l.path)
@@ -98,6 +103,7 @@ func (r *router) initRoutes() {
 		NewGetRoute("/_ping", pingHandler),
 		NewGetRoute("/events", r.getEvents),
 		NewGetRoute("/info", r.getInfo),
+		NewGetRoute("/system/df", r.getSystemDiskUsage),
 		NewGetRoute("/version", r.getVersion),
 		NewGetRoute("/images/json", r.getImagesJSON),
 		NewGetRoute("/images/search", r.getImagesSearch),
@@ -123,9 +129,11 @@ func (r *router) initRoutes() {
 		NewPostRoute("/build", r.postBuild),
 		NewPostRoute("/images/create", r.postImagesCreate),
 		NewPostRoute("/images/load", r.postImagesLoad),
+		NewPostRoute("/images/prune", r.postImagesPrune),
 		NewPostRoute("/images/{name:.*}/push", r.postImagesPush),
 		NewPostRoute("/images/{name:.*}/tag", r.postImagesTag),
 		NewPostRoute("/containers/create", r.postContainersCreate),
+		NewPostRoute("/containers/prune", r.postContainersPrune),
 		NewPostRoute("/containers/{name:.*}/kill", r.postContainersKill),
 		NewPostRoute("/containers/{name:.*}/pause", r.postContainersPause),
 		NewPostRoute("/containers/{name:.*}/unpause", r.postContainersUnpause),
@@ -140,7 +148,9 @@ func (r *router) initRoutes() {
 		NewPostRoute("/exec/{name:.*}/start", r.postContainerExecStart),
 		NewPostRoute("/exec/{name:.*}/resize", r.postContainerExecResize),
 		NewPostRoute("/containers/{name:.*}/rename", r.postContainerRename),
+		NewPostRoute("/containers/{name:.*}/update", r.postContainerUpdate),
 		NewPostRoute("/volumes", r.postVolumesCreate),
+		NewPostRoute("/volumes/prune", r.postVolumesPrune),
 		// PUT
 		NewPutRoute("/containers/{name:.*}/archive", r.putContainersArchive),
 		// DELETE
@@ -148,6 +158,7 @@ func (r *router) initRoutes() {
 		NewDeleteRoute("/images/{name:.*}", r.deleteImages),
 		NewDeleteRoute("/volumes/{name:.*}", r.deleteVolumes),
 	}
+	r.routes = append(r.routes, r.networkRoutes()...)
 }
 
 func optionsHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -0,0 +1,32 @@
+// +build experimental
+
+package local
+
+import (
+	"net/http"
+
+	"github.com/docker/docker/api/server/httputils"
+	dkrouter "github.com/docker/docker/api/server/router"
+	"golang.org/x/net/context"
+)
+
+// networkRoutes returns the routes of the networks handled by this router.
+// The networks are otherwise managed through the network router, which does
+// not handle them.
+func (r *router) networkRoutes() []dkrouter.Route {
+	return []dkrouter.Route{
+		NewPostRoute("/networks/prune", r.postNetworksPrune),
+	}
+}
+
+func (s *router) postNetworksPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneReport, err := s.daemon.NetworksPrune(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
+}
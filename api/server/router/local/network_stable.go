@@ -0,0 +1,11 @@
+// +build !experimental
+
+package local
+
+import dkrouter "github.com/docker/docker/api/server/router"
+
+// networkRoutes returns no route, as the networks are only managed through
+// the API in the experimental builds.
+func (r *router) networkRoutes() []dkrouter.Route {
+	return nil
+}
@@ -47,7 +47,7 @@ func (s *router) postVolumesCreate(ctx context.Context, w http.ResponseWriter, r
 		return err
 	}
 
-	volume, err := s.daemon.VolumeCreate(req.Name, req.Driver, req.DriverOpts)
+	volume, err := s.daemon.VolumeCreate(req.Name, req.Driver, req.DriverOpts, req.Labels)
 	if err != nil {
 		return err
 	}
@@ -64,3 +64,15 @@ func (s *router) deleteVolumes(ctx context.Context, w http.ResponseWriter, r *ht
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
+
+func (s *router) postVolumesPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneReport, err := s.daemon.VolumesPrune(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
+}
@@ -24,3 +24,8 @@ type networkRoute struct {
 func (l networkRoute) Handler() httputils.APIFunc {
 	return l.handler
 }
+
+// Path returns the path prefix of the route
+func (l networkRoute) Path() string {
+	return l.path
+}
@@ -3,11 +3,16 @@
 package network
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"golang.org/x/net/context"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/router"
 	"github.com/docker/docker/daemon"
 	"github.com/docker/libnetwork/api"
@@ -33,13 +38,84 @@ func NewRouter(d *daemon.Daemon) router.Router {
 		return nil
 	}
 
-	for _, path := range []string{"/networks", "/services", "/sandboxes"} {
+	for _, path := range []string{"/services", "/sandboxes"} {
 		routes = append(routes, networkRoute{path, handler})
 	}
+	routes = append(routes, networkRoute{"/networks", networksHandler(d, netHandler)})
 
 	return networkRouter{routes}
 }
 
+// networkLabels is the part of a network creation request the daemon keeps
+// along with the network, as libnetwork does not.
+type networkLabels struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// networksHandler wraps the handler of the networks to log the events of the
+// networks created and removed through the API, and to record their labels.
+func networksHandler(d *daemon.Daemon, netHandler http.HandlerFunc) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		c := d.NetworkController()
+		path := r.URL.Path[strings.Index(r.URL.Path, "/networks")+len("/networks"):]
+
+		switch {
+		case r.Method == "POST" && strings.Trim(path, "/") == "":
+			// The body is read first for the labels, and handed over.
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				return err
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			rec := &responseRecorder{ResponseWriter: w}
+			netHandler(rec, r)
+			var id string
+			if rec.status != http.StatusCreated || json.Unmarshal(rec.body.Bytes(), &id) != nil {
+				return nil
+			}
+			var create networkLabels
+			json.Unmarshal(body, &create)
+			d.NetworkCreated(id, create.Labels)
+			if n, err := c.NetworkByID(id); err == nil {
+				d.LogNetworkEvent(n.ID(), n.Name(), n.Type(), "create")
+			}
+		case r.Method == "DELETE" && path != "" && !strings.Contains(strings.Trim(path, "/"), "/"):
+			// The network is looked up before it is removed.
+			n, err := c.NetworkByID(strings.Trim(path, "/"))
+			rec := &responseRecorder{ResponseWriter: w}
+			netHandler(rec, r)
+			if err == nil && rec.status == http.StatusOK {
+				d.NetworkRemoved(n.ID())
+				d.LogNetworkEvent(n.ID(), n.Name(), n.Type(), "destroy")
+			}
+		default:
+			netHandler(w, r)
+		}
+		return nil
+	}
+}
+
+// responseRecorder writes a response through while keeping its status and
+// its body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
 // Register adds the filtered handler to the mux.
 func (n networkRoute) Register(m *mux.Router, handler http.Handler) {
 	logrus.Debugf("Registering %s, %v", n.path, httpMethods)
@@ -22,4 +22,6 @@ type Route interface {
 	Register(*mux.Router, http.Handler)
 	// Handler returns the raw function to create the http handler.
 	Handler() httputils.APIFunc
+	// Path returns the path of the route, without the version prefix.
+	Path() string
 }
@@ -179,7 +179,7 @@ func (s *Server) CreateMux() *mux.Router {
 	logrus.Debugf("Registering routers")
 	for _, router := range s.routers {
 		for _, r := range router.Routes() {
-			f := s.makeHTTPHandler(r.Handler())
+			f := instrumentHandler(r.Path(), s.makeHTTPHandler(r.Handler()))
 			r.Register(m, f)
 		}
 	}
@@ -0,0 +1,11 @@
+// Package logdriver defines the records the daemon exchanges with the log
+// driver plugins, and their encoding.
+package logdriver
+
+// LogEntry is a line logged by a container, sent to the plugins on the FIFO
+// given to /LogDriver.StartLogging and read from them by /LogDriver.ReadLogs.
+type LogEntry struct {
+	Source   string // Source is stdout or stderr
+	TimeNano int64  // TimeNano is the time of the line in nanoseconds since the epoch
+	Line     []byte // Line is the content of the line, without its newline
+}
@@ -0,0 +1,77 @@
+package logdriver
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MaxEntrySize is the maximum size of an encoded entry. Larger sizes are
+// taken for a corrupted stream.
+const MaxEntrySize = 1 << 20
+
+// LogEntryEncoder writes the entries to a stream, each one prefixed by the
+// size of its JSON encoding as a 4 bytes big endian integer.
+type LogEntryEncoder struct {
+	w io.Writer
+}
+
+// NewLogEntryEncoder returns an encoder writing to w.
+func NewLogEntryEncoder(w io.Writer) *LogEntryEncoder {
+	return &LogEntryEncoder{w: w}
+}
+
+// Encode writes the entry. The size and the encoding are written at once so
+// that entries written concurrently by several encoders to a FIFO are not
+// interleaved, as long as they fit in its buffer.
+func (e *LogEntryEncoder) Encode(entry *LogEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if len(b) > MaxEntrySize {
+		return fmt.Errorf("log entry of %d bytes exceeds the maximum size of %d bytes", len(b), MaxEntrySize)
+	}
+	buf := make([]byte, 4+len(b))
+	binary.BigEndian.PutUint32(buf, uint32(len(b)))
+	copy(buf[4:], b)
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// LogEntryDecoder reads the entries written by a LogEntryEncoder.
+type LogEntryDecoder struct {
+	r   io.Reader
+	buf []byte
+}
+
+// NewLogEntryDecoder returns a decoder reading from r.
+func NewLogEntryDecoder(r io.Reader) *LogEntryDecoder {
+	return &LogEntryDecoder{r: r}
+}
+
+// Decode reads the next entry into entry. It returns io.EOF at the end of
+// the stream, and io.ErrUnexpectedEOF if it ends within an entry.
+func (d *LogEntryDecoder) Decode(entry *LogEntry) error {
+	var size [4]byte
+	if _, err := io.ReadFull(d.r, size[:]); err != nil {
+		return err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > MaxEntrySize {
+		return fmt.Errorf("log entry of %d bytes exceeds the maximum size of %d bytes", n, MaxEntrySize)
+	}
+	if uint32(cap(d.buf)) < n {
+		d.buf = make([]byte, n)
+	}
+	buf := d.buf[:n]
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	*entry = LogEntry{}
+	return json.Unmarshal(buf, entry)
+}
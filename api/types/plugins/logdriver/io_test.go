@@ -0,0 +1,54 @@
+package logdriver
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLogEntryEncodeDecode(t *testing.T) {
+	entries := []*LogEntry{
+		{Source: "stdout", TimeNano: 1445000000000000000, Line: []byte("first line")},
+		{Source: "stderr", TimeNano: 1445000000000000001, Line: []byte{}},
+		{Source: "stdout", TimeNano: 1445000000000000002, Line: []byte("third\x00line")},
+	}
+	var buf bytes.Buffer
+	enc := NewLogEntryEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dec := NewLogEntryDecoder(&buf)
+	for _, expected := range entries {
+		var e LogEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&e, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, e)
+		}
+	}
+	var e LogEntry
+	if err := dec.Decode(&e); err != io.EOF {
+		t.Fatalf("Expected EOF, got %v", err)
+	}
+}
+
+func TestLogEntryDecodeTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewLogEntryEncoder(&buf).Encode(&LogEntry{Source: "stdout", Line: []byte("line")}); err != nil {
+		t.Fatal(err)
+	}
+	truncated := buf.Bytes()[:buf.Len()-2]
+
+	var e LogEntry
+	if err := NewLogEntryDecoder(bytes.NewReader(truncated)).Decode(&e); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected an unexpected EOF, got %v", err)
+	}
+	if err := NewLogEntryDecoder(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff})).Decode(&e); err == nil {
+		t.Fatal("Expected an error with an entry exceeding the maximum size")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/version"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
@@ -41,6 +42,13 @@ type ContainerWaitResponse struct {
 	StatusCode int `json:"StatusCode"`
 }
 
+// ContainerUpdateResponse contains response of Remote API:
+// POST "/containers/"+containerID+"/update"
+type ContainerUpdateResponse struct {
+	// Warnings are any warnings encountered during the update of the container.
+	Warnings []string `json:"Warnings"`
+}
+
 // ContainerCommitResponse contains response of Remote API:
 // POST "/commit?container="+containerID
 type ContainerCommitResponse struct {
@@ -195,6 +203,7 @@ type Info struct {
 	Debug              bool
 	NFd                int
 	OomKillDisable     bool
+	PidsLimit          bool
 	NGoroutines        int
 	SystemTime         string
 	ExecutionDriver    string
@@ -217,6 +226,8 @@ type Info struct {
 	ExperimentalBuild  bool
 	ServerVersion      string
 	ClusterStore       string
+	UIDMaps            []idtools.IDMap `json:"UidMaps"`
+	GIDMaps            []idtools.IDMap `json:"GidMaps"`
 }
 
 // ExecStartCheck is a temp struct used by execStart
@@ -228,6 +239,29 @@ type ExecStartCheck struct {
 	Tty bool
 }
 
+// Health states
+const (
+	NoHealthcheck = "none"      // Indicates there is no healthcheck
+	Starting      = "starting"  // Starting indicates that the container is not yet ready
+	Healthy       = "healthy"   // Healthy indicates that the container is running correctly
+	Unhealthy     = "unhealthy" // Unhealthy indicates that the container has a problem
+)
+
+// HealthcheckResult stores information about a single run of a healthcheck probe
+type HealthcheckResult struct {
+	Start    time.Time // Start is the time this check started
+	End      time.Time // End is the time this check ended
+	ExitCode int       // ExitCode meanings: 0=healthy, 1=unhealthy, 2=reserved (considered unhealthy), else=error running probe
+	Output   string    // Output from last check
+}
+
+// Health stores information about the container's healthcheck results
+type Health struct {
+	Status        string               // Status is one of Starting, Healthy or Unhealthy
+	FailingStreak int                  // FailingStreak is the number of consecutive failures
+	Log           []*HealthcheckResult // Log contains the last few results (oldest first)
+}
+
 // ContainerState stores container's running state
 // it's part of ContainerJSONBase and will return by "inspect" command
 type ContainerState struct {
@@ -242,6 +276,7 @@ type ContainerState struct {
 	Error      string
 	StartedAt  string
 	FinishedAt string
+	Health     *Health `json:",omitempty"`
 }
 
 // ContainerJSONBase contains response of Remote API:
@@ -265,9 +300,14 @@ type ContainerJSONBase struct {
 	MountLabel      string
 	ProcessLabel    string
 	AppArmorProfile string
+	SeccompProfile  string
 	ExecIDs         []string
 	HostConfig      *runconfig.HostConfig
 	GraphDriver     GraphDriverData
+
+	// LogMessagesDropped is the number of messages the logger dropped in
+	// non-blocking mode since the container was last started.
+	LogMessagesDropped int64
 }
 
 // ContainerJSON is newly used struct along with MountPoint
@@ -289,9 +329,10 @@ type MountPoint struct {
 
 // Volume represents the configuration of a volume for the remote API
 type Volume struct {
-	Name       string // Name is the name of the volume
-	Driver     string // Driver is the Driver name used to create the volume
-	Mountpoint string // Mountpoint is the location on disk of the volume
+	Name       string            // Name is the name of the volume
+	Driver     string            // Driver is the Driver name used to create the volume
+	Mountpoint string            // Mountpoint is the location on disk of the volume
+	Labels     map[string]string // Labels is metadata specific to the volume
 }
 
 // VolumesListResponse contains the response for the remote API:
@@ -300,10 +341,81 @@ type VolumesListResponse struct {
 	Volumes []*Volume // Volumes is the list of volumes being returned
 }
 
+// ImageDiskUsage is the disk usage of an image, with the size of its layers
+// which other images share.
+type ImageDiskUsage struct {
+	ID         string `json:"Id"`
+	RepoTags   []string
+	Created    int64
+	Size       int64 // Size is the size of all the layers of the image
+	SharedSize int64 // SharedSize is the size of the layers of the image which other images have
+	Containers int   // Containers is the number of containers using the image
+}
+
+// ContainerDiskUsage is the disk usage of a container, the size of its
+// writable layer.
+type ContainerDiskUsage struct {
+	ID      string `json:"Id"`
+	Names   []string
+	Image   string
+	Command string
+	Created int64
+	Status  string
+	Running bool
+	SizeRw  int64 // SizeRw is the size of the writable layer of the container, -1 if it cannot be computed
+}
+
+// VolumeDiskUsage is the disk usage of a volume.
+type VolumeDiskUsage struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Size       int64 // Size is the size on disk of a local volume, -1 for the volumes of the other drivers
+	RefCount   int   // RefCount is the number of containers using the volume
+}
+
+// DiskUsage contains the response for the remote API:
+// GET "/system/df"
+type DiskUsage struct {
+	LayersSize            int64 // LayersSize is the size of the layers of the images, each counted once
+	ReclaimableLayersSize int64 // ReclaimableLayersSize is the size of the layers which no container uses
+	Images                []*ImageDiskUsage
+	Containers            []*ContainerDiskUsage
+	Volumes               []*VolumeDiskUsage
+}
+
+// ContainersPruneReport contains the response for the remote API:
+// POST "/containers/prune"
+type ContainersPruneReport struct {
+	ContainersDeleted []string
+	SpaceReclaimed    uint64
+}
+
+// ImagesPruneReport contains the response for the remote API:
+// POST "/images/prune"
+type ImagesPruneReport struct {
+	ImagesDeleted  []ImageDelete
+	SpaceReclaimed uint64
+}
+
+// VolumesPruneReport contains the response for the remote API:
+// POST "/volumes/prune"
+type VolumesPruneReport struct {
+	VolumesDeleted []string
+	SpaceReclaimed uint64
+}
+
+// NetworksPruneReport contains the response for the remote API:
+// POST "/networks/prune"
+type NetworksPruneReport struct {
+	NetworksDeleted []string
+}
+
 // VolumeCreateRequest contains the response for the remote API:
 // POST "/volumes"
 type VolumeCreateRequest struct {
 	Name       string            // Name is the requested name of the volume
 	Driver     string            // Driver is the name of the driver that should be used to create the volume
 	DriverOpts map[string]string // DriverOpts holds the driver specific options to use for when creating the volume.
+	Labels     map[string]string // Labels holds metadata specific to the volume being created.
 }
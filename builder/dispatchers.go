@@ -15,6 +15,7 @@ import (
 	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Sirupsen/logrus"
@@ -605,6 +606,23 @@ func stopSignal(b *builder, args []string, attributes map[string]bool, original
 	return b.commit("", b.Config.Cmd, fmt.Sprintf("STOPSIGNAL %v", args))
 }
 
+// STOPTIMEOUT seconds
+//
+// Set the time the container is given to stop before it is killed.
+func stopTimeout(b *builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("STOPTIMEOUT requires exactly one argument")
+	}
+
+	timeout, err := strconv.Atoi(args[0])
+	if err != nil || timeout < 0 {
+		return fmt.Errorf("STOPTIMEOUT requires a number of seconds, got %s", args[0])
+	}
+
+	b.Config.StopTimeout = &timeout
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("STOPTIMEOUT %v", args))
+}
+
 // ARG name[=value]
 //
 // Adds the variable foo to the trusted list of variables that can be passed
@@ -45,38 +45,40 @@ import (
 
 // Environment variable interpolation will happen on these statements only.
 var replaceEnvAllowed = map[string]struct{}{
-	command.Env:        {},
-	command.Label:      {},
-	command.Add:        {},
-	command.Copy:       {},
-	command.Workdir:    {},
-	command.Expose:     {},
-	command.Volume:     {},
-	command.User:       {},
-	command.StopSignal: {},
-	command.Arg:        {},
+	command.Env:         {},
+	command.Label:       {},
+	command.Add:         {},
+	command.Copy:        {},
+	command.Workdir:     {},
+	command.Expose:      {},
+	command.Volume:      {},
+	command.User:        {},
+	command.StopSignal:  {},
+	command.StopTimeout: {},
+	command.Arg:         {},
 }
 
 var evaluateTable map[string]func(*builder, []string, map[string]bool, string) error
 
 func init() {
 	evaluateTable = map[string]func(*builder, []string, map[string]bool, string) error{
-		command.Env:        env,
-		command.Label:      label,
-		command.Maintainer: maintainer,
-		command.Add:        add,
-		command.Copy:       dispatchCopy, // copy() is a go builtin
-		command.From:       from,
-		command.Onbuild:    onbuild,
-		command.Workdir:    workdir,
-		command.Run:        run,
-		command.Cmd:        cmd,
-		command.Entrypoint: entrypoint,
-		command.Expose:     expose,
-		command.Volume:     volume,
-		command.User:       user,
-		command.StopSignal: stopSignal,
-		command.Arg:        arg,
+		command.Env:         env,
+		command.Label:       label,
+		command.Maintainer:  maintainer,
+		command.Add:         add,
+		command.Copy:        dispatchCopy, // copy() is a go builtin
+		command.From:        from,
+		command.Onbuild:     onbuild,
+		command.Workdir:     workdir,
+		command.Run:         run,
+		command.Cmd:         cmd,
+		command.Entrypoint:  entrypoint,
+		command.Expose:      expose,
+		command.Volume:      volume,
+		command.User:        user,
+		command.StopSignal:  stopSignal,
+		command.StopTimeout: stopTimeout,
+		command.Arg:         arg,
 	}
 }
 
@@ -138,6 +140,7 @@ type builder struct {
 	cgroupParent string
 	memory       int64
 	memorySwap   int64
+	shmSize      int64
 	ulimits      []*ulimit.Ulimit
 
 	cancelled <-chan struct{} // When closed, job was cancelled.
@@ -417,7 +420,7 @@ func platformSupports(command string) error {
 		return nil
 	}
 	switch command {
-	case "expose", "volume", "user", "stopsignal", "arg":
+	case "expose", "volume", "user", "stopsignal", "stoptimeout", "arg":
 		return fmt.Errorf("The daemon on this platform does not support the command '%s'", command)
 	}
 	return nil
@@ -28,6 +28,7 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/httputils"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/parsers"
@@ -619,6 +620,7 @@ func (b *builder) create() (*daemon.Container, error) {
 		CgroupParent: b.cgroupParent,
 		Memory:       b.memory,
 		MemorySwap:   b.memorySwap,
+		ShmSize:      b.shmSize,
 		Ulimits:      b.ulimits,
 	}
 
@@ -755,8 +757,12 @@ func (b *builder) addContext(container *daemon.Container, orig, dest string, dec
 		return err
 	}
 
+	uidMaps, gidMaps := b.Daemon.GetUIDGIDMaps()
+	archiver := chrootarchive.NewArchiver(uidMaps, gidMaps)
+	rootUID, rootGID := b.Daemon.GetRemappedUIDGID()
+
 	if fi.IsDir() {
-		return copyAsDirectory(origPath, destPath, destExists)
+		return copyAsDirectory(archiver, origPath, destPath, rootUID, rootGID, destExists)
 	}
 
 	// If we are adding a remote file (or we've been told not to decompress), do not try to untar it
@@ -771,17 +777,17 @@ func (b *builder) addContext(container *daemon.Container, orig, dest string, dec
 		}
 
 		// try to successfully untar the orig
-		if err := chrootarchive.UntarPath(origPath, tarDest); err == nil {
+		if err := archiver.UntarPath(origPath, tarDest); err == nil {
 			return nil
 		} else if err != io.EOF {
 			logrus.Debugf("Couldn't untar %s to %s: %s", origPath, tarDest, err)
 		}
 	}
 
-	if err := system.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+	if err := idtools.MkdirAllNewAs(filepath.Dir(destPath), 0755, rootUID, rootGID); err != nil {
 		return err
 	}
-	if err := chrootarchive.CopyWithTar(origPath, destPath); err != nil {
+	if err := archiver.CopyWithTar(origPath, destPath); err != nil {
 		return err
 	}
 
@@ -790,14 +796,14 @@ func (b *builder) addContext(container *daemon.Container, orig, dest string, dec
 		resPath = filepath.Join(destPath, filepath.Base(origPath))
 	}
 
-	return fixPermissions(origPath, resPath, 0, 0, destExists)
+	return fixPermissions(origPath, resPath, rootUID, rootGID, destExists)
 }
 
-func copyAsDirectory(source, destination string, destExisted bool) error {
-	if err := chrootarchive.CopyWithTar(source, destination); err != nil {
+func copyAsDirectory(archiver *archive.Archiver, source, destination string, rootUID, rootGID int, destExisted bool) error {
+	if err := archiver.CopyWithTar(source, destination); err != nil {
 		return err
 	}
-	return fixPermissions(source, destination, 0, 0, destExisted)
+	return fixPermissions(source, destination, rootUID, rootGID, destExisted)
 }
 
 func (b *builder) clearTmp() {
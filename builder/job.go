@@ -76,6 +76,7 @@ type Config struct {
 	CPUSetCpus     string
 	CPUSetMems     string
 	CgroupParent   string
+	ShmSize        int64
 	Ulimits        []*ulimit.Ulimit
 	AuthConfigs    map[string]cliconfig.AuthConfig
 	BuildArgs      map[string]string
@@ -221,6 +222,7 @@ func Build(d *daemon.Daemon, buildConfig *Config) error {
 		cgroupParent:     buildConfig.CgroupParent,
 		memory:           buildConfig.Memory,
 		memorySwap:       buildConfig.MemorySwap,
+		shmSize:          buildConfig.ShmSize,
 		ulimits:          buildConfig.Ulimits,
 		cancelled:        buildConfig.WaitCancelled(),
 		id:               stringid.GenerateRandomID(),
@@ -307,11 +307,15 @@ func (container *Container) ExtractToDir(path string, noOverwriteDirNonDir bool,
 		return ErrRootFSReadOnly
 	}
 
+	uidMaps, gidMaps := container.daemon.GetUIDGIDMaps()
+	rootUID, rootGID := container.daemon.GetRemappedUIDGID()
 	options := &archive.TarOptions{
 		ChownOpts: &archive.TarChownOptions{
-			UID: 0, GID: 0, // TODO: use config.User? Remap to userns root?
+			UID: rootUID, GID: rootGID, // TODO: use config.User?
 		},
 		NoOverwriteDirNonDir: noOverwriteDirNonDir,
+		UIDMaps:              uidMaps,
+		GIDMaps:              gidMaps,
 	}
 
 	if err := chrootarchive.Untar(content, resolvedPath, options); err != nil {
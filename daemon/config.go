@@ -1,6 +1,14 @@
 package daemon
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/runconfig"
@@ -9,6 +17,10 @@ import (
 const (
 	defaultNetworkMtu    = 1500
 	disableNetworkBridge = "none"
+
+	// configFileFlag is the name of the flag setting the configuration file,
+	// it cannot be set from the file itself.
+	configFileFlag = "config-file"
 )
 
 // CommonConfig defines the configuration of a docker daemon which are
@@ -34,6 +46,10 @@ type CommonConfig struct {
 	TrustKeyPath   string
 	DefaultNetwork string
 
+	// LiveRestore keeps containers running while the daemon is down, and
+	// reattaches to them when it starts again.
+	LiveRestore bool
+
 	// ClusterStore is the storage backend used for the cluster information. It is used by both
 	// multihost networking (to store networks and endpoints information) and by the node discovery
 	// mechanism.
@@ -43,6 +59,22 @@ type CommonConfig struct {
 	// discovery. This should be a 'host:port' combination on which that daemon instance is
 	// reachable by other hosts.
 	ClusterAdvertise string
+
+	// ConfigFile is the path of the JSON file holding the daemon options
+	// which are not set on the command line.
+	ConfigFile string
+
+	// EventsLogMaxSize is the maximum size of the journal recording the
+	// events, in a format understood by units.RAMInBytes. 0 disables it.
+	EventsLogMaxSize string
+
+	// EventsLogMaxAge is the maximum age of the events kept in the journal,
+	// 0 keeping them up to its maximum size.
+	EventsLogMaxAge time.Duration
+
+	// MetricsAddress is the TCP address the Prometheus metrics are served
+	// on. They are not served when it is empty.
+	MetricsAddress string
 }
 
 // InstallCommonFlags adds command-line options to the top-level flag parser for
@@ -68,4 +100,117 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.Var(opts.NewMapOpts(config.LogConfig.Config, nil), []string{"-log-opt"}, usageFn("Set log driver options"))
 	cmd.StringVar(&config.ClusterAdvertise, []string{"-cluster-advertise"}, "", usageFn("Address of the daemon instance to advertise"))
 	cmd.StringVar(&config.ClusterStore, []string{"-cluster-store"}, "", usageFn("Set the cluster store"))
+	cmd.StringVar(&config.ConfigFile, []string{"-" + configFileFlag}, defaultConfigFile, usageFn("Daemon configuration file"))
+	cmd.StringVar(&config.EventsLogMaxSize, []string{"-events-log-max-size"}, "100m", usageFn("Maximum size of the events journal, 0 disables it"))
+	cmd.DurationVar(&config.EventsLogMaxAge, []string{"-events-log-max-age"}, 0, usageFn("Maximum age of the events kept in the journal"))
+	cmd.StringVar(&config.MetricsAddress, []string{"-metrics-addr"}, "", usageFn("TCP address to serve the Prometheus metrics on"))
+}
+
+// ReadConfigFile reads the daemon configuration file at path. The file holds
+// a JSON object whose keys are the long names of the daemon flags.
+func ReadConfigFile(path string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var options map[string]interface{}
+	if err := json.Unmarshal(b, &options); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+	}
+	return options, nil
+}
+
+// ValidateConfigFile checks that every option of the configuration file
+// matches a flag of cmd, and that none of them is also set on the command
+// line, as reported by isSet.
+func ValidateConfigFile(options map[string]interface{}, cmd *flag.FlagSet, isSet func(name string) bool) error {
+	var unknown, conflicts []string
+	for key, value := range options {
+		f := cmd.Lookup("-" + key)
+		if f == nil || key == configFileFlag {
+			unknown = append(unknown, key)
+			continue
+		}
+		if isSet("-" + key) {
+			conflicts = append(conflicts, fmt.Sprintf("%s: (from flag: %v, from file: %v)", key, f.Value, value))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("the following options of the configuration file don't match any flag: %s", strings.Join(unknown, ", "))
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("the following options are set both as flags and in the configuration file: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// ApplyConfigFile sets the flags of cmd to the options of the configuration
+// file. Options which don't match any flag of cmd are skipped.
+func ApplyConfigFile(options map[string]interface{}, cmd *flag.FlagSet) error {
+	for key, value := range options {
+		if cmd.Lookup("-"+key) == nil {
+			continue
+		}
+		values, err := configFileValues(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s in the configuration file: %v", key, err)
+		}
+		for _, v := range values {
+			if err := cmd.Set("-"+key, v); err != nil {
+				return fmt.Errorf("invalid value for %s in the configuration file: %v", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// configFileValues converts a value of the configuration file to the
+// arguments of its flag. Lists give one argument per element, and objects
+// one key=value argument per key, like repeated flags do.
+func configFileValues(value interface{}) ([]string, error) {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, e := range v {
+			s, err := configFileScalar(e)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			s, err := configFileScalar(v[k])
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, k+"="+s)
+		}
+	default:
+		s, err := configFileScalar(v)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+func configFileScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
 }
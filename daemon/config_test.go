@@ -0,0 +1,182 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/daemon/events"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/runconfig"
+)
+
+func writeConfigFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "docker-config-file-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "daemon.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestReadConfigFile(t *testing.T) {
+	path, cleanup := writeConfigFile(t, `{"labels": "foo=bar"`)
+	defer cleanup()
+	if _, err := ReadConfigFile(path); err == nil {
+		t.Fatal("Expected an error for an invalid configuration file")
+	}
+
+	if _, err := ReadConfigFile(path + ".missing"); !os.IsNotExist(err) {
+		t.Fatalf("Expected a not exist error for a missing configuration file, got %v", err)
+	}
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	config := &Config{}
+	config.LogConfig.Config = make(map[string]string)
+	cmd := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.InstallCommonFlags(cmd, func(s string) string { return s })
+	if err := cmd.Parse([]string{"--label", "a=b"}); err != nil {
+		t.Fatal(err)
+	}
+	isSet := func(name string) bool { return cmd.IsSet(name) }
+
+	valid := map[string]interface{}{
+		"log-driver": "syslog",
+		"mtu":        float64(1400),
+	}
+	if err := ValidateConfigFile(valid, cmd, isSet); err != nil {
+		t.Fatalf("Expected valid options, got %v", err)
+	}
+
+	unknown := map[string]interface{}{
+		"foo":         "bar",
+		"s":           "vfs",
+		"config-file": "/etc/docker/other.json",
+	}
+	err := ValidateConfigFile(unknown, cmd, isSet)
+	if err == nil || !strings.Contains(err.Error(), "config-file, foo, s") {
+		t.Fatalf("Expected an error for the unknown options, got %v", err)
+	}
+
+	conflicts := map[string]interface{}{
+		"label": []interface{}{"c=d"},
+	}
+	err = ValidateConfigFile(conflicts, cmd, isSet)
+	if err == nil || !strings.Contains(err.Error(), "label: (from flag: [a=b], from file: [c=d])") {
+		t.Fatalf("Expected a conflict error, got %v", err)
+	}
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	path, cleanup := writeConfigFile(t, `{
+		"label": ["a=b", "c=d"],
+		"log-driver": "syslog",
+		"log-opt": {"syslog-tag": "docker", "syslog-facility": "daemon"},
+		"mtu": 1400,
+		"live-restore": true,
+		"dns": "8.8.8.8",
+		"unknown": "ignored"
+	}`)
+	defer cleanup()
+
+	options, err := ReadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{}
+	config.LogConfig.Config = make(map[string]string)
+	cmd := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.InstallFlags(cmd, func(s string) string { return s })
+	if err := ApplyConfigFile(options, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(config.Labels, []string{"a=b", "c=d"}) {
+		t.Fatalf("Expected labels [a=b c=d], got %v", config.Labels)
+	}
+	if config.LogConfig.Type != "syslog" {
+		t.Fatalf("Expected log driver syslog, got %s", config.LogConfig.Type)
+	}
+	expectedOpts := map[string]string{"syslog-tag": "docker", "syslog-facility": "daemon"}
+	if !reflect.DeepEqual(config.LogConfig.Config, expectedOpts) {
+		t.Fatalf("Expected log opts %v, got %v", expectedOpts, config.LogConfig.Config)
+	}
+	if config.Mtu != 1400 {
+		t.Fatalf("Expected mtu 1400, got %d", config.Mtu)
+	}
+	if !config.LiveRestore {
+		t.Fatal("Expected live restore to be enabled")
+	}
+	if !reflect.DeepEqual(config.DNS, []string{"8.8.8.8"}) {
+		t.Fatalf("Expected dns [8.8.8.8], got %v", config.DNS)
+	}
+
+	invalid := map[string]interface{}{"dns": "not an ip"}
+	if err := ApplyConfigFile(invalid, cmd); err == nil {
+		t.Fatal("Expected an error for an invalid dns address")
+	}
+	invalid = map[string]interface{}{"label": []interface{}{[]interface{}{"a=b"}}}
+	if err := ApplyConfigFile(invalid, cmd); err == nil {
+		t.Fatal("Expected an error for a nested list")
+	}
+	invalid = map[string]interface{}{"mtu": nil}
+	if err := ApplyConfigFile(invalid, cmd); err == nil {
+		t.Fatal("Expected an error for a null value")
+	}
+}
+
+func TestDaemonReload(t *testing.T) {
+	daemon := &Daemon{
+		configStore: &Config{
+			CommonConfig: CommonConfig{
+				Labels: []string{"a=b"},
+			},
+		},
+		defaultLogConfig: runconfig.LogConfig{Type: "json-file"},
+		EventsService:    events.New(),
+	}
+
+	config := &Config{
+		CommonConfig: CommonConfig{
+			Labels:    []string{"c=d"},
+			LogConfig: runconfig.LogConfig{Type: "syslog", Config: map[string]string{"syslog-tag": "docker"}},
+		},
+	}
+
+	// Only the labels are in the file.
+	reloaded := func(name string) bool { return name == "label" }
+	if err := daemon.Reload(config, reloaded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(daemon.configStore.Labels, []string{"c=d"}) {
+		t.Fatalf("Expected labels [c=d], got %v", daemon.configStore.Labels)
+	}
+	if daemon.defaultLogConfig.Type != "json-file" {
+		t.Fatalf("Expected log driver to stay json-file, got %s", daemon.defaultLogConfig.Type)
+	}
+	current, l := daemon.EventsService.Subscribe()
+	daemon.EventsService.Evict(l)
+	if len(current) != 1 || current[0].Type != "daemon" || current[0].Action != "reload" || current[0].Actor.Attributes["labels"] != "[c=d]" {
+		t.Fatalf("Expected a reload event with the new labels, got %v", current)
+	}
+
+	// An invalid log driver leaves the configuration untouched.
+	config.Labels = []string{"e=f"}
+	config.LogConfig = runconfig.LogConfig{Type: "foo"}
+	reloaded = func(name string) bool { return name == "label" || name == "log-driver" }
+	if err := daemon.Reload(config, reloaded); err == nil {
+		t.Fatal("Expected an error for an unknown log driver")
+	}
+	if !reflect.DeepEqual(daemon.configStore.Labels, []string{"c=d"}) {
+		t.Fatalf("Expected labels to stay [c=d], got %v", daemon.configStore.Labels)
+	}
+}
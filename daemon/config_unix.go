@@ -11,9 +11,10 @@ import (
 )
 
 var (
-	defaultPidFile = "/var/run/docker.pid"
-	defaultGraph   = "/var/lib/docker"
-	defaultExec    = "native"
+	defaultPidFile    = "/var/run/docker.pid"
+	defaultGraph      = "/var/lib/docker"
+	defaultExec       = "native"
+	defaultConfigFile = "/etc/docker/daemon.json"
 )
 
 // Config defines the configuration of a docker daemon.
@@ -27,6 +28,8 @@ type Config struct {
 	CorsHeaders          string
 	EnableCors           bool
 	EnableSelinuxSupport bool
+	Init                 bool // Run an init process in the containers which do not set it
+	RemappedRoot         string
 	SocketGroup          string
 	Ulimits              map[string]*ulimit.Ulimit
 }
@@ -77,6 +80,9 @@ func (config *Config) InstallFlags(cmd *flag.FlagSet, usageFn func(string) strin
 	cmd.BoolVar(&config.Bridge.EnableUserlandProxy, []string{"-userland-proxy"}, true, usageFn("Use userland proxy for loopback traffic"))
 	cmd.BoolVar(&config.EnableCors, []string{"#api-enable-cors", "#-api-enable-cors"}, false, usageFn("Enable CORS headers in the remote API, this is deprecated by --api-cors-header"))
 	cmd.StringVar(&config.CorsHeaders, []string{"-api-cors-header"}, "", usageFn("Set CORS headers in the remote API"))
+	cmd.BoolVar(&config.LiveRestore, []string{"-live-restore"}, false, usageFn("Keep containers on the host or none network running while the daemon is down"))
+	cmd.StringVar(&config.RemappedRoot, []string{"-userns-remap"}, "", usageFn("User/Group setting for user namespaces"))
+	cmd.BoolVar(&config.Init, []string{"-init"}, false, usageFn("Run an init in the containers to forward signals and reap processes"))
 
 	config.attachExperimentalFlags(cmd, usageFn)
 }
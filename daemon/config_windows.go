@@ -7,9 +7,10 @@ import (
 )
 
 var (
-	defaultPidFile = os.Getenv("programdata") + string(os.PathSeparator) + "docker.pid"
-	defaultGraph   = os.Getenv("programdata") + string(os.PathSeparator) + "docker"
-	defaultExec    = "windows"
+	defaultPidFile    = os.Getenv("programdata") + string(os.PathSeparator) + "docker.pid"
+	defaultGraph      = os.Getenv("programdata") + string(os.PathSeparator) + "docker"
+	defaultExec       = "windows"
+	defaultConfigFile = os.Getenv("programdata") + string(os.PathSeparator) + "docker" + string(os.PathSeparator) + "config" + string(os.PathSeparator) + "daemon.json"
 )
 
 // bridgeConfig stores all the bridge driver specific
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -18,6 +19,8 @@ import (
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/daemon/logger/local"
+	"github.com/docker/docker/daemon/logger/loggerutils/cache"
 	"github.com/docker/docker/daemon/network"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/image"
@@ -80,6 +83,9 @@ type CommonContainer struct {
 	// logDriver for closing
 	logDriver logger.Logger
 	logCopier *logger.Copier
+	// logDropped is the number of messages the last logger dropped in
+	// non-blocking mode, once it is closed.
+	logDropped int64
 }
 
 func (container *Container) fromDisk() error {
@@ -171,12 +177,7 @@ func (container *Container) writeHostConfig() error {
 }
 
 func (container *Container) logEvent(action string) {
-	d := container.daemon
-	d.EventsService.Log(
-		action,
-		container.ID,
-		container.Config.Image,
-	)
+	container.daemon.LogContainerEvent(container, action)
 }
 
 // GetResourcePath evaluates `path` in the scope of the container's basefs, with proper path
@@ -259,9 +260,13 @@ func (container *Container) Start() (err error) {
 			if container.ExitCode == 0 {
 				container.ExitCode = 128
 			}
+			container.setStartFailed()
 			container.toDisk()
 			container.cleanup()
-			container.logEvent("die")
+			attributes := map[string]string{
+				"exitCode": strconv.Itoa(container.ExitCode),
+			}
+			container.daemon.LogContainerEventWithAttributes(container, "die", attributes)
 		}
 	}()
 
@@ -299,11 +304,45 @@ func (container *Container) Start() (err error) {
 		return err
 	}
 	mounts = append(mounts, container.ipcMounts()...)
+	mounts = append(mounts, container.tmpfsMounts()...)
 
 	container.command.Mounts = mounts
 	return container.waitForStart()
 }
 
+// reattach resumes the supervision of a container whose process kept running
+// while the daemon was down. Its root filesystem, volumes and networking are
+// set up again for the daemon, but the process itself is left untouched.
+func (container *Container) reattach() error {
+	container.Lock()
+	defer container.Unlock()
+
+	if err := container.Mount(); err != nil {
+		return err
+	}
+	if err := container.restoreNetworking(); err != nil {
+		return err
+	}
+	if err := populateCommand(container, container.createDaemonEnvironment(nil)); err != nil {
+		return err
+	}
+	mounts, err := container.setupMounts()
+	if err != nil {
+		return err
+	}
+	mounts = append(mounts, container.ipcMounts()...)
+	container.command.Mounts = append(mounts, container.tmpfsMounts()...)
+
+	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	container.monitor.restoring = true
+	select {
+	case <-container.monitor.startSignal:
+	case err := <-promise.Go(container.monitor.Start):
+		return err
+	}
+	return nil
+}
+
 // streamConfig.StdinPipe returns a WriteCloser which can be used to feed data
 // to the standard input of the container's active process.
 // Container.StdoutPipe and Container.StderrPipe each return a ReadCloser
@@ -385,7 +424,10 @@ func (container *Container) killSig(sig int) error {
 	if err := container.daemon.kill(container, sig); err != nil {
 		return err
 	}
-	container.logEvent("kill")
+	attributes := map[string]string{
+		"signal": strconv.Itoa(sig),
+	}
+	container.daemon.LogContainerEventWithAttributes(container, "kill", attributes)
 	return nil
 }
 
@@ -418,6 +460,7 @@ func (container *Container) pause() error {
 	}
 	container.Paused = true
 	container.logEvent("pause")
+	container.daemon.updateHealthMonitor(container)
 	return nil
 }
 
@@ -440,6 +483,7 @@ func (container *Container) unpause() error {
 	}
 	container.Paused = false
 	container.logEvent("unpause")
+	container.daemon.updateHealthMonitor(container)
 	return nil
 }
 
@@ -553,7 +597,12 @@ func (container *Container) export() (archive.Archive, error) {
 		return nil, err
 	}
 
-	archive, err := archive.Tar(container.basefs, archive.Uncompressed)
+	uidMaps, gidMaps := container.daemon.GetUIDGIDMaps()
+	archive, err := archive.TarWithOptions(container.basefs, &archive.TarOptions{
+		Compression: archive.Uncompressed,
+		UIDMaps:     uidMaps,
+		GIDMaps:     gidMaps,
+	})
 	if err != nil {
 		container.Unmount()
 		return nil, err
@@ -658,9 +707,12 @@ func (container *Container) copy(resource string) (rc io.ReadCloser, err error)
 		filter = []string{filepath.Base(basePath)}
 		basePath = filepath.Dir(basePath)
 	}
+	uidMaps, gidMaps := container.daemon.GetUIDGIDMaps()
 	archive, err := archive.TarWithOptions(basePath, &archive.TarOptions{
 		Compression:  archive.Uncompressed,
 		IncludeFiles: filter,
+		UIDMaps:      uidMaps,
+		GIDMaps:      gidMaps,
 	})
 	if err != nil {
 		return nil, err
@@ -692,7 +744,22 @@ func (container *Container) getLogConfig() runconfig.LogConfig {
 		return cfg
 	}
 	// Use daemon's default log config for containers
-	return container.daemon.defaultLogConfig
+	return container.daemon.getDefaultLogConfig()
+}
+
+// logContext returns the context of the loggers of the container with the
+// log config cfg.
+func (container *Container) logContext(cfg runconfig.LogConfig) logger.Context {
+	return logger.Context{
+		Config:              cfg.Config,
+		ContainerID:         container.ID,
+		ContainerName:       container.Name,
+		ContainerEntrypoint: container.Path,
+		ContainerArgs:       container.Args,
+		ContainerImageID:    container.ImageID,
+		ContainerImageName:  container.Config.Image,
+		ContainerCreated:    container.Created,
+	}
 }
 
 func (container *Container) getLogger() (logger.Logger, error) {
@@ -707,25 +774,58 @@ func (container *Container) getLogger() (logger.Logger, error) {
 	if err != nil {
 		return nil, derr.ErrorCodeLoggingFactory.WithArgs(err)
 	}
-	ctx := logger.Context{
-		Config:              cfg.Config,
-		ContainerID:         container.ID,
-		ContainerName:       container.Name,
-		ContainerEntrypoint: container.Path,
-		ContainerArgs:       container.Args,
-		ContainerImageID:    container.ImageID,
-		ContainerImageName:  container.Config.Image,
-		ContainerCreated:    container.Created,
-	}
+	ctx := container.logContext(cfg)
 
-	// Set logging file for "json-logger"
-	if cfg.Type == jsonfilelog.Name {
+	// Set logging file for "json-logger" and "local"
+	switch cfg.Type {
+	case jsonfilelog.Name:
 		ctx.LogPath, err = container.getRootResourcePath(fmt.Sprintf("%s-json.log", container.ID))
+	case local.Name:
+		ctx.LogPath, err = container.getRootResourcePath(fmt.Sprintf("%s-local.log", container.ID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	l, err := c(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the logs of the drivers which can not read them back
+	if _, ok := l.(logger.LogReader); !ok && cache.Enabled(cfg.Config) {
+		cachePath, err := container.getRootResourcePath(fmt.Sprintf("%s-cache.log", container.ID))
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		cl, err := cache.WithLocalCache(l, ctx, cachePath)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		l = cl
+	}
+	return l, nil
+}
+
+// getLogReader returns the logger to read the logs of the container from.
+// The cached logs of a stopped container are read from the cache directly,
+// without creating its logging driver.
+func (container *Container) getLogReader() (logger.Logger, error) {
+	if container.logDriver != nil && container.IsRunning() {
+		return container.logDriver, nil
+	}
+	cfg := container.getLogConfig()
+	if cache.Enabled(cfg.Config) {
+		cachePath, err := container.getRootResourcePath(fmt.Sprintf("%s-cache.log", container.ID))
 		if err != nil {
 			return nil, err
 		}
+		if _, err := os.Stat(cachePath); err == nil {
+			return cache.NewReader(container.logContext(cfg), cachePath)
+		}
 	}
-	return c(ctx)
+	return container.getLogger()
 }
 
 func (container *Container) startLogging() error {
@@ -739,19 +839,47 @@ func (container *Container) startLogging() error {
 		return derr.ErrorCodeInitLogger.WithArgs(err)
 	}
 
-	copier := logger.NewCopier(container.ID, map[string]io.Reader{"stdout": container.StdoutPipe(), "stderr": container.StderrPipe()}, l)
-	container.logCopier = copier
-	copier.Run()
-	container.logDriver = l
-
 	// set LogPath field only for json-file logdriver
 	if jl, ok := l.(*jsonfilelog.JSONFileLogger); ok {
 		container.LogPath = jl.LogPath()
 	}
 
+	if _, ok := cfg.Config["multiline-pattern"]; ok {
+		ml, err := logger.NewMultilineLogger(l, cfg.Config)
+		if err != nil {
+			l.Close()
+			return derr.ErrorCodeInitLogger.WithArgs(err)
+		}
+		l = ml
+	}
+
+	if cfg.Config["mode"] == logger.ModeNonBlock {
+		maxSize, err := logger.MaxBufferSize(cfg.Config)
+		if err != nil {
+			l.Close()
+			return derr.ErrorCodeInitLogger.WithArgs(err)
+		}
+		l = logger.NewRingLogger(l, maxSize)
+	}
+
+	copier := logger.NewCopier(container.ID, map[string]io.Reader{"stdout": container.StdoutPipe(), "stderr": container.StderrPipe()}, l)
+	container.logCopier = copier
+	copier.Run()
+	container.logDriver = l
+	container.logDropped = 0
+
 	return nil
 }
 
+// logMessagesDropped returns the number of messages the logger dropped in
+// non-blocking mode since the container was last started.
+func (container *Container) logMessagesDropped() int64 {
+	if dc, ok := container.logDriver.(logger.DropCounter); ok {
+		return dc.DroppedMessages()
+	}
+	return container.logDropped
+}
+
 func (container *Container) waitForStart() error {
 	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
 
@@ -803,6 +931,9 @@ func (container *Container) exec(ExecConfig *ExecConfig) error {
 				c.Close()
 			}
 		}
+		ExecConfig.Lock()
+		ExecConfig.pid = pid
+		ExecConfig.Unlock()
 		close(ExecConfig.waitStart)
 		return nil
 	}
@@ -862,10 +993,13 @@ func (container *Container) Attach(stdin io.ReadCloser, stdout io.Writer, stderr
 
 func (container *Container) attachWithLogs(stdin io.ReadCloser, stdout, stderr io.Writer, logs, stream bool) error {
 	if logs {
-		logDriver, err := container.getLogger()
+		logDriver, err := container.getLogReader()
 		if err != nil {
 			return err
 		}
+		if logDriver != container.logDriver {
+			defer logDriver.Close()
+		}
 		cLog, ok := logDriver.(logger.LogReader)
 		if !ok {
 			return logger.ErrReadLogsNotSupported
@@ -1126,6 +1260,19 @@ func (container *Container) copyImagePathContent(v volume.Volume, destination st
 	return v.Unmount()
 }
 
+// defaultStopTimeout is the time, in seconds, a container is given to stop
+// before it is killed when neither its configuration nor the caller set one.
+const defaultStopTimeout = 10
+
+// stopTimeout returns the time, in seconds, the container is given to stop
+// before it is killed.
+func (container *Container) stopTimeout() int {
+	if container.Config.StopTimeout != nil {
+		return *container.Config.StopTimeout
+	}
+	return defaultStopTimeout
+}
+
 func (container *Container) stopSignal() int {
 	var stopSignal syscall.Signal
 	if container.Config.StopSignal != "" {
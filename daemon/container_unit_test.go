@@ -64,3 +64,24 @@ func TestContainerStopSignal(t *testing.T) {
 		t.Fatalf("Expected 9, got %v", s)
 	}
 }
+
+func TestContainerStopTimeout(t *testing.T) {
+	c := &Container{
+		CommonContainer: CommonContainer{
+			Config: &runconfig.Config{},
+		},
+	}
+	if s := c.stopTimeout(); s != defaultStopTimeout {
+		t.Fatalf("Expected %v, got %v", defaultStopTimeout, s)
+	}
+
+	timeout := 30
+	c = &Container{
+		CommonContainer: CommonContainer{
+			Config: &runconfig.Config{StopTimeout: &timeout},
+		},
+	}
+	if s := c.stopTimeout(); s != 30 {
+		t.Fatalf("Expected 30, got %v", s)
+	}
+}
@@ -19,10 +19,12 @@ import (
 	"github.com/docker/docker/daemon/links"
 	"github.com/docker/docker/daemon/network"
 	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/blkiodev"
 	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/nat"
+	"github.com/docker/docker/pkg/seccomp"
 	"github.com/docker/docker/pkg/stringid"
-	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
@@ -42,6 +44,10 @@ import (
 // ':' character .
 const DefaultPathEnv = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
 
+// defaultShmSize is the size of /dev/shm of a container when none is set in
+// its hostconfig, 64MB.
+const defaultShmSize int64 = 67108864
+
 // Container holds the fields specific to unixen implementations. See
 // CommonContainer for standard fields common to all containers.
 type Container struct {
@@ -57,6 +63,12 @@ type Container struct {
 	MountPoints     map[string]*mountPoint
 	ResolvConfPath  string
 
+	// SeccompProfile is the name of the seccomp profile of the container:
+	// "default", "unconfined", or the path of a custom profile, whose
+	// content is kept in SeccompProfileData.
+	SeccompProfile     string
+	SeccompProfileData string
+
 	Volumes   map[string]string // Deprecated since 1.7, kept for backwards compatibility
 	VolumesRW map[string]bool   // Deprecated since 1.7, kept for backwards compatibility
 }
@@ -175,6 +187,43 @@ func getDevicesFromPath(deviceMapping runconfig.DeviceMapping) (devs []*configs.
 	return devs, derr.ErrorCodeDeviceInfo.WithArgs(deviceMapping.PathOnHost, err)
 }
 
+// getBlkioDevice returns the block device at path, whose major and minor
+// numbers are used to refer to it in the blkio cgroup.
+func getBlkioDevice(path string) (*configs.Device, error) {
+	device, err := devices.DeviceFromPath(path, "rwm")
+	if err != nil {
+		return nil, derr.ErrorCodeBlkioDevice.WithArgs(path, err)
+	}
+	if device.Type != 'b' {
+		return nil, derr.ErrorCodeBlkioDevice.WithArgs(path, "not a block device")
+	}
+	return device, nil
+}
+
+func getBlkioWeightDevices(weightDevices []*blkiodev.WeightDevice) ([]*execdriver.WeightDevice, error) {
+	var out []*execdriver.WeightDevice
+	for _, wd := range weightDevices {
+		device, err := getBlkioDevice(wd.Path)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &execdriver.WeightDevice{Major: device.Major, Minor: device.Minor, Weight: wd.Weight})
+	}
+	return out, nil
+}
+
+func getBlkioThrottleDevices(throttleDevices []*blkiodev.ThrottleDevice) ([]*execdriver.ThrottleDevice, error) {
+	var out []*execdriver.ThrottleDevice
+	for _, td := range throttleDevices {
+		device, err := getBlkioDevice(td.Path)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &execdriver.ThrottleDevice{Major: device.Major, Minor: device.Minor, Rate: td.Rate})
+	}
+	return out, nil
+}
+
 func populateCommand(c *Container, env []string) error {
 	var en *execdriver.Network
 	if !c.Config.NetworkDisabled {
@@ -271,20 +320,47 @@ func populateCommand(c *Container, env []string) error {
 		rlimits = append(rlimits, rl)
 	}
 
+	weightDevices, err := getBlkioWeightDevices(c.hostConfig.BlkioWeightDevice)
+	if err != nil {
+		return err
+	}
+	readBpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceReadBps)
+	if err != nil {
+		return err
+	}
+	writeBpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceWriteBps)
+	if err != nil {
+		return err
+	}
+	readIOpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceReadIOps)
+	if err != nil {
+		return err
+	}
+	writeIOpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceWriteIOps)
+	if err != nil {
+		return err
+	}
+
 	resources := &execdriver.Resources{
-		Memory:            c.hostConfig.Memory,
-		MemorySwap:        c.hostConfig.MemorySwap,
-		MemoryReservation: c.hostConfig.MemoryReservation,
-		KernelMemory:      c.hostConfig.KernelMemory,
-		CPUShares:         c.hostConfig.CPUShares,
-		CpusetCpus:        c.hostConfig.CpusetCpus,
-		CpusetMems:        c.hostConfig.CpusetMems,
-		CPUPeriod:         c.hostConfig.CPUPeriod,
-		CPUQuota:          c.hostConfig.CPUQuota,
-		BlkioWeight:       c.hostConfig.BlkioWeight,
-		Rlimits:           rlimits,
-		OomKillDisable:    c.hostConfig.OomKillDisable,
-		MemorySwappiness:  -1,
+		Memory:                       c.hostConfig.Memory,
+		MemorySwap:                   c.hostConfig.MemorySwap,
+		MemoryReservation:            c.hostConfig.MemoryReservation,
+		KernelMemory:                 c.hostConfig.KernelMemory,
+		CPUShares:                    c.hostConfig.CPUShares,
+		CpusetCpus:                   c.hostConfig.CpusetCpus,
+		CpusetMems:                   c.hostConfig.CpusetMems,
+		CPUPeriod:                    c.hostConfig.CPUPeriod,
+		CPUQuota:                     c.hostConfig.CPUQuota,
+		BlkioWeight:                  c.hostConfig.BlkioWeight,
+		BlkioWeightDevice:            weightDevices,
+		BlkioThrottleReadBpsDevice:   readBpsDevices,
+		BlkioThrottleWriteBpsDevice:  writeBpsDevices,
+		BlkioThrottleReadIOpsDevice:  readIOpsDevices,
+		BlkioThrottleWriteIOpsDevice: writeIOpsDevices,
+		Rlimits:                      rlimits,
+		OomKillDisable:               c.hostConfig.OomKillDisable,
+		MemorySwappiness:             -1,
+		PidsLimit:                    c.hostConfig.PidsLimit,
 	}
 
 	if c.hostConfig.MemorySwappiness != nil {
@@ -302,6 +378,18 @@ func populateCommand(c *Container, env []string) error {
 	processConfig.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
 	processConfig.Env = env
 
+	uidMap, gidMap := c.daemon.GetUIDGIDMaps()
+
+	useInit := c.daemon.configStore.Init
+	if c.hostConfig.Init != nil {
+		useInit = *c.hostConfig.Init
+	}
+
+	seccompConfig, err := c.seccompConfig()
+	if err != nil {
+		return err
+	}
+
 	c.command = &execdriver.Command{
 		ID:                 c.ID,
 		Rootfs:             c.rootfsPath(),
@@ -324,11 +412,38 @@ func populateCommand(c *Container, env []string) error {
 		LxcConfig:          lxcConfig,
 		AppArmorProfile:    c.AppArmorProfile,
 		CgroupParent:       c.hostConfig.CgroupParent,
+		LiveRestore:        c.canLiveRestore(),
+		UIDMapping:         uidMap,
+		GIDMapping:         gidMap,
+		Seccomp:            seccompConfig,
+		Sysctls:            c.hostConfig.Sysctls,
+		Init:               useInit,
+		ShmSize:            c.shmSize(),
 	}
 
 	return nil
 }
 
+// shmSize returns the size of /dev/shm of the container, in bytes.
+func (container *Container) shmSize() int64 {
+	if container.hostConfig.ShmSize != 0 {
+		return container.hostConfig.ShmSize
+	}
+	return defaultShmSize
+}
+
+// seccompConfig returns the seccomp configuration of the container, nil if
+// it is unconfined.
+func (container *Container) seccompConfig() (*configs.Seccomp, error) {
+	switch container.SeccompProfile {
+	case "", "unconfined":
+		return nil, nil
+	case "default":
+		return seccomp.GetDefaultProfile(), nil
+	}
+	return seccomp.LoadProfile([]byte(container.SeccompProfileData))
+}
+
 func mergeDevices(defaultDevices, userDevices []*configs.Device) []*configs.Device {
 	if len(userDevices) == 0 {
 		return defaultDevices
@@ -353,7 +468,6 @@ func (container *Container) getSize() (int64, int64) {
 	var (
 		sizeRw, sizeRootfs int64
 		err                error
-		driver             = container.daemon.driver
 	)
 
 	if err := container.Mount(); err != nil {
@@ -362,14 +476,7 @@ func (container *Container) getSize() (int64, int64) {
 	}
 	defer container.Unmount()
 
-	initID := fmt.Sprintf("%s-init", container.ID)
-	sizeRw, err = driver.DiffSize(container.ID, initID)
-	if err != nil {
-		logrus.Errorf("Driver %s couldn't return diff size of container %s: %s", driver, container.ID, err)
-		// FIXME: GetSize should return an error. Not changing it now in case
-		// there is a side-effect.
-		sizeRw = -1
-	}
+	sizeRw = container.getSizeRw()
 
 	if _, err = os.Stat(container.basefs); err == nil {
 		if sizeRootfs, err = directory.Size(container.basefs); err != nil {
@@ -379,6 +486,22 @@ func (container *Container) getSize() (int64, int64) {
 	return sizeRw, sizeRootfs
 }
 
+// getSizeRw returns the size of the writable layer of the container, or -1 if
+// the storage driver cannot compute it. Unlike getSize, it does not walk the
+// layers of the image.
+func (container *Container) getSizeRw() int64 {
+	driver := container.daemon.driver
+	initID := fmt.Sprintf("%s-init", container.ID)
+	sizeRw, err := driver.DiffSize(container.ID, initID)
+	if err != nil {
+		logrus.Errorf("Driver %s couldn't return diff size of container %s: %s", driver, container.ID, err)
+		// FIXME: GetSize should return an error. Not changing it now in case
+		// there is a side-effect.
+		return -1
+	}
+	return sizeRw
+}
+
 // Attempt to set the network mounts given a provided destination and
 // the path to use for it; return true if the given destination was a
 // network mount file
@@ -865,6 +988,7 @@ func (container *Container) configureNetwork(networkName, service, networkDriver
 		if n, err = createNetwork(controller, networkName, networkDriver); err != nil {
 			return err
 		}
+		container.daemon.LogNetworkEvent(n.ID(), n.Name(), n.Type(), "create")
 	}
 
 	ep, err := n.EndpointByName(service)
@@ -912,6 +1036,7 @@ func (container *Container) configureNetwork(networkName, service, networkDriver
 	if err := ep.Join(sb); err != nil {
 		return err
 	}
+	container.daemon.LogNetworkEventWithAttributes(n.ID(), n.Name(), n.Type(), "connect", map[string]string{"container": container.ID})
 
 	if err := container.updateJoinInfo(ep); err != nil {
 		return derr.ErrorCodeJoinInfo.WithArgs(err)
@@ -958,6 +1083,28 @@ func (container *Container) initializeNetworking() error {
 	return container.buildHostnameFile()
 }
 
+// canLiveRestore returns whether the container can keep running while the
+// daemon is down. The terminal and stdin of a container are held by the
+// daemon, and so is the state of the networks other than host and none, so
+// containers using them are stopped along with the daemon.
+func (container *Container) canLiveRestore() bool {
+	if !container.daemon.configStore.LiveRestore {
+		return false
+	}
+	if container.Config.Tty || container.Config.OpenStdin {
+		return false
+	}
+	mode := container.hostConfig.NetworkMode
+	return container.Config.NetworkDisabled || mode.IsHost() || mode.IsNone()
+}
+
+// restoreNetworking registers the networking of a container which kept
+// running while the daemon was down with the network controller again.
+func (container *Container) restoreNetworking() error {
+	container.NetworkSettings = &network.Settings{}
+	return container.allocateNetwork()
+}
+
 // called from the libcontainer pre-start hook to set the network
 // namespace configuration linkage to the libnetwork "sandbox" entity
 func (container *Container) setNetworkNamespaceKey(pid int) error {
@@ -999,7 +1146,8 @@ func (container *Container) setupWorkingDirectory() error {
 				return err
 			}
 
-			if err := system.MkdirAll(pth, 0755); err != nil {
+			rootUID, rootGID := container.daemon.GetRemappedUIDGID()
+			if err := idtools.MkdirAllNewAs(pth, 0755, rootUID, rootGID); err != nil {
 				return err
 			}
 		}
@@ -1070,6 +1218,7 @@ func (container *Container) releaseNetwork() {
 		logrus.Errorf("Error deleting sandbox id %s for container %s: %v", sid, container.ID, err)
 		return
 	}
+	container.daemon.LogNetworkEventWithAttributes(n.ID(), n.Name(), n.Type(), "disconnect", map[string]string{"container": container.ID})
 
 	// In addition to leaving all endpoints, delete implicitly created endpoint
 	if container.Config.PublishService == "" {
@@ -1109,6 +1258,12 @@ func (container *Container) unmountVolumes(forceSyscall bool) error {
 			if err := volumeMount.Volume.Unmount(); err != nil {
 				return err
 			}
+
+			attributes := map[string]string{
+				"driver":    volumeMount.Volume.DriverName(),
+				"container": container.ID,
+			}
+			container.daemon.LogVolumeEvent(volumeMount.Volume.Name(), "unmount", attributes)
 		}
 	}
 
@@ -1189,7 +1344,15 @@ func (container *Container) addMountPointWithVolume(destination string, vol volu
 }
 
 func (container *Container) isDestinationMounted(destination string) bool {
-	return container.MountPoints[destination] != nil
+	if container.MountPoints[destination] != nil {
+		return true
+	}
+	for dest := range container.hostConfig.Tmpfs {
+		if filepath.Clean(dest) == destination {
+			return true
+		}
+	}
+	return false
 }
 
 func (container *Container) prepareMountPoints() error {
@@ -1222,6 +1385,9 @@ func (container *Container) removeMountPoints(rm bool) error {
 			if err != nil && err != store.ErrVolumeInUse {
 				rmErrors = append(rmErrors, err.Error())
 			}
+			if err == nil {
+				container.daemon.LogVolumeEvent(m.Volume.Name(), "destroy", map[string]string{"driver": m.Volume.DriverName()})
+			}
 		}
 	}
 	if len(rmErrors) > 0 {
@@ -1247,7 +1413,8 @@ func (container *Container) setupIpcDirs() error {
 		return err
 	}
 
-	if err := syscall.Mount("shm", shmPath, "tmpfs", uintptr(syscall.MS_NOEXEC|syscall.MS_NOSUID|syscall.MS_NODEV), label.FormatMountLabel("mode=1777,size=65536k", container.getMountLabel())); err != nil {
+	shmProperty := "mode=1777,size=" + strconv.FormatInt(container.shmSize(), 10)
+	if err := syscall.Mount("shm", shmPath, "tmpfs", uintptr(syscall.MS_NOEXEC|syscall.MS_NOSUID|syscall.MS_NODEV), label.FormatMountLabel(shmProperty, container.getMountLabel())); err != nil {
 		return fmt.Errorf("mounting shm tmpfs: %s", err)
 	}
 
@@ -1322,6 +1489,21 @@ func (container *Container) ipcMounts() []execdriver.Mount {
 	return mounts
 }
 
+// tmpfsMounts returns the tmpfs mounts of the container, mounted by the
+// exec driver when the container starts.
+func (container *Container) tmpfsMounts() []execdriver.Mount {
+	var mounts []execdriver.Mount
+	for dest, data := range container.hostConfig.Tmpfs {
+		mounts = append(mounts, execdriver.Mount{
+			Source:      "tmpfs",
+			Destination: dest,
+			Writable:    true,
+			Data:        data,
+		})
+	}
+	return mounts
+}
+
 func detachMounted(path string) error {
 	return syscall.Unmount(path, syscall.MNT_DETACH)
 }
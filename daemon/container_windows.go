@@ -140,11 +140,26 @@ func (container *Container) getSize() (int64, int64) {
 	return 0, 0
 }
 
+func (container *Container) getSizeRw() int64 {
+	// TODO Windows
+	return 0
+}
+
 // setNetworkNamespaceKey is a no-op on Windows.
 func (container *Container) setNetworkNamespaceKey(pid int) error {
 	return nil
 }
 
+// canLiveRestore returns false, containers cannot be restored on Windows.
+func (container *Container) canLiveRestore() bool {
+	return false
+}
+
+// restoreNetworking is a no-op on Windows.
+func (container *Container) restoreNetworking() error {
+	return nil
+}
+
 // allocateNetwork is a no-op on Windows.
 func (container *Container) allocateNetwork() error {
 	return nil
@@ -183,6 +198,10 @@ func (container *Container) ipcMounts() []execdriver.Mount {
 	return nil
 }
 
+func (container *Container) tmpfsMounts() []execdriver.Mount {
+	return nil
+}
+
 func getDefaultRouteMtu() (int, error) {
 	return -1, errSystemNotSupported
 }
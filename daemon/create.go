@@ -95,7 +95,7 @@ func (daemon *Daemon) Create(config *runconfig.Config, hostConfig *runconfig.Hos
 	if err := daemon.Register(container); err != nil {
 		return nil, nil, err
 	}
-	if err := daemon.createRootfs(container); err != nil {
+	if err := daemon.createRootfs(container, hostConfig.StorageOpt); err != nil {
 		return nil, nil, err
 	}
 	if err := daemon.setHostConfig(container, hostConfig); err != nil {
@@ -140,16 +140,16 @@ func (daemon *Daemon) generateSecurityOpt(ipcMode runconfig.IpcMode, pidMode run
 	return nil, nil
 }
 
-// VolumeCreate creates a volume with the specified name, driver, and opts
+// VolumeCreate creates a volume with the specified name, driver, opts and labels
 // This is called directly from the remote API
-func (daemon *Daemon) VolumeCreate(name, driverName string, opts map[string]string) (*types.Volume, error) {
+func (daemon *Daemon) VolumeCreate(name, driverName string, opts, labels map[string]string) (*types.Volume, error) {
 	if name == "" {
 		name = stringid.GenerateNonCryptoID()
 	}
 
-	v, err := daemon.volumes.Create(name, driverName, opts)
+	v, err := daemon.volumeCreate(name, driverName, opts, labels)
 	if err != nil {
 		return nil, err
 	}
-	return volumeToAPIType(v), nil
+	return daemon.volumeToAPIType(v), nil
 }
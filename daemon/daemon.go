@@ -36,6 +36,7 @@ import (
 	"github.com/docker/docker/pkg/discovery"
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/graphdb"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/namesgenerator"
 	"github.com/docker/docker/pkg/nat"
@@ -45,6 +46,7 @@ import (
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/truncindex"
+	"github.com/docker/docker/pkg/units"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/trust"
@@ -115,10 +117,15 @@ type Daemon struct {
 	RegistryService  *registry.Service
 	EventsService    *events.Events
 	netController    libnetwork.NetworkController
+	networkMetadata  *networkMetadataStore
 	volumes          *store.VolumeStore
 	discoveryWatcher discovery.Watcher
+	discoveryStop    chan struct{}
+	reloadLock       sync.RWMutex // protects the settings changed by Reload
 	root             string
 	shutdown         bool
+	uidMaps          []idtools.IDMap
+	gidMaps          []idtools.IDMap
 }
 
 // Get looks for a container using the provided information, which could be
@@ -208,25 +215,9 @@ func (daemon *Daemon) Register(container *Container) error {
 	// we'll waste time if we update it for every container
 	daemon.idIndex.Add(container.ID)
 
-	if container.IsRunning() {
-		logrus.Debugf("killing old running container %s", container.ID)
-		// Set exit code to 128 + SIGKILL (9) to properly represent unsuccessful exit
-		container.setStoppedLocking(&execdriver.ExitStatus{ExitCode: 137})
-		// use the current driver and ensure that the container is dead x.x
-		cmd := &execdriver.Command{
-			ID: container.ID,
-		}
-		daemon.execDriver.Terminate(cmd)
-
-		if err := container.unmountIpcMounts(); err != nil {
-			logrus.Errorf("%s: Failed to umount ipc filesystems: %v", container.ID, err)
-		}
-		if err := container.Unmount(); err != nil {
-			logrus.Debugf("unmount error %s", err)
-		}
-		if err := container.toDiskLocking(); err != nil {
-			logrus.Errorf("Error saving stopped state to disk: %v", err)
-		}
+	// Containers which can be restored are reattached to by restore()
+	if container.IsRunning() && !container.canLiveRestore() {
+		daemon.killStaleContainer(container)
 	}
 
 	if err := daemon.verifyVolumesInfo(container); err != nil {
@@ -240,6 +231,29 @@ func (daemon *Daemon) Register(container *Container) error {
 	return nil
 }
 
+// killStaleContainer kills a container left running by a previous daemon and
+// marks it as stopped.
+func (daemon *Daemon) killStaleContainer(container *Container) {
+	logrus.Debugf("killing old running container %s", container.ID)
+	// Set exit code to 128 + SIGKILL (9) to properly represent unsuccessful exit
+	container.setStoppedLocking(&execdriver.ExitStatus{ExitCode: 137})
+	// use the current driver and ensure that the container is dead x.x
+	cmd := &execdriver.Command{
+		ID: container.ID,
+	}
+	daemon.execDriver.Terminate(cmd)
+
+	if err := container.unmountIpcMounts(); err != nil {
+		logrus.Errorf("%s: Failed to umount ipc filesystems: %v", container.ID, err)
+	}
+	if err := container.Unmount(); err != nil {
+		logrus.Debugf("unmount error %s", err)
+	}
+	if err := container.toDiskLocking(); err != nil {
+		logrus.Errorf("Error saving stopped state to disk: %v", err)
+	}
+}
+
 func (daemon *Daemon) ensureName(container *Container) error {
 	if container.Name == "" {
 		name, err := daemon.generateNewName(container.ID)
@@ -331,6 +345,24 @@ func (daemon *Daemon) restore() error {
 				return
 			}
 
+			if container.IsRunning() {
+				logrus.Debugf("Restoring container %s", container.ID)
+				// A container which exited while the daemon was down is
+				// marked as stopped with its exit code by reattach.
+				if err := container.reattach(); err != nil && err != execdriver.ErrNotRunning {
+					logrus.Errorf("Failed to restore container %s: %s", container.ID, err)
+					container.releaseNetwork()
+					daemon.killStaleContainer(container)
+				}
+			}
+
+			// Remove the containers which exited while the daemon was
+			// shutting down or down, and should have been removed then.
+			if container.hostConfig.AutoRemove && !container.IsRunning() {
+				daemon.autoRemove(container)
+				return
+			}
+
 			// check the restart policy on the containers and restart any container with
 			// the restart policy of "always"
 			if daemon.configStore.AutoRestart && container.shouldRestart() {
@@ -610,9 +642,18 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 			return nil, fmt.Errorf("Unable to get the full path to root (%s): %s", config.Root, err)
 		}
 	}
-	config.Root = realRoot
+
+	uidMaps, gidMaps, err := setupRemappedRoot(config)
+	if err != nil {
+		return nil, err
+	}
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the root directory if it doesn't exists
-	if err := system.MkdirAll(config.Root, 0700); err != nil {
+	if err := setupDaemonRoot(config, realRoot, rootUID, rootGID); err != nil {
 		return nil, err
 	}
 
@@ -631,7 +672,7 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	graphdriver.DefaultDriver = config.GraphDriver
 
 	// Load storage driver
-	driver, err := graphdriver.New(config.Root, config.GraphOptions)
+	driver, err := graphdriver.New(config.Root, config.GraphOptions, uidMaps, gidMaps)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing graphdriver: %v", err)
 	}
@@ -639,6 +680,8 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 
 	d := &Daemon{}
 	d.driver = driver
+	d.uidMaps = uidMaps
+	d.gidMaps = gidMaps
 
 	// Ensure the graph driver is shutdown at a later point
 	defer func() {
@@ -664,7 +707,7 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 
 	daemonRepo := filepath.Join(config.Root, "containers")
 
-	if err := system.MkdirAll(daemonRepo, 0700); err != nil {
+	if err := idtools.MkdirAllAs(daemonRepo, 0700, rootUID, rootGID); err != nil {
 		return nil, err
 	}
 
@@ -680,7 +723,7 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	}
 
 	// Configure the volumes driver
-	volStore, err := configureVolumes(config)
+	volStore, err := configureVolumes(config, rootUID, rootGID)
 	if err != nil {
 		return nil, err
 	}
@@ -700,7 +743,10 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 		return nil, fmt.Errorf("could not create trust store: %s", err)
 	}
 
-	eventsService := events.New()
+	eventsService, err := newEventsService(config)
+	if err != nil {
+		return nil, err
+	}
 	logrus.Debug("Creating repository list")
 	tagCfg := &graph.TagStoreConfig{
 		Graph:    g,
@@ -724,6 +770,10 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	if err != nil {
 		return nil, fmt.Errorf("Error initializing network controller: %v", err)
 	}
+	d.networkMetadata, err = newNetworkMetadataStore(filepath.Join(config.Root, "networks-metadata.json"))
+	if err != nil {
+		return nil, err
+	}
 
 	graphdbPath := filepath.Join(config.Root, "linkgraph.db")
 	graph, err := graphdb.NewSqliteConn(graphdbPath)
@@ -733,13 +783,9 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 
 	d.containerGraphDB = graph
 
-	var sysInitPath string
-	if config.ExecDriver == "lxc" {
-		initPath, err := configureSysInit(config)
-		if err != nil {
-			return nil, err
-		}
-		sysInitPath = initPath
+	sysInitPath, err := configureSysInit(config, rootUID, rootGID)
+	if err != nil {
+		return nil, err
 	}
 
 	sysInfo := sysinfo.New(false)
@@ -759,7 +805,8 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	// DiscoveryWatcher version.
 	if config.ClusterStore != "" && config.ClusterAdvertise != "" {
 		var err error
-		if d.discoveryWatcher, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise); err != nil {
+		d.discoveryStop = make(chan struct{})
+		if d.discoveryWatcher, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise, d.discoveryStop); err != nil {
 			return nil, fmt.Errorf("discovery initialization failed (%v)", err)
 		}
 	}
@@ -781,8 +828,11 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	d.volumes = volStore
 	d.root = config.Root
 
-	if err := d.cleanupMounts(); err != nil {
-		return nil, err
+	// The ipc mounts of containers left running are still in use
+	if !config.LiveRestore {
+		if err := d.cleanupMounts(); err != nil {
+			return nil, err
+		}
 	}
 
 	go d.execCommandGC()
@@ -794,14 +844,35 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	return d, nil
 }
 
+// ShutdownTimeout returns the time, in seconds, Shutdown is given to stop
+// the running containers: the longest of their stop timeouts, with some
+// time left to kill them, and no less than 15 seconds.
+func (daemon *Daemon) ShutdownTimeout() int {
+	shutdownTimeout := 15
+	for _, c := range daemon.List() {
+		if c.IsRunning() && c.stopTimeout()+5 > shutdownTimeout {
+			shutdownTimeout = c.stopTimeout() + 5
+		}
+	}
+	return shutdownTimeout
+}
+
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	// keepRunning is set when containers are left running for the next
+	// daemon to restore, their mounts and networking must be kept then.
+	keepRunning := false
 	if daemon.containers != nil {
 		group := sync.WaitGroup{}
 		logrus.Debug("starting clean shutdown of all containers...")
 		for _, container := range daemon.List() {
 			c := container
+			if c.IsRunning() && c.canLiveRestore() {
+				logrus.Debugf("leaving %s running", c.ID)
+				keepRunning = true
+				continue
+			}
 			if c.IsRunning() {
 				logrus.Debugf("stopping %s", c.ID)
 				group.Add(1)
@@ -827,8 +898,8 @@ func (daemon *Daemon) Shutdown() error {
 							logrus.Debugf("Failed to unpause container %s with error: %v", c.ID, err)
 							return
 						}
-						if _, err := c.WaitStop(10 * time.Second); err != nil {
-							logrus.Debugf("container %s failed to exit in 10 second of SIGTERM, sending SIGKILL to force", c.ID)
+						if _, err := c.WaitStop(time.Duration(c.stopTimeout()) * time.Second); err != nil {
+							logrus.Debugf("container %s failed to exit in %d seconds of SIGTERM, sending SIGKILL to force", c.ID, c.stopTimeout())
 							sig, ok := signal.SignalMap["KILL"]
 							if !ok {
 								logrus.Warnf("System does not support SIGKILL")
@@ -837,8 +908,8 @@ func (daemon *Daemon) Shutdown() error {
 							daemon.kill(c, int(sig))
 						}
 					} else {
-						// If container failed to exit in 10 seconds of SIGTERM, then using the force
-						if err := c.Stop(10); err != nil {
+						// If container failed to exit in its stop timeout of SIGTERM, then using the force
+						if err := c.Stop(c.stopTimeout()); err != nil {
 							logrus.Errorf("Stop container %s with error: %v", c.ID, err)
 						}
 					}
@@ -850,7 +921,7 @@ func (daemon *Daemon) Shutdown() error {
 		group.Wait()
 
 		// trigger libnetwork Stop only if it's initialized
-		if daemon.netController != nil {
+		if daemon.netController != nil && !keepRunning {
 			daemon.netController.Stop()
 		}
 	}
@@ -861,6 +932,16 @@ func (daemon *Daemon) Shutdown() error {
 		}
 	}
 
+	if daemon.EventsService != nil {
+		if err := daemon.EventsService.Close(); err != nil {
+			logrus.Errorf("Error closing the events journal: %v", err)
+		}
+	}
+
+	if keepRunning {
+		return nil
+	}
+
 	if daemon.driver != nil {
 		if err := daemon.driver.Cleanup(); err != nil {
 			logrus.Errorf("Error during graph storage driver.Cleanup(): %v", err)
@@ -901,6 +982,15 @@ func (daemon *Daemon) unmount(container *Container) error {
 	return nil
 }
 
+// reattach resumes the supervision of a container by the exec driver, see
+// Container.reattach.
+func (daemon *Daemon) reattach(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error) {
+	hooks := execdriver.Hooks{
+		Start: startCallback,
+	}
+	return daemon.execDriver.Restore(c.command, pipes, hooks)
+}
+
 func (daemon *Daemon) run(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error) {
 	hooks := execdriver.Hooks{
 		Start: startCallback,
@@ -939,14 +1029,20 @@ func (daemon *Daemon) diff(container *Container) (archive.Archive, error) {
 	return daemon.driver.Diff(container.ID, initID)
 }
 
-func (daemon *Daemon) createRootfs(container *Container) error {
+// createRootfs creates the init and the read-write layers of the container
+// with the given storage driver options.
+func (daemon *Daemon) createRootfs(container *Container, storageOpt map[string]string) error {
 	// Step 1: create the container directory.
 	// This doubles as a barrier to avoid race conditions.
 	if err := os.Mkdir(container.root, 0700); err != nil {
 		return err
 	}
+	rootUID, rootGID := daemon.GetRemappedUIDGID()
+	if err := os.Chown(container.root, rootUID, rootGID); err != nil {
+		return err
+	}
 	initID := fmt.Sprintf("%s-init", container.ID)
-	if err := daemon.driver.Create(initID, container.ImageID); err != nil {
+	if err := daemon.driver.Create(initID, container.ImageID, storageOpt); err != nil {
 		return err
 	}
 	initPath, err := daemon.driver.Get(initID, "")
@@ -954,7 +1050,7 @@ func (daemon *Daemon) createRootfs(container *Container) error {
 		return err
 	}
 
-	if err := setupInitLayer(initPath); err != nil {
+	if err := setupInitLayer(initPath, rootUID, rootGID); err != nil {
 		daemon.driver.Put(initID)
 		return err
 	}
@@ -963,7 +1059,7 @@ func (daemon *Daemon) createRootfs(container *Container) error {
 	// for the actual container.
 	daemon.driver.Put(initID)
 
-	if err := daemon.driver.Create(container.ID, initID); err != nil {
+	if err := daemon.driver.Create(container.ID, initID, storageOpt); err != nil {
 		return err
 	}
 	return nil
@@ -1004,6 +1100,19 @@ func (daemon *Daemon) ExecutionDriver() execdriver.Driver {
 	return daemon.execDriver
 }
 
+// GetUIDGIDMaps returns the uid and gid mappings of the user namespace
+// containers run in, or nil when user namespaces are not in use.
+func (daemon *Daemon) GetUIDGIDMaps() ([]idtools.IDMap, []idtools.IDMap) {
+	return daemon.uidMaps, daemon.gidMaps
+}
+
+// GetRemappedUIDGID returns the host uid and gid root in the containers is
+// mapped to, 0 and 0 when user namespaces are not in use.
+func (daemon *Daemon) GetRemappedUIDGID() (int, int) {
+	uid, gid, _ := idtools.GetRootUIDGID(daemon.uidMaps, daemon.gidMaps)
+	return uid, gid
+}
+
 func (daemon *Daemon) containerGraph() *graphdb.Database {
 	return daemon.containerGraphDB
 }
@@ -1108,12 +1217,24 @@ func (daemon *Daemon) verifyContainerSettings(hostConfig *runconfig.HostConfig,
 				return nil, err
 			}
 		}
+
+		if config.StopTimeout != nil && *config.StopTimeout < 0 {
+			return nil, fmt.Errorf("Invalid stop timeout %d: it cannot be negative", *config.StopTimeout)
+		}
+
+		if err := validateHealthcheck(config.Healthcheck); err != nil {
+			return nil, err
+		}
 	}
 
 	if hostConfig == nil {
 		return nil, nil
 	}
 
+	if hostConfig.AutoRemove && hostConfig.RestartPolicy.Name != "" && !hostConfig.RestartPolicy.IsNone() {
+		return nil, fmt.Errorf("Can't create 'AutoRemove' container with restart policy")
+	}
+
 	for port := range hostConfig.PortBindings {
 		_, portStr := nat.SplitProtoPort(string(port))
 		if _, err := nat.ParsePort(portStr); err != nil {
@@ -1131,13 +1252,38 @@ func (daemon *Daemon) verifyContainerSettings(hostConfig *runconfig.HostConfig,
 	return verifyPlatformContainerSettings(daemon, hostConfig, config)
 }
 
+// newEventsService returns the service logging the events of the daemon,
+// which records them in a journal under the root directory unless its
+// maximum size is 0.
+func newEventsService(config *Config) (*events.Events, error) {
+	if config.EventsLogMaxSize == "" {
+		return events.New(), nil
+	}
+	maxSize, err := units.RAMInBytes(config.EventsLogMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --events-log-max-size %s: %v", config.EventsLogMaxSize, err)
+	}
+	if maxSize == 0 {
+		return events.New(), nil
+	}
+	if maxSize < 0 || config.EventsLogMaxAge < 0 {
+		return nil, fmt.Errorf("the events journal cannot have a negative maximum size or age")
+	}
+
+	journal, err := events.NewJournal(filepath.Join(config.Root, "events"), maxSize, config.EventsLogMaxAge)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening the events journal: %v", err)
+	}
+	return events.NewWithJournal(journal), nil
+}
+
 // NetworkController exposes the libnetwork interface to manage networks.
 func (daemon *Daemon) NetworkController() libnetwork.NetworkController {
 	return daemon.netController
 }
 
-func configureVolumes(config *Config) (*store.VolumeStore, error) {
-	volumesDriver, err := local.New(config.Root)
+func configureVolumes(config *Config, rootUID, rootGID int) (*store.VolumeStore, error) {
+	volumesDriver, err := local.New(config.Root, rootUID, rootGID)
 	if err != nil {
 		return nil, err
 	}
@@ -1145,6 +1291,9 @@ func configureVolumes(config *Config) (*store.VolumeStore, error) {
 	volumedrivers.Register(volumesDriver, volumesDriver.Name())
 	s := store.New()
 	s.AddAll(volumesDriver.List())
+	if err := s.LoadMetadata(filepath.Join(config.Root, "volumes-metadata.json")); err != nil {
+		return nil, err
+	}
 
 	return s, nil
 }
@@ -13,7 +13,12 @@ import (
 func migrateIfAufs(driver graphdriver.Driver, root string) error {
 	if ad, ok := driver.(*aufs.Driver); ok {
 		logrus.Debugf("Migrating existing containers")
-		if err := ad.Migrate(root, setupInitLayer); err != nil {
+		// The layouts to migrate predate user namespaces, their init
+		// layers are owned by the real root.
+		setupInit := func(p string) error {
+			return setupInitLayer(p, 0, 0)
+		}
+		if err := ad.Migrate(root, setupInit); err != nil {
 			return err
 		}
 	}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/pkg/graphdb"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/truncindex"
@@ -487,7 +488,7 @@ func TestRemoveLocalVolumesFollowingSymlinks(t *testing.T) {
 	}
 
 	m := c.MountPoints["/vol1"]
-	_, err = daemon.VolumeCreate(m.Name, m.Driver, nil)
+	_, err = daemon.VolumeCreate(m.Name, m.Driver, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -504,12 +505,13 @@ func TestRemoveLocalVolumesFollowingSymlinks(t *testing.T) {
 
 func initDaemonForVolumesTest(tmp string) (*Daemon, error) {
 	daemon := &Daemon{
-		repository: tmp,
-		root:       tmp,
-		volumes:    store.New(),
+		repository:    tmp,
+		root:          tmp,
+		volumes:       store.New(),
+		EventsService: events.New(),
 	}
 
-	volumesDriver, err := local.New(tmp)
+	volumesDriver, err := local.New(tmp, 0, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -549,3 +551,63 @@ func TestParseSecurityOpt(t *testing.T) {
 		t.Fatal("Expected parseSecurityOpt error, got nil")
 	}
 }
+
+func TestParseSecurityOptSeccomp(t *testing.T) {
+	container := &Container{}
+	config := &runconfig.HostConfig{}
+
+	expected := "unconfined"
+	if supportsSeccomp {
+		expected = "default"
+	}
+	if err := parseSecurityOpt(container, config); err != nil {
+		t.Fatalf("Unexpected parseSecurityOpt error: %v", err)
+	}
+	if container.SeccompProfile != expected {
+		t.Fatalf("Expected the %s seccomp profile, got %q", expected, container.SeccompProfile)
+	}
+
+	config.SecurityOpt = []string{"seccomp=unconfined"}
+	if err := parseSecurityOpt(container, config); err != nil {
+		t.Fatalf("Unexpected parseSecurityOpt error: %v", err)
+	}
+	if container.SeccompProfile != "unconfined" {
+		t.Fatalf("Expected the unconfined seccomp profile, got %q", container.SeccompProfile)
+	}
+
+	tmp, err := ioutil.TempDir("", "docker-seccomp-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	valid := filepath.Join(tmp, "valid.json")
+	if err := ioutil.WriteFile(valid, []byte(`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "chmod", "action": "SCMP_ACT_ERRNO"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(tmp, "invalid.json")
+	if err := ioutil.WriteFile(invalid, []byte(`{"defaultAction": "SCMP_ACT_NOPE"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config.SecurityOpt = []string{"seccomp=" + valid}
+	err = parseSecurityOpt(container, config)
+	if !supportsSeccomp {
+		if err == nil {
+			t.Fatal("Expected an error without seccomp support, got nil")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Unexpected parseSecurityOpt error: %v", err)
+	}
+	if container.SeccompProfile != valid || container.SeccompProfileData == "" {
+		t.Fatalf("Expected the seccomp profile %s, got %q", valid, container.SeccompProfile)
+	}
+
+	for _, profile := range []string{invalid, filepath.Join(tmp, "missing.json")} {
+		config.SecurityOpt = []string{"seccomp=" + profile}
+		if err := parseSecurityOpt(container, config); err == nil {
+			t.Fatalf("Expected parseSecurityOpt error for %s, got nil", profile)
+		}
+	}
+}
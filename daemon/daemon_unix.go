@@ -4,18 +4,25 @@ package daemon
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/autogen/dockerversion"
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/blkiodev"
 	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/kernel"
+	"github.com/docker/docker/pkg/seccomp"
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/runconfig"
@@ -25,6 +32,7 @@ import (
 	"github.com/docker/libnetwork/netlabel"
 	"github.com/docker/libnetwork/options"
 	"github.com/opencontainers/runc/libcontainer/label"
+	"github.com/opencontainers/runc/libcontainer/user"
 	"github.com/vishvananda/netlink"
 )
 
@@ -37,29 +45,71 @@ const (
 
 func parseSecurityOpt(container *Container, config *runconfig.HostConfig) error {
 	var (
-		labelOpts []string
-		err       error
+		labelOpts      []string
+		seccompProfile string
+		err            error
 	)
 
 	for _, opt := range config.SecurityOpt {
-		con := strings.SplitN(opt, ":", 2)
-		if len(con) == 1 {
+		// The name of the option is followed by either ':' or '='.
+		i := strings.IndexAny(opt, ":=")
+		if i == -1 {
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
-		switch con[0] {
+		name, value := opt[:i], opt[i+1:]
+		switch name {
 		case "label":
-			labelOpts = append(labelOpts, con[1])
+			labelOpts = append(labelOpts, value)
 		case "apparmor":
-			container.AppArmorProfile = con[1]
+			container.AppArmorProfile = value
+		case "seccomp":
+			seccompProfile = value
 		default:
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
 	}
 
+	if err := setSeccompProfile(container, config, seccompProfile); err != nil {
+		return err
+	}
+
 	container.ProcessLabel, container.MountLabel, err = label.InitLabels(labelOpts)
 	return err
 }
 
+// setSeccompProfile sets the seccomp profile of the container from the
+// seccomp security option, either "unconfined" or the path of a JSON
+// profile, which is loaded and validated here. Without the option, the
+// default profile applies to the containers which are not privileged.
+func setSeccompProfile(container *Container, config *runconfig.HostConfig, profile string) error {
+	container.SeccompProfile = "unconfined"
+	container.SeccompProfileData = ""
+
+	switch profile {
+	case "unconfined":
+		return nil
+	case "":
+		if supportsSeccomp && !config.Privileged {
+			container.SeccompProfile = "default"
+		}
+		return nil
+	}
+
+	if !supportsSeccomp {
+		return fmt.Errorf("Seccomp profiles are not supported by this build of the daemon")
+	}
+	b, err := ioutil.ReadFile(profile)
+	if err != nil {
+		return fmt.Errorf("Opening seccomp profile %s failed: %v", profile, err)
+	}
+	if _, err := seccomp.LoadProfile(b); err != nil {
+		return fmt.Errorf("Invalid seccomp profile %s: %v", profile, err)
+	}
+	container.SeccompProfile = profile
+	container.SeccompProfileData = string(b)
+	return nil
+}
+
 func checkKernelVersion(k, major, minor int) bool {
 	if v, err := kernel.GetKernelVersion(); err != nil {
 		logrus.Warnf("%s", err)
@@ -88,7 +138,7 @@ func checkKernel() error {
 	return nil
 }
 
-// adaptContainerSettings is called during container creation to modify any
+// adaptContainerSettings is called during container creation and update to modify any
 // settings necessary in the HostConfig structure.
 func (daemon *Daemon) adaptContainerSettings(hostConfig *runconfig.HostConfig, adjustCPUShares bool) {
 	if hostConfig == nil {
@@ -124,6 +174,23 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *runconfig.HostC
 		return warnings, fmt.Errorf("Cannot use --lxc-conf with execdriver: %s", daemon.ExecutionDriver().Name())
 	}
 
+	// Root in a remapped container has no privileges on the host, which
+	// rules out the settings sharing the host resources with it.
+	if len(daemon.uidMaps) > 0 {
+		if hostConfig.Privileged {
+			return warnings, fmt.Errorf("Privileged mode is incompatible with user namespaces")
+		}
+		if hostConfig.NetworkMode.IsHost() {
+			return warnings, fmt.Errorf("Cannot share the host's network namespace when user namespaces are enabled")
+		}
+		if hostConfig.PidMode.IsHost() {
+			return warnings, fmt.Errorf("Cannot share the host PID namespace when user namespaces are enabled")
+		}
+		if hostConfig.ReadonlyRootfs {
+			return warnings, fmt.Errorf("Cannot use the --read-only option when user namespaces are enabled")
+		}
+	}
+
 	// memory subsystem checks and adjustments
 	if hostConfig.Memory != 0 && hostConfig.Memory < 4194304 {
 		return warnings, fmt.Errorf("Minimum memory limit allowed is 4MB")
@@ -202,11 +269,77 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *runconfig.HostC
 	if hostConfig.BlkioWeight > 0 && (hostConfig.BlkioWeight < 10 || hostConfig.BlkioWeight > 1000) {
 		return warnings, fmt.Errorf("Range of blkio weight is from 10 to 1000.")
 	}
+	if len(hostConfig.BlkioWeightDevice) > 0 && !sysInfo.BlkioWeightDevice {
+		warnings = append(warnings, "Your kernel does not support Block I/O weight_device. Weight-device discarded.")
+		logrus.Warnf("Your kernel does not support Block I/O weight_device. Weight-device discarded.")
+		hostConfig.BlkioWeightDevice = nil
+	}
+	if len(hostConfig.BlkioDeviceReadBps) > 0 && !sysInfo.BlkioReadBpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block read limit in bytes per second. Device read bps discarded.")
+		logrus.Warnf("Your kernel does not support Block read limit in bytes per second. Device read bps discarded.")
+		hostConfig.BlkioDeviceReadBps = nil
+	}
+	if len(hostConfig.BlkioDeviceWriteBps) > 0 && !sysInfo.BlkioWriteBpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block write limit in bytes per second. Device write bps discarded.")
+		logrus.Warnf("Your kernel does not support Block write limit in bytes per second. Device write bps discarded.")
+		hostConfig.BlkioDeviceWriteBps = nil
+	}
+	if len(hostConfig.BlkioDeviceReadIOps) > 0 && !sysInfo.BlkioReadIOpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block read limit in IO per second. Device read iops discarded.")
+		logrus.Warnf("Your kernel does not support Block read limit in IO per second. Device read iops discarded.")
+		hostConfig.BlkioDeviceReadIOps = nil
+	}
+	if len(hostConfig.BlkioDeviceWriteIOps) > 0 && !sysInfo.BlkioWriteIOpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block write limit in IO per second. Device write iops discarded.")
+		logrus.Warnf("Your kernel does not support Block write limit in IO per second. Device write iops discarded.")
+		hostConfig.BlkioDeviceWriteIOps = nil
+	}
+	if err := verifyBlkioDevices(hostConfig); err != nil {
+		return warnings, err
+	}
+	if hostConfig.PidsLimit != 0 && !sysInfo.PidsLimit {
+		warnings = append(warnings, "Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		logrus.Warnf("Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		hostConfig.PidsLimit = 0
+	}
 	if hostConfig.OomKillDisable && !sysInfo.OomKillDisable {
 		hostConfig.OomKillDisable = false
 		return warnings, fmt.Errorf("Your kernel does not support oom kill disable.")
 	}
 
+	for dest, options := range hostConfig.Tmpfs {
+		if !filepath.IsAbs(dest) {
+			return warnings, fmt.Errorf("Invalid tmpfs destination %q: the path must be absolute", dest)
+		}
+		if _, _, err := mount.ParseTmpfsOptions(options); err != nil {
+			return warnings, err
+		}
+	}
+
+	if hostConfig.ShmSize < 0 {
+		return warnings, fmt.Errorf("SHM size must be greater than 0")
+	}
+	// A container sharing the IPC namespace of the host or of another
+	// container uses their /dev/shm, along with its size.
+	if hostConfig.ShmSize != 0 && (hostConfig.IpcMode.IsContainer() || hostConfig.IpcMode.IsHost()) {
+		warnings = append(warnings, "The IPC namespace is shared, the size of its /dev/shm is used. SHM size discarded.")
+		logrus.Warnf("The IPC namespace is shared, the size of its /dev/shm is used. SHM size discarded.")
+		hostConfig.ShmSize = 0
+	}
+
+	if err := verifySysctls(daemon, hostConfig); err != nil {
+		return warnings, err
+	}
+
+	if hostConfig.Init != nil && *hostConfig.Init {
+		if strings.Contains(daemon.ExecutionDriver().Name(), "lxc") {
+			return warnings, fmt.Errorf("Cannot use --init with execdriver: %s", daemon.ExecutionDriver().Name())
+		}
+		if daemon.systemInitPath() == "" {
+			return warnings, fmt.Errorf("Cannot use --init, the daemon could not locate dockerinit")
+		}
+	}
+
 	if sysInfo.IPv4ForwardingDisabled {
 		warnings = append(warnings, "IPv4 forwarding is disabled. Networking will not work.")
 		logrus.Warnf("IPv4 forwarding is disabled. Networking will not work")
@@ -214,6 +347,49 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *runconfig.HostC
 	return warnings, nil
 }
 
+// verifyBlkioDevices checks that the devices of the blkio settings of
+// hostConfig are block devices of the host.
+func verifyBlkioDevices(hostConfig *runconfig.HostConfig) error {
+	if _, err := getBlkioWeightDevices(hostConfig.BlkioWeightDevice); err != nil {
+		return err
+	}
+	for _, throttleDevices := range [][]*blkiodev.ThrottleDevice{
+		hostConfig.BlkioDeviceReadBps,
+		hostConfig.BlkioDeviceWriteBps,
+		hostConfig.BlkioDeviceReadIOps,
+		hostConfig.BlkioDeviceWriteIOps,
+	} {
+		if _, err := getBlkioThrottleDevices(throttleDevices); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifySysctls checks that the sysctls of hostConfig are namespaced, and
+// that their namespace is the container's own, so that they cannot change
+// the settings of the host or of another container.
+func verifySysctls(daemon *Daemon, hostConfig *runconfig.HostConfig) error {
+	if len(hostConfig.Sysctls) == 0 {
+		return nil
+	}
+	if strings.Contains(daemon.ExecutionDriver().Name(), "lxc") {
+		return fmt.Errorf("Cannot use --sysctl with execdriver: %s", daemon.ExecutionDriver().Name())
+	}
+	for key, value := range hostConfig.Sysctls {
+		if _, err := opts.ValidateSysctl(key + "=" + value); err != nil {
+			return err
+		}
+		if opts.IsNetSysctl(key) && (hostConfig.NetworkMode.IsHost() || hostConfig.NetworkMode.IsContainer()) {
+			return fmt.Errorf("Sysctl %s cannot be set when the network namespace is shared (--net=%s)", key, hostConfig.NetworkMode)
+		}
+		if opts.IsIpcSysctl(key) && (hostConfig.IpcMode.IsHost() || hostConfig.IpcMode.IsContainer()) {
+			return fmt.Errorf("Sysctl %s cannot be set when the IPC namespace is shared (--ipc=%s)", key, hostConfig.IpcMode)
+		}
+	}
+	return nil
+}
+
 // checkConfigOptions checks for mutually incompatible config options
 func checkConfigOptions(config *Config) error {
 	// Check for mutually incompatible config options
@@ -226,6 +402,9 @@ func checkConfigOptions(config *Config) error {
 	if !config.Bridge.EnableIPTables && config.Bridge.EnableIPMasq {
 		config.Bridge.EnableIPMasq = false
 	}
+	if config.Init && config.ExecDriver == "lxc" {
+		return fmt.Errorf("You specified --init with the lxc execdriver, which runs its own init. Please use the native execdriver.")
+	}
 	return nil
 }
 
@@ -258,21 +437,114 @@ func configureKernelSecuritySupport(config *Config, driverName string) error {
 	return nil
 }
 
+// setupRemappedRoot returns the uid and gid mappings of the user namespace
+// containers run in, from the --userns-remap setting. No mappings are
+// returned when the setting is empty.
+func setupRemappedRoot(config *Config) ([]idtools.IDMap, []idtools.IDMap, error) {
+	if config.RemappedRoot == "" {
+		return nil, nil, nil
+	}
+	if config.ExecDriver != "native" {
+		return nil, nil, fmt.Errorf("User namespaces are only supported with the native execdriver")
+	}
+	username, groupname, err := parseRemappedRoot(config.RemappedRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	if username == "root" {
+		return nil, nil, fmt.Errorf("Cannot remap root to itself, --userns-remap must name an unprivileged user")
+	}
+	uidMaps, gidMaps, err := idtools.CreateIDMappings(username, groupname)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Cannot create the user namespace mappings: %v", err)
+	}
+	return uidMaps, gidMaps, nil
+}
+
+// parseRemappedRoot parses a "user[:group]" setting, where the user and the
+// group are given by name or by id, and returns the user and group names.
+// The group defaults to the name of the user.
+func parseRemappedRoot(usergrp string) (string, string, error) {
+	parts := strings.Split(usergrp, ":")
+	if len(parts) > 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("Invalid --userns-remap setting %q, expected user[:group]", usergrp)
+	}
+
+	var (
+		u   user.User
+		err error
+	)
+	if uid, convErr := strconv.Atoi(parts[0]); convErr == nil {
+		u, err = user.LookupUid(uid)
+	} else {
+		u, err = user.LookupUser(parts[0])
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("Cannot find user %q for --userns-remap: %v", parts[0], err)
+	}
+	username, groupname := u.Name, u.Name
+
+	if len(parts) == 2 && parts[1] != "" {
+		var g user.Group
+		if gid, convErr := strconv.Atoi(parts[1]); convErr == nil {
+			g, err = user.LookupGid(gid)
+		} else {
+			g, err = user.LookupGroup(parts[1])
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("Cannot find group %q for --userns-remap: %v", parts[1], err)
+		}
+		groupname = g.Name
+	}
+	return username, groupname, nil
+}
+
+// setupDaemonRoot creates the root directory of the daemon. With user
+// namespaces, the daemon stores its data in a "uid.gid" subdirectory owned
+// by the remapped root, so that data of different mappings are kept apart.
+// rootDir is then made traversable by everyone for the containers to reach
+// their layers and volumes.
+func setupDaemonRoot(config *Config, rootDir string, rootUID, rootGID int) error {
+	config.Root = rootDir
+	if rootUID == 0 && rootGID == 0 {
+		return system.MkdirAll(config.Root, 0700)
+	}
+
+	if err := system.MkdirAll(rootDir, 0701); err != nil {
+		return err
+	}
+	if err := os.Chmod(rootDir, 0701); err != nil {
+		return err
+	}
+	config.Root = filepath.Join(rootDir, fmt.Sprintf("%d.%d", rootUID, rootGID))
+	return idtools.MkdirAllAs(config.Root, 0700, rootUID, rootGID)
+}
+
 // MigrateIfDownlevel is a wrapper for AUFS migration for downlevel
 func migrateIfDownlevel(driver graphdriver.Driver, root string) error {
 	return migrateIfAufs(driver, root)
 }
 
-func configureSysInit(config *Config) (string, error) {
+// configureSysInit returns the path of the daemon's copy of dockerinit. It
+// is required by the lxc driver, by the init process of the containers and
+// by live restore, whose containers run it to record their exit status;
+// without them, a missing dockerinit is only reported.
+func configureSysInit(config *Config, rootUID, rootGID int) (string, error) {
 	localCopy := filepath.Join(config.Root, "init", fmt.Sprintf("dockerinit-%s", dockerversion.VERSION))
 	sysInitPath := utils.DockerInitPath(localCopy)
 	if sysInitPath == "" {
-		return "", fmt.Errorf("Could not locate dockerinit: This usually means docker was built incorrectly. See https://docs.docker.com/contributing/devenvironment for official build instructions.")
+		err := fmt.Errorf("Could not locate dockerinit: This usually means docker was built incorrectly. See https://docs.docker.com/contributing/devenvironment for official build instructions.")
+		if config.ExecDriver == "lxc" || config.Init || config.LiveRestore {
+			return "", err
+		}
+		logrus.Warnf("%v Containers cannot be run with --init.", err)
+		return "", nil
 	}
 
 	if sysInitPath != localCopy {
 		// When we find a suitable dockerinit binary (even if it's our local binary), we copy it into config.Root at localCopy for future use (so that the original can go away without that being a problem, for example during a package upgrade).
-		if err := os.Mkdir(filepath.Dir(localCopy), 0700); err != nil && !os.IsExist(err) {
+		// It is owned by the remapped root, if any, which mounts it in the containers run with --init.
+		if err := idtools.MkdirAs(filepath.Dir(localCopy), 0700, rootUID, rootGID); err != nil {
 			return "", err
 		}
 		if _, err := fileutils.CopyFile(sysInitPath, localCopy); err != nil {
@@ -281,6 +553,9 @@ func configureSysInit(config *Config) (string, error) {
 		if err := os.Chmod(localCopy, 0700); err != nil {
 			return "", err
 		}
+		if err := os.Chown(localCopy, rootUID, rootGID); err != nil {
+			return "", err
+		}
 		sysInitPath = localCopy
 	}
 	return sysInitPath, nil
@@ -435,7 +710,7 @@ func initBridgeDriver(controller libnetwork.NetworkController, config *Config) e
 //
 // This extra layer is used by all containers as the top-most ro layer. It protects
 // the container from unwanted side-effects on the rw layer.
-func setupInitLayer(initLayer string) error {
+func setupInitLayer(initLayer string, rootUID, rootGID int) error {
 	for pth, typ := range map[string]string{
 		"/dev/pts":         "dir",
 		"/dev/shm":         "dir",
@@ -458,12 +733,12 @@ func setupInitLayer(initLayer string) error {
 
 		if _, err := os.Stat(filepath.Join(initLayer, pth)); err != nil {
 			if os.IsNotExist(err) {
-				if err := system.MkdirAll(filepath.Join(initLayer, filepath.Dir(pth)), 0755); err != nil {
+				if err := idtools.MkdirAllNewAs(filepath.Join(initLayer, filepath.Dir(pth)), 0755, rootUID, rootGID); err != nil {
 					return err
 				}
 				switch typ {
 				case "dir":
-					if err := system.MkdirAll(filepath.Join(initLayer, pth), 0755); err != nil {
+					if err := idtools.MkdirAllNewAs(filepath.Join(initLayer, pth), 0755, rootUID, rootGID); err != nil {
 						return err
 					}
 				case "file":
@@ -471,6 +746,7 @@ func setupInitLayer(initLayer string) error {
 					if err != nil {
 						return err
 					}
+					f.Chown(rootUID, rootGID)
 					f.Close()
 				default:
 					if err := os.Symlink(typ, filepath.Join(initLayer, pth)); err != nil {
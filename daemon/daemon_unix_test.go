@@ -85,3 +85,75 @@ func TestAdjustCPUSharesNoAdjustment(t *testing.T) {
 		t.Error("Expected CPUShares to be unchanged")
 	}
 }
+
+func TestCanLiveRestore(t *testing.T) {
+	daemon := &Daemon{
+		configStore: &Config{},
+	}
+	daemon.configStore.LiveRestore = true
+
+	cases := []struct {
+		config     *runconfig.Config
+		hostConfig *runconfig.HostConfig
+		expected   bool
+	}{
+		{&runconfig.Config{}, &runconfig.HostConfig{NetworkMode: "host"}, true},
+		{&runconfig.Config{}, &runconfig.HostConfig{NetworkMode: "none"}, true},
+		{&runconfig.Config{NetworkDisabled: true}, &runconfig.HostConfig{}, true},
+		{&runconfig.Config{}, &runconfig.HostConfig{NetworkMode: "bridge"}, false},
+		{&runconfig.Config{}, &runconfig.HostConfig{NetworkMode: "default"}, false},
+		{&runconfig.Config{Tty: true}, &runconfig.HostConfig{NetworkMode: "host"}, false},
+		{&runconfig.Config{OpenStdin: true}, &runconfig.HostConfig{NetworkMode: "host"}, false},
+	}
+	for _, c := range cases {
+		container := &Container{
+			CommonContainer: CommonContainer{
+				daemon:     daemon,
+				Config:     c.config,
+				hostConfig: c.hostConfig,
+			},
+		}
+		if restorable := container.canLiveRestore(); restorable != c.expected {
+			t.Fatalf("Expected canLiveRestore to be %v for %+v and %+v, got %v", c.expected, c.config, c.hostConfig, restorable)
+		}
+	}
+
+	daemon.configStore.LiveRestore = false
+	container := &Container{
+		CommonContainer: CommonContainer{
+			daemon:     daemon,
+			Config:     &runconfig.Config{},
+			hostConfig: &runconfig.HostConfig{NetworkMode: "host"},
+		},
+	}
+	if container.canLiveRestore() {
+		t.Fatal("Expected containers not to be restored without LiveRestore")
+	}
+}
+
+func TestParseRemappedRoot(t *testing.T) {
+	cases := []struct {
+		in                  string
+		username, groupname string
+	}{
+		{"root", "root", "root"},
+		{"0", "root", "root"},
+		{"root:0", "root", "root"},
+		{"0:root", "root", "root"},
+	}
+	for _, c := range cases {
+		username, groupname, err := parseRemappedRoot(c.in)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", c.in, err)
+		}
+		if username != c.username || groupname != c.groupname {
+			t.Fatalf("Expected %q to be parsed as %s:%s, got %s:%s", c.in, c.username, c.groupname, username, groupname)
+		}
+	}
+
+	for _, invalid := range []string{"", ":root", "root:root:root", "nonexistentuser", "root:nonexistentgroup"} {
+		if _, _, err := parseRemappedRoot(invalid); err == nil {
+			t.Fatalf("Expected an error parsing %q", invalid)
+		}
+	}
+}
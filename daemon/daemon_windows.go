@@ -2,14 +2,15 @@ package daemon
 
 import (
 	"fmt"
-	"os"
 	"syscall"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/graphdriver"
 	// register the windows graph driver
 	_ "github.com/docker/docker/daemon/graphdriver/windows"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/libnetwork"
 )
@@ -25,7 +26,7 @@ func parseSecurityOpt(container *Container, config *runconfig.HostConfig) error
 	return nil
 }
 
-func setupInitLayer(initLayer string) error {
+func setupInitLayer(initLayer string, rootUID, rootGID int) error {
 	return nil
 }
 
@@ -33,7 +34,7 @@ func checkKernel() error {
 	return nil
 }
 
-// adaptContainerSettings is called during container creation to modify any
+// adaptContainerSettings is called during container creation and update to modify any
 // settings necessary in the HostConfig structure.
 func (daemon *Daemon) adaptContainerSettings(hostConfig *runconfig.HostConfig, adjustCPUShares bool) {
 	if hostConfig == nil {
@@ -89,9 +90,20 @@ func migrateIfDownlevel(driver graphdriver.Driver, root string) error {
 	return nil
 }
 
-func configureSysInit(config *Config) (string, error) {
+// setupRemappedRoot is a no-op, user namespaces are not supported on Windows.
+func setupRemappedRoot(config *Config) ([]idtools.IDMap, []idtools.IDMap, error) {
+	return nil, nil, nil
+}
+
+func setupDaemonRoot(config *Config, rootDir string, rootUID, rootGID int) error {
+	config.Root = rootDir
+	// Create the root directory if it doesn't exists
+	return system.MkdirAll(config.Root, 0700)
+}
+
+func configureSysInit(config *Config, rootUID, rootGID int) (string, error) {
 	// TODO Windows.
-	return os.Getenv("TEMP"), nil
+	return "", nil
 }
 
 func isBridgeNetworkDisabled(config *Config) bool {
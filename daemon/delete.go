@@ -65,6 +65,14 @@ func (daemon *Daemon) ContainerRm(name string, config *ContainerRmConfig) error
 	return nil
 }
 
+// autoRemove removes a container started with AutoRemove set, and its
+// volumes, once it exited and is not going to be restarted.
+func (daemon *Daemon) autoRemove(container *Container) {
+	if err := daemon.ContainerRm(container.ID, &ContainerRmConfig{ForceRemove: true, RemoveVolume: true}); err != nil {
+		logrus.Errorf("Error removing container %s: %v", container.ID, err)
+	}
+}
+
 // Destroy unregisters a container from the daemon and cleanly removes its contents from the filesystem.
 func (daemon *Daemon) rm(container *Container, forceRemove bool) (err error) {
 	if container.IsRunning() {
@@ -160,5 +168,6 @@ func (daemon *Daemon) VolumeRm(name string) error {
 		}
 		return derr.ErrorCodeRmVolume.WithArgs(name, err)
 	}
+	daemon.LogVolumeEvent(v.Name(), "destroy", map[string]string{"driver": v.DriverName()})
 	return nil
 }
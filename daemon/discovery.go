@@ -19,8 +19,9 @@ const (
 )
 
 // initDiscovery initialized the nodes discovery subsystem by connecting to the specified backend
-// and start a registration loop to advertise the current node under the specified address.
-func initDiscovery(backend, address string) (discovery.Backend, error) {
+// and start a registration loop to advertise the current node under the specified address,
+// until stop is closed.
+func initDiscovery(backend, address string, stop <-chan struct{}) (discovery.Backend, error) {
 	var (
 		discoveryBackend discovery.Backend
 		err              error
@@ -31,18 +32,22 @@ func initDiscovery(backend, address string) (discovery.Backend, error) {
 
 	// We call Register() on the discovery backend in a loop for the whole lifetime of the daemon,
 	// but we never actually Watch() for nodes appearing and disappearing for the moment.
-	go registrationLoop(discoveryBackend, address)
+	go registrationLoop(discoveryBackend, address, stop)
 	return discoveryBackend, nil
 }
 
 // registrationLoop registers the current node against the discovery backend using the specified
-// address. The function never returns, as registration against the backend comes with a TTL and
-// requires regular heartbeats.
-func registrationLoop(discoveryBackend discovery.Backend, address string) {
+// address. The function only returns when stop is closed, as registration against the backend
+// comes with a TTL and requires regular heartbeats.
+func registrationLoop(discoveryBackend discovery.Backend, address string, stop <-chan struct{}) {
 	for {
 		if err := discoveryBackend.Register(address); err != nil {
 			log.Errorf("Registering as %q in discovery failed: %v", address, err)
 		}
-		time.Sleep(defaultDiscoveryHeartbeat)
+		select {
+		case <-stop:
+			return
+		case <-time.After(defaultDiscoveryHeartbeat):
+		}
 	}
 }
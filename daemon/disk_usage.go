@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/volume"
+)
+
+// SystemDiskUsage returns the disk usage of the images, the containers and
+// the volumes of the daemon.
+//
+// The size of each image layer is recorded in the graph when the layer is
+// registered, so the layers shared by several images are not walked again.
+// Only the writable layers of the containers and the local volumes are.
+func (daemon *Daemon) SystemDiskUsage() (*types.DiskUsage, error) {
+	layers := daemon.graph.Map()
+	du := &types.DiskUsage{}
+	for _, layer := range layers {
+		du.LayersSize += layer.Size
+	}
+
+	containers, err := daemon.Containers(&ContainersConfig{All: true})
+	if err != nil {
+		return nil, err
+	}
+	imageContainers := make(map[string]int)
+	for _, c := range containers {
+		container, err := daemon.Get(c.ID)
+		if err != nil {
+			// The container was removed in the meantime.
+			continue
+		}
+		imageContainers[container.ImageID]++
+		du.Containers = append(du.Containers, &types.ContainerDiskUsage{
+			ID:      c.ID,
+			Names:   c.Names,
+			Image:   c.Image,
+			Command: c.Command,
+			Created: c.Created,
+			Status:  c.Status,
+			Running: container.IsRunning(),
+			SizeRw:  container.getSizeRw(),
+		})
+	}
+
+	// The layers which no container uses could be reclaimed by removing
+	// their images.
+	usedLayers := make(map[string]bool)
+	for id := range imageContainers {
+		for _, layer := range imageLayers(layers, id) {
+			usedLayers[layer.ID] = true
+		}
+	}
+	du.ReclaimableLayersSize = du.LayersSize
+	for id := range usedLayers {
+		du.ReclaimableLayersSize -= layers[id].Size
+	}
+
+	images, err := daemon.repositories.Images("", "", false)
+	if err != nil {
+		return nil, err
+	}
+	// A layer is shared when several of the listed images have it.
+	chains := make([][]*image.Image, len(images))
+	layerImages := make(map[string]int)
+	for i, img := range images {
+		chains[i] = imageLayers(layers, img.ID)
+		for _, layer := range chains[i] {
+			layerImages[layer.ID]++
+		}
+	}
+	for i, img := range images {
+		idu := &types.ImageDiskUsage{
+			ID:         img.ID,
+			RepoTags:   img.RepoTags,
+			Created:    img.Created,
+			Containers: imageContainers[img.ID],
+		}
+		for _, layer := range chains[i] {
+			idu.Size += layer.Size
+			if layerImages[layer.ID] > 1 {
+				idu.SharedSize += layer.Size
+			}
+		}
+		du.Images = append(du.Images, idu)
+	}
+
+	for _, v := range daemon.volumes.List() {
+		vdu := &types.VolumeDiskUsage{
+			Name:       v.Name(),
+			Driver:     v.DriverName(),
+			Mountpoint: v.Path(),
+			Size:       -1,
+			RefCount:   int(daemon.volumes.Count(v)),
+		}
+		// The volumes of the other drivers may not be on this host.
+		if v.DriverName() == volume.DefaultDriverName {
+			if size, err := directory.Size(v.Path()); err != nil {
+				logrus.Warnf("Failed to compute the size of volume %s: %v", v.Name(), err)
+			} else {
+				vdu.Size = size
+			}
+		}
+		du.Volumes = append(du.Volumes, vdu)
+	}
+
+	return du, nil
+}
+
+// imageLayers returns the layers of the image with the given id, the image
+// itself followed by its parents, looked up in layers.
+func imageLayers(layers map[string]*image.Image, id string) []*image.Image {
+	var chain []*image.Image
+	for id != "" {
+		layer, ok := layers[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, layer)
+		id = layer.Parent
+	}
+	return chain
+}
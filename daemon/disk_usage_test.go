@@ -0,0 +1,28 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/image"
+)
+
+func TestImageLayers(t *testing.T) {
+	layers := map[string]*image.Image{
+		"base":  {ID: "base"},
+		"child": {ID: "child", Parent: "base"},
+		"top":   {ID: "top", Parent: "child"},
+	}
+
+	chain := imageLayers(layers, "top")
+	if len(chain) != 3 || chain[0].ID != "top" || chain[1].ID != "child" || chain[2].ID != "base" {
+		t.Fatalf("Expected the layers of top to be top, child and base, got %v", chain)
+	}
+	// The chain stops at the layers which are missing from the graph.
+	delete(layers, "base")
+	if chain := imageLayers(layers, "top"); len(chain) != 2 {
+		t.Fatalf("Expected 2 layers without base, got %d", len(chain))
+	}
+	if chain := imageLayers(layers, "unknown"); len(chain) != 0 {
+		t.Fatalf("Expected no layers for an unknown image, got %d", len(chain))
+	}
+}
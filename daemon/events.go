@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"strings"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+// LogContainerEvent generates an event related to a container.
+func (daemon *Daemon) LogContainerEvent(container *Container, action string) {
+	daemon.LogContainerEventWithAttributes(container, action, map[string]string{})
+}
+
+// LogContainerEventWithAttributes generates an event related to a container
+// with specific given attributes, along with its name, image and labels.
+func (daemon *Daemon) LogContainerEventWithAttributes(container *Container, action string, attributes map[string]string) {
+	copyAttributes(attributes, container.Config.Labels)
+	if container.Config.Image != "" {
+		attributes["image"] = container.Config.Image
+	}
+	attributes["name"] = strings.TrimLeft(container.Name, "/")
+
+	actor := eventtypes.Actor{
+		ID:         container.ID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.ContainerEventType, actor)
+	countContainerAction(action)
+}
+
+// LogImageEvent generates an event related to an image, identified by its
+// ID and, when there is one, by the reference the action applies to.
+func (daemon *Daemon) LogImageEvent(imageID, refName, action string) {
+	attributes := map[string]string{}
+	if refName != "" {
+		attributes["name"] = refName
+	}
+	actor := eventtypes.Actor{
+		ID:         imageID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.ImageEventType, actor)
+}
+
+// LogVolumeEvent generates an event related to a volume.
+func (daemon *Daemon) LogVolumeEvent(volumeID, action string, attributes map[string]string) {
+	actor := eventtypes.Actor{
+		ID:         volumeID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.VolumeEventType, actor)
+}
+
+// LogNetworkEvent generates an event related to a network with only the
+// default attributes.
+func (daemon *Daemon) LogNetworkEvent(networkID, name, networkType, action string) {
+	daemon.LogNetworkEventWithAttributes(networkID, name, networkType, action, map[string]string{})
+}
+
+// LogNetworkEventWithAttributes generates an event related to a network
+// with specific given attributes, along with its name and type.
+func (daemon *Daemon) LogNetworkEventWithAttributes(networkID, name, networkType, action string, attributes map[string]string) {
+	attributes["name"] = name
+	attributes["type"] = networkType
+	actor := eventtypes.Actor{
+		ID:         networkID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.NetworkEventType, actor)
+}
+
+// LogDaemonEventWithAttributes generates an event related to the daemon
+// itself, identified by the ID of the daemon.
+func (daemon *Daemon) LogDaemonEventWithAttributes(action string, attributes map[string]string) {
+	actor := eventtypes.Actor{
+		ID:         daemon.ID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.DaemonEventType, actor)
+}
+
+// copyAttributes guarantees that labels are not mutated by event triggers.
+func copyAttributes(attributes, labels map[string]string) {
+	for k, v := range labels {
+		attributes[k] = v
+	}
+}
@@ -4,50 +4,124 @@ import (
 	"sync"
 	"time"
 
-	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/pubsub"
 )
 
 const eventsLimit = 64
 
-// Events is pubsub channel for *jsonmessage.JSONMessage
+// Events is pubsub channel for *eventtypes.Message
 type Events struct {
-	mu     sync.Mutex
-	events []*jsonmessage.JSONMessage
-	pub    *pubsub.Publisher
+	mu      sync.Mutex
+	events  []*eventtypes.Message
+	last    int64 // last is the time of the last event in nanoseconds
+	pub     *pubsub.Publisher
+	journal *Journal
 }
 
 // New returns new *Events instance
 func New() *Events {
+	return NewWithJournal(nil)
+}
+
+// NewWithJournal returns new *Events instance which also records the events
+// in journal, from which SubscribeSince replays them.
+func NewWithJournal(journal *Journal) *Events {
 	return &Events{
-		events: make([]*jsonmessage.JSONMessage, 0, eventsLimit),
-		pub:    pubsub.NewPublisher(100*time.Millisecond, 1024),
+		events:  make([]*eventtypes.Message, 0, eventsLimit),
+		last:    time.Now().UTC().UnixNano(),
+		pub:     pubsub.NewPublisher(100*time.Millisecond, 1024),
+		journal: journal,
 	}
 }
 
 // Subscribe adds new listener to events, returns slice of 64 stored last events
 // channel in which you can expect new events in form of interface{}, so you
 // need type assertion.
-func (e *Events) Subscribe() ([]*jsonmessage.JSONMessage, chan interface{}) {
+func (e *Events) Subscribe() ([]*eventtypes.Message, chan interface{}) {
 	e.mu.Lock()
-	current := make([]*jsonmessage.JSONMessage, len(e.events))
+	current := make([]*eventtypes.Message, len(e.events))
 	copy(current, e.events)
 	l := e.pub.Subscribe()
 	e.mu.Unlock()
 	return current, l
 }
 
+// SubscribeSince adds new listener to events like Subscribe, and calls fn in
+// order with the events logged between since and until, both included, as
+// they are read from the journal if any, or else from the last events
+// stored. A zero until replays the events up to the last one. If fn returns
+// an error, the listener is evicted and the error is returned.
+func (e *Events) SubscribeSince(since, until time.Time, fn func(*eventtypes.Message) error) (chan interface{}, error) {
+	// The times of the events increase, so the events up to the last one
+	// logged when subscribing are the past ones, and only the ones logged
+	// after it are received, even if the previous ones are published late.
+	e.mu.Lock()
+	last := e.last
+	l := e.pub.SubscribeTopic(func(v interface{}) bool {
+		return v.(*eventtypes.Message).TimeNano > last
+	})
+	stored := make([]*eventtypes.Message, len(e.events))
+	copy(stored, e.events)
+	e.mu.Unlock()
+
+	replay := func(ev *eventtypes.Message) error {
+		if ev.TimeNano > last || ev.Time < since.Unix() || (!until.IsZero() && ev.Time > until.Unix()) {
+			return nil
+		}
+		return fn(ev)
+	}
+	var err error
+	if e.journal != nil {
+		err = e.journal.Read(since, until, replay)
+	} else {
+		for _, ev := range stored {
+			if err = replay(ev); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		e.pub.Evict(l)
+		return nil, err
+	}
+	return l, nil
+}
+
 // Evict evicts listener from pubsub
 func (e *Events) Evict(l chan interface{}) {
 	e.pub.Evict(l)
 }
 
 // Log broadcasts event to listeners. Each listener has 100 millisecond for
-// receiving event or it will be skipped.
-func (e *Events) Log(action, id, from string) {
-	now := time.Now().UTC()
-	jm := &jsonmessage.JSONMessage{Status: action, ID: id, From: from, Time: now.Unix(), TimeNano: now.UnixNano()}
+// receiving event or it will be skipped. The legacy status, id and from of
+// the events are set for the containers and the images.
+func (e *Events) Log(action, eventType string, actor eventtypes.Actor) {
+	jm := &eventtypes.Message{
+		Type:   eventType,
+		Action: action,
+		Actor:  actor,
+	}
+	switch eventType {
+	case eventtypes.ContainerEventType:
+		jm.Status = action
+		jm.ID = actor.ID
+		jm.From = actor.Attributes["image"]
+	case eventtypes.ImageEventType:
+		jm.Status = action
+		jm.ID = actor.ID
+	}
+
 	e.mu.Lock()
+	// The events are timed under the lock so that their times increase.
+	now := time.Now().UTC().UnixNano()
+	if now <= e.last {
+		now = e.last + 1
+	}
+	e.last = now
+	jm.Time = time.Unix(0, now).Unix()
+	jm.TimeNano = now
 	if len(e.events) == cap(e.events) {
 		// discard oldest event
 		copy(e.events, e.events[1:])
@@ -55,6 +129,11 @@ func (e *Events) Log(action, id, from string) {
 	} else {
 		e.events = append(e.events, jm)
 	}
+	if e.journal != nil {
+		if err := e.journal.Write(jm); err != nil {
+			logrus.Warnf("Failed to record event %s in the journal: %v", action, err)
+		}
+	}
 	e.mu.Unlock()
 	e.pub.Publish(jm)
 }
@@ -63,3 +142,11 @@ func (e *Events) Log(action, id, from string) {
 func (e *Events) SubscribersCount() int {
 	return e.pub.Len()
 }
+
+// Close closes the journal of the events, if any.
+func (e *Events) Close() error {
+	if e.journal == nil {
+		return nil
+	}
+	return e.journal.Close()
+}
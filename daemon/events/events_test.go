@@ -5,7 +5,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/docker/docker/pkg/jsonmessage"
+	eventtypes "github.com/docker/docker/api/types/events"
 )
 
 func TestEventsLog(t *testing.T) {
@@ -18,10 +18,10 @@ func TestEventsLog(t *testing.T) {
 	if count != 2 {
 		t.Fatalf("Must be 2 subscribers, got %d", count)
 	}
-	e.Log("test", "cont", "image")
+	e.Log("test", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont", Attributes: map[string]string{"image": "image"}})
 	select {
 	case msg := <-l1:
-		jmsg, ok := msg.(*jsonmessage.JSONMessage)
+		jmsg, ok := msg.(*eventtypes.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
@@ -42,7 +42,7 @@ func TestEventsLog(t *testing.T) {
 	}
 	select {
 	case msg := <-l2:
-		jmsg, ok := msg.(*jsonmessage.JSONMessage)
+		jmsg, ok := msg.(*eventtypes.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
@@ -70,7 +70,7 @@ func TestEventsLogTimeout(t *testing.T) {
 
 	c := make(chan struct{})
 	go func() {
-		e.Log("test", "cont", "image")
+		e.Log("test", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont", Attributes: map[string]string{"image": "image"}})
 		close(c)
 	}()
 
@@ -88,7 +88,7 @@ func TestLogEvents(t *testing.T) {
 		action := fmt.Sprintf("action_%d", i)
 		id := fmt.Sprintf("cont_%d", i)
 		from := fmt.Sprintf("image_%d", i)
-		e.Log(action, id, from)
+		e.Log(action, eventtypes.ContainerEventType, eventtypes.Actor{ID: id, Attributes: map[string]string{"image": from}})
 	}
 	time.Sleep(50 * time.Millisecond)
 	current, l := e.Subscribe()
@@ -97,16 +97,16 @@ func TestLogEvents(t *testing.T) {
 		action := fmt.Sprintf("action_%d", num)
 		id := fmt.Sprintf("cont_%d", num)
 		from := fmt.Sprintf("image_%d", num)
-		e.Log(action, id, from)
+		e.Log(action, eventtypes.ContainerEventType, eventtypes.Actor{ID: id, Attributes: map[string]string{"image": from}})
 	}
 	if len(e.events) != eventsLimit {
 		t.Fatalf("Must be %d events, got %d", eventsLimit, len(e.events))
 	}
 
-	var msgs []*jsonmessage.JSONMessage
+	var msgs []*eventtypes.Message
 	for len(msgs) < 10 {
 		m := <-l
-		jm, ok := (m).(*jsonmessage.JSONMessage)
+		jm, ok := (m).(*eventtypes.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", m)
 		}
@@ -133,3 +133,26 @@ func TestLogEvents(t *testing.T) {
 		t.Fatalf("Last action is %s, must be action_89", lastC.Status)
 	}
 }
+
+func TestEventsLogLegacyFields(t *testing.T) {
+	e := New()
+	e.Log("create", eventtypes.VolumeEventType, eventtypes.Actor{ID: "vol", Attributes: map[string]string{"driver": "local"}})
+	e.Log("untag", eventtypes.ImageEventType, eventtypes.Actor{ID: "img", Attributes: map[string]string{"name": "busybox:latest"}})
+
+	current, l := e.Subscribe()
+	defer e.Evict(l)
+	if len(current) != 2 {
+		t.Fatalf("Must be 2 events, got %d", len(current))
+	}
+	vol := current[0]
+	if vol.Type != eventtypes.VolumeEventType || vol.Action != "create" || vol.Actor.ID != "vol" || vol.Actor.Attributes["driver"] != "local" {
+		t.Fatalf("Unexpected volume event %+v", vol)
+	}
+	if vol.Status != "" || vol.ID != "" {
+		t.Fatalf("The legacy fields must not be set for volumes, got %+v", vol)
+	}
+	img := current[1]
+	if img.Status != "untag" || img.ID != "img" || img.From != "" {
+		t.Fatalf("Unexpected legacy fields for image event %+v", img)
+	}
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"strings"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+// Filter can filter out docker events from a stream
+type Filter struct {
+	filter filters.Args
+}
+
+// NewFilter creates a new Filter
+func NewFilter(filter filters.Args) *Filter {
+	return &Filter{filter: filter}
+}
+
+// Include returns true when the event ev is included by the filters. The
+// filters on an object, like the container or the volume one, only include
+// the events of the objects of this type.
+func (ef *Filter) Include(ev *eventtypes.Message) bool {
+	return ef.filter.ExactMatch("event", ev.Action) &&
+		ef.filter.ExactMatch("type", ev.Type) &&
+		ef.matchContainer(ev) &&
+		ef.matchImage(ev) &&
+		ef.matchObject(ev, eventtypes.VolumeEventType) &&
+		ef.matchObject(ev, eventtypes.NetworkEventType) &&
+		ef.filter.MatchKVList("label", ev.Actor.Attributes)
+}
+
+// matchContainer matches the containers by ID, ID prefix or name.
+func (ef *Filter) matchContainer(ev *eventtypes.Message) bool {
+	values := ef.filter["container"]
+	if len(values) == 0 {
+		return true
+	}
+	if ev.Type != eventtypes.ContainerEventType {
+		return false
+	}
+	for _, v := range values {
+		v = strings.TrimPrefix(v, "/")
+		if strings.HasPrefix(ev.Actor.ID, v) || v == ev.Actor.Attributes["name"] {
+			return true
+		}
+	}
+	return false
+}
+
+// matchImage matches the image events by image ID or reference, and the
+// container events by the image of the container. A reference without a tag
+// matches all the tags of the repository.
+func (ef *Filter) matchImage(ev *eventtypes.Message) bool {
+	values := ef.filter["image"]
+	if len(values) == 0 {
+		return true
+	}
+
+	var candidates []string
+	switch ev.Type {
+	case eventtypes.ImageEventType:
+		candidates = []string{ev.Actor.ID, ev.Actor.Attributes["name"]}
+	case eventtypes.ContainerEventType:
+		candidates = []string{ev.Actor.Attributes["image"]}
+	default:
+		return false
+	}
+
+	for _, v := range values {
+		for _, c := range candidates {
+			if c == "" {
+				continue
+			}
+			if c == v {
+				return true
+			}
+			if repo, _ := parsers.ParseRepositoryTag(c); repo == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchObject matches the events of the objects of type eventType, volumes
+// or networks, by ID or name.
+func (ef *Filter) matchObject(ev *eventtypes.Message, eventType string) bool {
+	values := ef.filter[eventType]
+	if len(values) == 0 {
+		return true
+	}
+	if ev.Type != eventType {
+		return false
+	}
+	for _, v := range values {
+		if v == ev.Actor.ID || v == ev.Actor.Attributes["name"] {
+			return true
+		}
+	}
+	return false
+}
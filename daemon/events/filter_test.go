@@ -0,0 +1,71 @@
+package events
+
+import (
+	"testing"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func TestFilterInclude(t *testing.T) {
+	container := &eventtypes.Message{
+		Type:   eventtypes.ContainerEventType,
+		Action: "start",
+		Actor: eventtypes.Actor{
+			ID:         "4386fb97867d",
+			Attributes: map[string]string{"name": "web", "image": "busybox:latest", "com.example.tier": "front"},
+		},
+	}
+	image := &eventtypes.Message{
+		Type:   eventtypes.ImageEventType,
+		Action: "untag",
+		Actor: eventtypes.Actor{
+			ID:         "d7057cb02084",
+			Attributes: map[string]string{"name": "localhost:5000/busybox:latest"},
+		},
+	}
+	volume := &eventtypes.Message{
+		Type:   eventtypes.VolumeEventType,
+		Action: "create",
+		Actor: eventtypes.Actor{
+			ID:         "data",
+			Attributes: map[string]string{"driver": "local"},
+		},
+	}
+
+	cases := []struct {
+		filter filters.Args
+		ev     *eventtypes.Message
+		match  bool
+	}{
+		{filters.Args{}, volume, true},
+		{filters.Args{"event": {"start"}}, container, true},
+		{filters.Args{"event": {"stop"}}, container, false},
+		{filters.Args{"type": {"volume"}}, volume, true},
+		{filters.Args{"type": {"volume"}}, container, false},
+		{filters.Args{"container": {"web"}}, container, true},
+		{filters.Args{"container": {"/web"}}, container, true},
+		{filters.Args{"container": {"4386"}}, container, true},
+		{filters.Args{"container": {"db"}}, container, false},
+		{filters.Args{"container": {"web"}}, volume, false},
+		{filters.Args{"image": {"busybox"}}, container, true},
+		{filters.Args{"image": {"busybox:latest"}}, container, true},
+		{filters.Args{"image": {"ubuntu"}}, container, false},
+		{filters.Args{"image": {"d7057cb02084"}}, image, true},
+		{filters.Args{"image": {"localhost:5000/busybox"}}, image, true},
+		{filters.Args{"image": {"localhost"}}, image, false},
+		{filters.Args{"volume": {"data"}}, volume, true},
+		{filters.Args{"volume": {"other"}}, volume, false},
+		{filters.Args{"network": {"data"}}, volume, false},
+		{filters.Args{"label": {"com.example.tier=front"}}, container, true},
+		{filters.Args{"label": {"com.example.tier"}}, container, true},
+		{filters.Args{"label": {"com.example.tier=back"}}, container, false},
+		{filters.Args{"label": {"com.example.tier"}}, volume, false},
+	}
+
+	for _, c := range cases {
+		if NewFilter(c.filter).Include(c.ev) != c.match {
+			t.Fatalf("Expected filter %v to match %s %s: %v", c.filter, c.ev.Type, c.ev.Actor.ID, c.match)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+const (
+	// journalSegments is the number of segments the size of the journal is
+	// divided in, the oldest one being removed at once when it is full.
+	journalSegments = 8
+	segmentSuffix   = ".log"
+)
+
+// journalCompactInterval is the interval at which the journal is compacted
+// when it has a maximum age, so that the expired events are removed even if
+// no event is logged.
+var journalCompactInterval = time.Minute
+
+// segment is a file of the journal, named after the time of its first event
+// and holding one JSON encoded event per line.
+type segment struct {
+	first   int64 // first is the time of the first event in nanoseconds
+	size    int64
+	modTime time.Time
+}
+
+func (s *segment) name() string {
+	return fmt.Sprintf("%019d%s", s.first, segmentSuffix)
+}
+
+// Journal records the events on disk so that they are kept beyond the last
+// ones stored in memory and across the restarts of the daemon. It is bounded
+// by a maximum size and, optionally, a maximum age: the oldest segments are
+// removed when a new one is started, when Compact is called, and
+// periodically if the journal has a maximum age.
+type Journal struct {
+	mu       sync.Mutex
+	root     string
+	maxSize  int64
+	maxAge   time.Duration
+	segments []*segment // segments are sorted, the last one is written to
+	f        *os.File
+	stop     chan struct{}
+}
+
+// NewJournal opens the journal in the directory root, creating it if needed,
+// and compacts it, then periodically if maxAge is set.
+func NewJournal(root string, maxSize int64, maxAge time.Duration) (*Journal, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid events journal size %d", maxSize)
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{root: root, maxSize: maxSize, maxAge: maxAge}
+	for _, fi := range files {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), segmentSuffix) {
+			continue
+		}
+		first, err := strconv.ParseInt(strings.TrimSuffix(fi.Name(), segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		j.segments = append(j.segments, &segment{first: first, size: fi.Size(), modTime: fi.ModTime()})
+	}
+	sort.Sort(byFirst(j.segments))
+
+	if len(j.segments) > 0 {
+		if err := j.openLast(); err != nil {
+			return nil, err
+		}
+	}
+	if err := j.compact(time.Now()); err != nil {
+		j.Close()
+		return nil, err
+	}
+	if maxAge > 0 {
+		j.stop = make(chan struct{})
+		go j.compactPeriodically(j.stop)
+	}
+	return j, nil
+}
+
+// compactPeriodically compacts the journal every journalCompactInterval
+// until stop is closed.
+func (j *Journal) compactPeriodically(stop chan struct{}) {
+	ticker := time.NewTicker(journalCompactInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.Compact(); err != nil {
+				logrus.Warnf("Failed to compact the events journal: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// openLast opens the last segment to append to it. A partial line left by a
+// crash is terminated so that the next event starts on a line of its own.
+func (j *Journal) openLast() error {
+	s := j.segments[len(j.segments)-1]
+	f, err := os.OpenFile(filepath.Join(j.root, s.name()), os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if s.size > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, s.size-1); err != nil {
+			f.Close()
+			return err
+		}
+		if last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				f.Close()
+				return err
+			}
+			s.size++
+		}
+	}
+	j.f = f
+	return nil
+}
+
+// Write appends the event to the journal, starting a new segment when the
+// current one is full.
+func (j *Journal) Write(jm *eventtypes.Message) error {
+	b, err := json.Marshal(jm)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil || j.segments[len(j.segments)-1].size >= j.segmentSize() {
+		if err := j.rotate(jm.TimeNano); err != nil {
+			return err
+		}
+	}
+
+	s := j.segments[len(j.segments)-1]
+	n, err := j.f.Write(b)
+	s.size += int64(n)
+	s.modTime = time.Unix(0, jm.TimeNano)
+	return err
+}
+
+func (j *Journal) segmentSize() int64 {
+	if size := j.maxSize / journalSegments; size > 0 {
+		return size
+	}
+	return j.maxSize
+}
+
+// rotate starts a new segment with the event logged at time first, and
+// removes the oldest segments beyond the bounds of the journal.
+func (j *Journal) rotate(first int64) error {
+	if j.f != nil {
+		j.f.Close()
+		j.f = nil
+	}
+	// The times of the events are not guaranteed to increase, but the names
+	// of the segments must be ordered.
+	if n := len(j.segments); n > 0 && first <= j.segments[n-1].first {
+		first = j.segments[n-1].first + 1
+	}
+
+	s := &segment{first: first}
+	f, err := os.OpenFile(filepath.Join(j.root, s.name()), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	j.f = f
+	j.segments = append(j.segments, s)
+	return j.compact(time.Now())
+}
+
+// Compact removes the segments, but for the one being written, which are
+// beyond the maximum size of the journal or only hold events older than its
+// maximum age.
+func (j *Journal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.compact(time.Now())
+}
+
+func (j *Journal) compact(now time.Time) error {
+	var total int64
+	for _, s := range j.segments {
+		total += s.size
+	}
+
+	for len(j.segments) > 1 {
+		s := j.segments[0]
+		expired := j.maxAge > 0 && s.modTime.Before(now.Add(-j.maxAge))
+		if total <= j.maxSize && !expired {
+			break
+		}
+		if err := os.Remove(filepath.Join(j.root, s.name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		logrus.Debugf("Removed events journal segment %s", s.name())
+		total -= s.size
+		j.segments = j.segments[1:]
+	}
+	return nil
+}
+
+// Read calls fn in order with the events of the journal logged between since
+// and until, both included and compared at the second like the events API
+// does, and stops at the first error fn returns. A zero until reads up to
+// the last event. The segments are read without holding the lock of the
+// journal, so that the events keep being written meanwhile: a segment
+// removed by a compaction is skipped, and a line being written is ignored.
+func (j *Journal) Read(since, until time.Time, fn func(*eventtypes.Message) error) error {
+	j.mu.Lock()
+	segments := make([]*segment, len(j.segments))
+	copy(segments, j.segments)
+	j.mu.Unlock()
+
+	for i, s := range segments {
+		// The events of a segment are older than the first one of the next.
+		if i+1 < len(segments) && segments[i+1].first < since.UnixNano() {
+			continue
+		}
+		if !until.IsZero() && time.Unix(0, s.first).Unix() > until.Unix() {
+			break
+		}
+		if err := j.readSegment(s, since, until, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *Journal) readSegment(s *segment, since, until time.Time, fn func(*eventtypes.Message) error) error {
+	f, err := os.Open(filepath.Join(j.root, s.name()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			jm := &eventtypes.Message{}
+			if err := json.Unmarshal(line, jm); err != nil {
+				// A line may have been cut by a crash of the daemon.
+				logrus.Debugf("Skipping invalid event in %s: %v", s.name(), err)
+			} else if jm.Time >= since.Unix() && (until.IsZero() || jm.Time <= until.Unix()) {
+				if err := fn(upgradeLegacyEvent(jm)); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// upgradeLegacyEvent sets the type, the action and the actor of an event
+// recorded before the events had them. Only the container events had an
+// image, the other ones were image events.
+func upgradeLegacyEvent(jm *eventtypes.Message) *eventtypes.Message {
+	if jm.Type != "" {
+		return jm
+	}
+	jm.Action = jm.Status
+	jm.Actor.ID = jm.ID
+	if jm.From != "" {
+		jm.Type = eventtypes.ContainerEventType
+		jm.Actor.Attributes = map[string]string{"image": jm.From}
+	} else {
+		jm.Type = eventtypes.ImageEventType
+	}
+	return jm
+}
+
+// Close stops the periodic compaction and closes the segment being written.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stop != nil {
+		close(j.stop)
+		j.stop = nil
+	}
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+type byFirst []*segment
+
+func (s byFirst) Len() int           { return len(s) }
+func (s byFirst) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byFirst) Less(i, j int) bool { return s[i].first < s[j].first }
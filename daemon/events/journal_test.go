@@ -0,0 +1,338 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+func newTestEvent(i int, t time.Time) *eventtypes.Message {
+	action := fmt.Sprintf("action_%d", i)
+	return &eventtypes.Message{
+		Status:   action,
+		ID:       "cont",
+		Type:     eventtypes.ContainerEventType,
+		Action:   action,
+		Actor:    eventtypes.Actor{ID: "cont"},
+		Time:     t.Unix(),
+		TimeNano: t.UnixNano(),
+	}
+}
+
+// readJournal returns the events of the journal read between since and until.
+func readJournal(j *Journal, since, until time.Time) ([]*eventtypes.Message, error) {
+	var events []*eventtypes.Message
+	err := j.Read(since, until, func(jm *eventtypes.Message) error {
+		events = append(events, jm)
+		return nil
+	})
+	return events, err
+}
+
+func TestJournalReadSinceUntil(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := NewJournal(root, 1024*1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Unix(1445000000, 0)
+	for i := 0; i < 10; i++ {
+		if err := j.Write(newTestEvent(i, base.Add(time.Duration(i)*time.Second))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, err := readJournal(j, base.Add(3*time.Second), base.Add(6*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 || events[0].Status != "action_3" || events[3].Status != "action_6" {
+		t.Fatalf("Expected the events 3 to 6, got %v", events)
+	}
+
+	// The events are still there once the journal is opened again.
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if j, err = NewJournal(root, 1024*1024, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if err := j.Write(newTestEvent(10, base.Add(10*time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	events, err = readJournal(j, base.Add(8*time.Second), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[2].Status != "action_10" {
+		t.Fatalf("Expected the events 8 to 10, got %v", events)
+	}
+}
+
+func TestJournalCompact(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// Each segment holds a few events only.
+	j, err := NewJournal(root, 8*512, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	base := time.Unix(1445000000, 0)
+	for i := 0; i < 200; i++ {
+		if err := j.Write(newTestEvent(i, base.Add(time.Duration(i)*time.Second))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, fi := range files {
+		total += fi.Size()
+	}
+	b, err := json.Marshal(newTestEvent(199, base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The journal is compacted when a segment is started, the one being
+	// written may then reach its share and exceed it by one event.
+	if total > 8*512+512+int64(len(b)+1) {
+		t.Fatalf("Expected the journal to be bounded to %d bytes, got %d in %d files", 8*512, total, len(files))
+	}
+
+	events, err := readJournal(j, base, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 || len(events) == 200 || events[len(events)-1].Status != "action_199" {
+		t.Fatalf("Expected the oldest events only to be removed, got %d events", len(events))
+	}
+
+	// The segments older than the maximum age are removed, but for the one
+	// being written.
+	j.maxAge = time.Hour
+	if err := j.compact(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(j.segments) != 1 {
+		t.Fatalf("Expected only the last segment to be kept, got %d", len(j.segments))
+	}
+}
+
+func TestJournalPartialLine(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// A crash left the last event cut.
+	content := `{"status":"action_0","id":"cont","from":"busybox","time":1445000000}` + "\n" + `{"status":"act`
+	if err := ioutil.WriteFile(filepath.Join(root, "1445000000000000000.log"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := NewJournal(root, 1024*1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if err := j.Write(newTestEvent(1, time.Unix(1445000001, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := readJournal(j, time.Unix(0, 0), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Status != "action_0" || events[1].Status != "action_1" {
+		t.Fatalf("Expected the events 0 and 1, got %v", events)
+	}
+	// The event recorded before the events had a type is upgraded.
+	if events[0].Type != eventtypes.ContainerEventType || events[0].Action != "action_0" || events[0].Actor.ID != "cont" || events[0].Actor.Attributes["image"] != "busybox" {
+		t.Fatalf("Expected the legacy event to be upgraded, got %+v", events[0])
+	}
+}
+
+func TestSubscribeSinceJournal(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := NewJournal(root, 1024*1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewWithJournal(j)
+	defer e.Close()
+
+	since := time.Now()
+	// More events than the ones stored in memory are replayed.
+	for i := 0; i < eventsLimit+16; i++ {
+		e.Log(fmt.Sprintf("action_%d", i), eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	}
+	var current []*eventtypes.Message
+	l, err := e.SubscribeSince(since, time.Time{}, func(jm *eventtypes.Message) error {
+		current = append(current, jm)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Evict(l)
+	if len(current) != eventsLimit+16 {
+		t.Fatalf("Must be %d events, got %d", eventsLimit+16, len(current))
+	}
+	if current[0].Status != "action_0" {
+		t.Fatalf("First action is %s, must be action_0", current[0].Status)
+	}
+}
+
+func TestSubscribeSinceNoDuplicates(t *testing.T) {
+	e := New()
+	since := time.Now()
+	e.Log("past", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+
+	var current []*eventtypes.Message
+	l, err := e.SubscribeSince(since, time.Time{}, func(jm *eventtypes.Message) error {
+		current = append(current, jm)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Evict(l)
+	if len(current) != 1 || current[0].Action != "past" {
+		t.Fatalf("Expected the past event, got %v", current)
+	}
+
+	// A past event published after subscribing is not received again.
+	e.pub.Publish(current[0])
+	e.Log("live", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	select {
+	case msg := <-l:
+		if jm := msg.(*eventtypes.Message); jm.Action != "live" {
+			t.Fatalf("Expected the live event, got %s", jm.Action)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the live event")
+	}
+}
+
+func TestSubscribeSinceLogsWhileReplaying(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := NewJournal(root, 1024*1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewWithJournal(j)
+	defer e.Close()
+
+	since := time.Now()
+	e.Log("past", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+
+	// The events are logged while the journal is replayed, and the ones
+	// logged meanwhile are received by the listener only.
+	var current []*eventtypes.Message
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l, err := e.SubscribeSince(since, time.Time{}, func(jm *eventtypes.Message) error {
+			current = append(current, jm)
+			e.Log("live", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		e.Evict(l)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout logging an event while the journal is replayed")
+	}
+	if len(current) != 1 || current[0].Action != "past" {
+		t.Fatalf("Expected the past event only, got %v", current)
+	}
+
+	// The listener is evicted when the replay fails.
+	_, err = e.SubscribeSince(since, time.Time{}, func(jm *eventtypes.Message) error {
+		return fmt.Errorf("client gone")
+	})
+	if err == nil || err.Error() != "client gone" {
+		t.Fatalf("Expected the error of the replay, got %v", err)
+	}
+	if n := e.SubscribersCount(); n != 0 {
+		t.Fatalf("Expected the listener to be evicted, got %d listeners", n)
+	}
+}
+
+func TestJournalCompactsPeriodically(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	old := journalCompactInterval
+	journalCompactInterval = 10 * time.Millisecond
+	defer func() { journalCompactInterval = old }()
+
+	// Each segment holds a few events only.
+	j, err := NewJournal(root, 8*512, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	for i := 0; i < 10; i++ {
+		if err := j.Write(newTestEvent(i, time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The segments expire while no event is written.
+	j.mu.Lock()
+	if len(j.segments) < 2 {
+		j.mu.Unlock()
+		t.Fatalf("Expected several segments, got %d", len(j.segments))
+	}
+	j.maxAge = time.Nanosecond
+	j.mu.Unlock()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		j.mu.Lock()
+		n := len(j.segments)
+		j.mu.Unlock()
+		if n == 1 {
+			return
+		}
+	}
+	t.Fatal("Expected the expired segment to be removed")
+}
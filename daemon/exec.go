@@ -34,6 +34,9 @@ type ExecConfig struct {
 	Container  *Container
 	canRemove  bool
 
+	// pid is the host pid of the exec process once it has started.
+	pid int
+
 	// waitStart will be closed immediately after the exec is really started.
 	waitStart chan struct{}
 }
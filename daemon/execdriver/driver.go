@@ -2,10 +2,12 @@ package execdriver
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os/exec"
 	"time"
 
+	"github.com/docker/docker/pkg/idtools"
 	// TODO Windows: Factor out ulimit
 	"github.com/docker/docker/pkg/ulimit"
 	"github.com/opencontainers/runc/libcontainer"
@@ -22,6 +24,7 @@ var (
 	ErrWaitTimeoutReached      = errors.New("Wait timeout reached")
 	ErrDriverAlreadyRegistered = errors.New("A driver already registered this docker init function")
 	ErrDriverNotFound          = errors.New("The requested docker init has not been found")
+	ErrRestoreNotSupported     = errors.New("The exec driver does not support restoring running containers")
 )
 
 // DriverCallback defines a callback function which is used in "Run" and "Exec".
@@ -71,6 +74,13 @@ type Driver interface {
 	// the exit code. It's the last stage on Docker side for running a container.
 	Run(c *Command, pipes *Pipes, hooks Hooks) (ExitStatus, error)
 
+	// Restore reattaches to the process of a container which kept running
+	// while the daemon was down, blocks until the process exits and returns
+	// the exit code. The Start hook is called once the process is found.
+	// If the process exited while the daemon was down, ErrNotRunning is
+	// returned along with the exit code it left, or -1 if it is not known.
+	Restore(c *Command, pipes *Pipes, hooks Hooks) (ExitStatus, error)
+
 	// Exec executes the process in an existing container, blocks until the
 	// process exits and returns the exit code.
 	Exec(c *Command, processConfig *ProcessConfig, pipes *Pipes, hooks Hooks) (int, error)
@@ -103,6 +113,10 @@ type Driver interface {
 	// Stats returns resource stats for a running container
 	Stats(id string) (*ResourceStats, error)
 
+	// Update updates the resource limits of a running container to
+	// match c.Resources.
+	Update(c *Command) error
+
 	// SupportsHooks refers to the driver capability to exploit pre/post hook functionality
 	SupportsHooks() bool
 }
@@ -141,19 +155,51 @@ type UTS struct {
 // Currently these are all for cgroup configs.
 // TODO Windows: Factor out ulimit.Rlimit
 type Resources struct {
-	Memory            int64            `json:"memory"`
-	MemorySwap        int64            `json:"memory_swap"`
-	MemoryReservation int64            `json:"memory_reservation"`
-	KernelMemory      int64            `json:"kernel_memory"`
-	CPUShares         int64            `json:"cpu_shares"`
-	CpusetCpus        string           `json:"cpuset_cpus"`
-	CpusetMems        string           `json:"cpuset_mems"`
-	CPUPeriod         int64            `json:"cpu_period"`
-	CPUQuota          int64            `json:"cpu_quota"`
-	BlkioWeight       int64            `json:"blkio_weight"`
-	Rlimits           []*ulimit.Rlimit `json:"rlimits"`
-	OomKillDisable    bool             `json:"oom_kill_disable"`
-	MemorySwappiness  int64            `json:"memory_swappiness"`
+	Memory                       int64             `json:"memory"`
+	MemorySwap                   int64             `json:"memory_swap"`
+	MemoryReservation            int64             `json:"memory_reservation"`
+	KernelMemory                 int64             `json:"kernel_memory"`
+	CPUShares                    int64             `json:"cpu_shares"`
+	CpusetCpus                   string            `json:"cpuset_cpus"`
+	CpusetMems                   string            `json:"cpuset_mems"`
+	CPUPeriod                    int64             `json:"cpu_period"`
+	CPUQuota                     int64             `json:"cpu_quota"`
+	BlkioWeight                  int64             `json:"blkio_weight"`
+	BlkioWeightDevice            []*WeightDevice   `json:"blkio_weight_device"`
+	BlkioThrottleReadBpsDevice   []*ThrottleDevice `json:"blkio_throttle_read_bps_device"`
+	BlkioThrottleWriteBpsDevice  []*ThrottleDevice `json:"blkio_throttle_write_bps_device"`
+	BlkioThrottleReadIOpsDevice  []*ThrottleDevice `json:"blkio_throttle_read_iops_device"`
+	BlkioThrottleWriteIOpsDevice []*ThrottleDevice `json:"blkio_throttle_write_iops_device"`
+	Rlimits                      []*ulimit.Rlimit  `json:"rlimits"`
+	OomKillDisable               bool              `json:"oom_kill_disable"`
+	MemorySwappiness             int64             `json:"memory_swappiness"`
+	PidsLimit                    int64             `json:"pids_limit"`
+}
+
+// WeightDevice is the relative block IO weight of a device, identified by
+// its major and minor numbers.
+type WeightDevice struct {
+	Major  int64  `json:"major"`
+	Minor  int64  `json:"minor"`
+	Weight uint16 `json:"weight"`
+}
+
+// String returns the rule of the blkio.weight_device cgroup file.
+func (w *WeightDevice) String() string {
+	return fmt.Sprintf("%d:%d %d", w.Major, w.Minor, w.Weight)
+}
+
+// ThrottleDevice is the block IO rate limit of a device, identified by its
+// major and minor numbers.
+type ThrottleDevice struct {
+	Major int64  `json:"major"`
+	Minor int64  `json:"minor"`
+	Rate  uint64 `json:"rate"`
+}
+
+// String returns the rule of the blkio.throttle cgroup files.
+func (t *ThrottleDevice) String() string {
+	return fmt.Sprintf("%d:%d %d", t.Major, t.Minor, t.Rate)
 }
 
 // ResourceStats contains information about resource usage by a container.
@@ -162,6 +208,7 @@ type ResourceStats struct {
 	Read        time.Time `json:"read"`
 	MemoryLimit int64     `json:"memory_limit"`
 	SystemUsage uint64    `json:"system_usage"`
+	PidsCurrent uint64    `json:"pids_current"`
 }
 
 // Mount contains information for a mount operation.
@@ -171,6 +218,7 @@ type Mount struct {
 	Writable    bool   `json:"writable"`
 	Private     bool   `json:"private"`
 	Slave       bool   `json:"slave"`
+	Data        string `json:"data"`
 }
 
 // ProcessConfig describes a process that will be run inside a container.
@@ -219,4 +267,11 @@ type Command struct {
 	FirstStart         bool              `json:"first_start"`
 	LayerPaths         []string          `json:"layer_paths"` // Windows needs to know the layer paths and folder for a command
 	LayerFolder        string            `json:"layer_folder"`
+	LiveRestore        bool              `json:"live_restore"` // keep the process running when the daemon exits
+	UIDMapping         []idtools.IDMap   `json:"uidmapping"`   // uid mappings of the user namespace, if any
+	GIDMapping         []idtools.IDMap   `json:"gidmapping"`   // gid mappings of the user namespace, if any
+	Seccomp            *configs.Seccomp  `json:"seccomp"`      // syscall filter, nil if unconfined
+	Sysctls            map[string]string `json:"sysctls"`      // namespaced sysctls set in the container
+	Init               bool              `json:"init"`         // run an init process as PID 1, which starts the entrypoint
+	ShmSize            int64             `json:"shm_size"`     // size of /dev/shm in bytes
 }
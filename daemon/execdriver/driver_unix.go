@@ -12,11 +12,30 @@ import (
 	"time"
 
 	"github.com/docker/docker/daemon/execdriver/native/template"
+	"github.com/docker/docker/pkg/mount"
 	"github.com/opencontainers/runc/libcontainer"
 	"github.com/opencontainers/runc/libcontainer/cgroups/fs"
 	"github.com/opencontainers/runc/libcontainer/configs"
 )
 
+// defaultTmpfsOptions are the mount options of the tmpfs mounts of the
+// containers which the options of a mount do not override.
+var defaultTmpfsOptions = []string{"noexec", "nosuid", "nodev", "size=65536k"}
+
+// TmpfsOptions returns the mount options of a tmpfs mount given its data,
+// the default options merged with the ones set in data.
+func TmpfsOptions(data string) (string, error) {
+	options := defaultTmpfsOptions
+	if data != "" {
+		options = append(append([]string{}, options...), strings.Split(data, ",")...)
+	}
+	merged, err := mount.MergeTmpfsOptions(options)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(merged, ","), nil
+}
+
 // Network settings of the container
 type Network struct {
 	Mtu            int    `json:"mtu"`
@@ -66,6 +85,7 @@ func SetupCgroups(container *configs.Config, c *Command) error {
 		container.Cgroups.Memory = c.Resources.Memory
 		container.Cgroups.MemoryReservation = c.Resources.MemoryReservation
 		container.Cgroups.MemorySwap = c.Resources.MemorySwap
+		container.Cgroups.KernelMemory = c.Resources.KernelMemory
 		container.Cgroups.CpusetCpus = c.Resources.CpusetCpus
 		container.Cgroups.CpusetMems = c.Resources.CpusetMems
 		container.Cgroups.CpuPeriod = c.Resources.CPUPeriod
@@ -885,6 +885,68 @@ func (d *Driver) Stats(id string) (*execdriver.ResourceStats, error) {
 	return execdriver.Stats(d.containerDir(id), d.activeContainers[id].container.Cgroups.Memory, d.machineMemory)
 }
 
+// Restore implements the exec driver Driver interface.
+// The lxc driver cannot reattach to containers of a previous daemon.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	return execdriver.ExitStatus{ExitCode: -1}, execdriver.ErrRestoreNotSupported
+}
+
+// Update implements the exec driver Driver interface,
+// it executes lxc-cgroup to apply the new resource limits to a running container.
+func (d *Driver) Update(c *execdriver.Command) error {
+	if c.Resources == nil {
+		return nil
+	}
+	var settings [][2]string
+	if c.Resources.CPUShares != 0 {
+		settings = append(settings, [2]string{"cpu.shares", strconv.FormatInt(c.Resources.CPUShares, 10)})
+	}
+	if c.Resources.CPUQuota != 0 {
+		settings = append(settings, [2]string{"cpu.cfs_quota_us", strconv.FormatInt(c.Resources.CPUQuota, 10)})
+	}
+	if c.Resources.CpusetCpus != "" {
+		settings = append(settings, [2]string{"cpuset.cpus", c.Resources.CpusetCpus})
+	}
+	if c.Resources.BlkioWeight != 0 {
+		settings = append(settings, [2]string{"blkio.weight", strconv.FormatInt(c.Resources.BlkioWeight, 10)})
+	}
+	if c.Resources.KernelMemory != 0 {
+		settings = append(settings, [2]string{"memory.kmem.limit_in_bytes", strconv.FormatInt(c.Resources.KernelMemory, 10)})
+	}
+	for _, setting := range settings {
+		if err := setLxcCgroup(c.ID, setting[0], setting[1]); err != nil {
+			return err
+		}
+	}
+
+	if c.Resources.Memory == 0 {
+		return nil
+	}
+	memory := strconv.FormatInt(c.Resources.Memory, 10)
+	if c.Resources.MemorySwap <= 0 {
+		return setLxcCgroup(c.ID, "memory.limit_in_bytes", memory)
+	}
+	swap := strconv.FormatInt(c.Resources.MemorySwap, 10)
+	// The memory limit can never exceed the memory+swap limit, so when the
+	// memory limit is raised above the current memory+swap limit the swap
+	// limit has to be raised first.
+	if err := setLxcCgroup(c.ID, "memory.limit_in_bytes", memory); err != nil {
+		if err := setLxcCgroup(c.ID, "memory.memsw.limit_in_bytes", swap); err != nil {
+			return err
+		}
+		return setLxcCgroup(c.ID, "memory.limit_in_bytes", memory)
+	}
+	return setLxcCgroup(c.ID, "memory.memsw.limit_in_bytes", swap)
+}
+
+func setLxcCgroup(id, key, value string) error {
+	output, err := exec.Command("lxc-cgroup", "-n", id, key, value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Err: %s Output: %s", err, output)
+	}
+	return nil
+}
+
 // SupportsHooks implements the execdriver Driver interface.
 // The LXC execdriver does not support the hook mechanism, which is currently unique to runC/libcontainer.
 func (d *Driver) SupportsHooks() bool {
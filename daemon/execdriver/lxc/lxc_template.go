@@ -76,11 +76,13 @@ lxc.mount.entry = {{.ProcessConfig.Console}} {{escapeFstabSpaces $ROOTFS}}/dev/c
 {{end}}
 
 lxc.mount.entry = devpts {{escapeFstabSpaces $ROOTFS}}/dev/pts devpts {{formatMountLabel "newinstance,ptmxmode=0666,nosuid,noexec,create=dir" ""}} 0 0
-lxc.mount.entry = shm {{escapeFstabSpaces $ROOTFS}}/dev/shm tmpfs {{formatMountLabel "size=65536k,nosuid,nodev,noexec,create=dir" ""}} 0 0
+lxc.mount.entry = shm {{escapeFstabSpaces $ROOTFS}}/dev/shm tmpfs {{formatMountLabel (printf "mode=1777,size=%d,nosuid,nodev,noexec,create=dir" .ShmSize) ""}} 0 0
 
 {{range $value := .Mounts}}
 {{$createVal := isDirectory $value.Source}}
-{{if $value.Writable}}
+{{if eq $value.Source "tmpfs"}}
+lxc.mount.entry = tmpfs {{escapeFstabSpaces $ROOTFS}}/{{escapeFstabSpaces $value.Destination}} tmpfs {{formatMountLabel (tmpfsOptions $value.Data) ""}} 0 0
+{{else if $value.Writable}}
 lxc.mount.entry = {{$value.Source}} {{escapeFstabSpaces $ROOTFS}}/{{escapeFstabSpaces $value.Destination}} none rbind,rw,create={{$createVal}} 0 0
 {{else}}
 lxc.mount.entry = {{$value.Source}} {{escapeFstabSpaces $ROOTFS}}/{{escapeFstabSpaces $value.Destination}} none rbind,ro,create={{$createVal}} 0 0
@@ -119,6 +121,24 @@ lxc.cgroup.cpu.cfs_quota_us = {{.Resources.CPUQuota}}
 {{if .Resources.BlkioWeight}}
 lxc.cgroup.blkio.weight = {{.Resources.BlkioWeight}}
 {{end}}
+{{range .Resources.BlkioWeightDevice}}
+lxc.cgroup.blkio.weight_device = {{.}}
+{{end}}
+{{range .Resources.BlkioThrottleReadBpsDevice}}
+lxc.cgroup.blkio.throttle.read_bps_device = {{.}}
+{{end}}
+{{range .Resources.BlkioThrottleWriteBpsDevice}}
+lxc.cgroup.blkio.throttle.write_bps_device = {{.}}
+{{end}}
+{{range .Resources.BlkioThrottleReadIOpsDevice}}
+lxc.cgroup.blkio.throttle.read_iops_device = {{.}}
+{{end}}
+{{range .Resources.BlkioThrottleWriteIOpsDevice}}
+lxc.cgroup.blkio.throttle.write_iops_device = {{.}}
+{{end}}
+{{if gt .Resources.PidsLimit 0}}
+lxc.cgroup.pids.max = {{.Resources.PidsLimit}}
+{{end}}
 {{if .Resources.OomKillDisable}}
 lxc.cgroup.memory.oom_control = {{.Resources.OomKillDisable}}
 {{end}}
@@ -194,6 +214,16 @@ func dropList(drops []string) ([]string, error) {
 	return []string{}, nil
 }
 
+// tmpfsOptions returns the mount options of a tmpfs mount given its data,
+// with the same defaults as the native driver.
+func tmpfsOptions(data string) (string, error) {
+	options, err := execdriver.TmpfsOptions(data)
+	if err != nil {
+		return "", err
+	}
+	return options + ",create=dir", nil
+}
+
 func isDirectory(source string) string {
 	f, err := os.Stat(source)
 	logrus.Debugf("dir: %s\n", source)
@@ -246,6 +276,7 @@ func init() {
 		"escapeFstabSpaces": escapeFstabSpaces,
 		"formatMountLabel":  label.FormatMountLabel,
 		"isDirectory":       isDirectory,
+		"tmpfsOptions":      tmpfsOptions,
 		"keepCapabilities":  keepCapabilities,
 		"dropList":          dropList,
 		"getHostname":       getHostname,
@@ -224,6 +224,7 @@ func TestCustomLxcConfigMounts(t *testing.T) {
 		},
 		Mounts:        mounts,
 		ProcessConfig: processConfig,
+		ShmSize:       134217728,
 	}
 
 	p, err := driver.generateLXCConfig(command)
@@ -233,6 +234,7 @@ func TestCustomLxcConfigMounts(t *testing.T) {
 
 	grepFile(t, p, "lxc.utsname = docker")
 	grepFile(t, p, "lxc.cgroup.cpuset.cpus = 0,1")
+	grepFile(t, p, "lxc.mount.entry = shm /dev/shm tmpfs mode=1777,size=134217728,nosuid,nodev,noexec,create=dir 0 0")
 
 	grepFile(t, p, fmt.Sprintf("lxc.mount.entry = %s %s none rbind,ro,create=%s 0 0", tempDir, "/"+tempDir, "dir"))
 	grepFile(t, p, fmt.Sprintf("lxc.mount.entry = %s %s none rbind,rw,create=%s 0 0", tempFile.Name(), "/"+tempFile.Name(), "file"))
@@ -0,0 +1,194 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// writeCgroupFile writes data to the file of a cgroup.
+func writeCgroupFile(dir, file, data string) error {
+	return ioutil.WriteFile(filepath.Join(dir, file), []byte(data), 0700)
+}
+
+// readCgroupUint reads the unsigned integer of the file of a cgroup.
+func readCgroupUint(dir, file string) (uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// raiseMemorySwapLimit writes the memory+swap limit of the container before
+// libcontainer sets its memory limit when the new memory limit is above the
+// current memory+swap limit, as the kernel refuses a memory limit larger
+// than the memory+swap limit. libcontainer writes the memory limit first,
+// which only works when the limits are lowered. An unlimited memory+swap
+// limit (-1) is always written here, as libcontainer only writes positive
+// ones.
+func raiseMemorySwapLimit(cont libcontainer.Container, cgroup *configs.Cgroup) error {
+	if cgroup.MemorySwap == 0 || (cgroup.Memory == 0 && cgroup.MemorySwap > 0) {
+		return nil
+	}
+	state, err := cont.State()
+	if err != nil {
+		return err
+	}
+	dir, ok := state.CgroupPaths["memory"]
+	if !ok {
+		return nil
+	}
+	if cgroup.MemorySwap == -1 {
+		if _, err := os.Stat(filepath.Join(dir, "memory.memsw.limit_in_bytes")); err != nil {
+			// Swap accounting is disabled.
+			return nil
+		}
+		return writeCgroupFile(dir, "memory.memsw.limit_in_bytes", "-1")
+	}
+	current, err := readCgroupUint(dir, "memory.memsw.limit_in_bytes")
+	if err != nil || uint64(cgroup.Memory) <= current {
+		// Swap accounting is disabled, or the limits can be written in order.
+		return nil
+	}
+	return writeCgroupFile(dir, "memory.memsw.limit_in_bytes", strconv.FormatInt(cgroup.MemorySwap, 10))
+}
+
+// cgroupDir returns the directory of the cgroup of the subsystem of the
+// container whose init process is pid, at the same path as its devices
+// cgroup in the hierarchy of the subsystem. It returns "" if the subsystem
+// is not mounted. It finds the cgroups of the subsystems which the vendored
+// libcontainer does not manage or set up as docker needs.
+func cgroupDir(subsystem string, pid int) (string, error) {
+	mountpoint, root, err := cgroups.FindCgroupMountpointAndRoot(subsystem)
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	paths, err := cgroups.ParseCgroupFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	devices, ok := paths["devices"]
+	if !ok {
+		return "", nil
+	}
+	rel, err := filepath.Rel(root, devices)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mountpoint, rel), nil
+}
+
+// joinPidsCgroup moves the init process pid of a container to its pids
+// cgroup, and sets the limit of the number of processes of the cgroup,
+// where -1 is unlimited and 0 leaves the limit unchanged. It is run as a
+// prestart hook, once libcontainer has set the other cgroups up.
+func joinPidsCgroup(pid int, limit int64) error {
+	dir, err := cgroupDir("pids", pid)
+	if err != nil || dir == "" {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if limit != 0 {
+		value := "max"
+		if limit > 0 {
+			value = strconv.FormatInt(limit, 10)
+		}
+		if err := writeCgroupFile(dir, "pids.max", value); err != nil {
+			return err
+		}
+	}
+	return writeCgroupFile(dir, "cgroup.procs", strconv.Itoa(pid))
+}
+
+// addPidsCgroupPath adds the pids cgroup of the container whose init process
+// is pid to the cgroup paths of cont, which libcontainer makes the processes
+// exec'd in the container enter before they run. The vendored libcontainer
+// does not manage the pids subsystem, and its cgroup managers return their
+// paths by reference. It is called before the container is reported as
+// running, while nothing else uses the paths.
+func addPidsCgroupPath(cont libcontainer.Container, pid int) error {
+	dir, err := cgroupDir("pids", pid)
+	if err != nil || dir == "" {
+		return err
+	}
+	state, err := cont.State()
+	if err != nil {
+		return err
+	}
+	if state.CgroupPaths != nil {
+		state.CgroupPaths["pids"] = dir
+	}
+	return nil
+}
+
+// removePidsCgroup returns a function removing the pids cgroup of the
+// container whose init process is pid, to call once it has exited.
+func removePidsCgroup(pid int) func() {
+	dir, err := cgroupDir("pids", pid)
+	if err != nil || dir == "" {
+		return func() {}
+	}
+	return func() {
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to remove pids cgroup %s: %v", dir, err)
+		}
+	}
+}
+
+// currentPids returns the number of processes in the pids cgroup of the
+// container whose init process is pid.
+func currentPids(pid int) (uint64, error) {
+	dir, err := cgroupDir("pids", pid)
+	if err != nil || dir == "" {
+		return 0, err
+	}
+	return readCgroupUint(dir, "pids.current")
+}
+
+// setBlkioDevices writes the per device block IO rules of the resources to
+// the blkio cgroup of the container whose init process is pid. Each rule is
+// written on its own, as the kernel parses one rule per write.
+func setBlkioDevices(pid int, r *execdriver.Resources) error {
+	if len(r.BlkioWeightDevice)+len(r.BlkioThrottleReadBpsDevice)+len(r.BlkioThrottleWriteBpsDevice)+len(r.BlkioThrottleReadIOpsDevice)+len(r.BlkioThrottleWriteIOpsDevice) == 0 {
+		return nil
+	}
+	dir, err := cgroupDir("blkio", pid)
+	if err != nil || dir == "" {
+		return err
+	}
+	for _, wd := range r.BlkioWeightDevice {
+		if err := writeCgroupFile(dir, "blkio.weight_device", wd.String()); err != nil {
+			return err
+		}
+	}
+	for file, devices := range map[string][]*execdriver.ThrottleDevice{
+		"blkio.throttle.read_bps_device":   r.BlkioThrottleReadBpsDevice,
+		"blkio.throttle.write_bps_device":  r.BlkioThrottleWriteBpsDevice,
+		"blkio.throttle.read_iops_device":  r.BlkioThrottleReadIOpsDevice,
+		"blkio.throttle.write_iops_device": r.BlkioThrottleWriteIOpsDevice,
+	} {
+		for _, td := range devices {
+			if err := writeCgroupFile(dir, file, td.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
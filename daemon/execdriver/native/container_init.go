@@ -0,0 +1,115 @@
+// +build linux
+
+package native
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/pkg/term"
+)
+
+// containerInitPath is where dockerinit is mounted in the containers run
+// with an init process, and the name under which it runs that process.
+const containerInitPath = "/dev/init"
+
+// containerExitStatusPath is where the file the init process writes the exit
+// status of its command to is mounted, in the containers which can be
+// restored. Their process is not a child of the daemon which restores them,
+// which reads the status from the file instead.
+const containerExitStatusPath = "/dev/init.exitstatus"
+
+func init() {
+	reexec.Register(containerInitPath, containerInit)
+}
+
+// containerInit runs as PID 1 of the containers started with --init. It
+// starts the command given in its arguments, forwards it the signals it
+// receives, reaps the orphaned processes reparented to it and exits with
+// the status of the command.
+func containerInit() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "init: no command to run")
+		os.Exit(1)
+	}
+
+	// Catch the signals before the command starts so that none is lost.
+	signals := make(chan os.Signal, 32)
+	signal.Notify(signals)
+
+	cmd := exec.Command(os.Args[1], os.Args[2:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// The command gets its own process group, which is made the foreground
+	// one of the terminal, so that the signals sent from the terminal reach
+	// it only once.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:    true,
+		Foreground: term.IsTerminal(os.Stdin.Fd()),
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "init: %v\n", err)
+		// Like a shell, 127 reports a command which is not found.
+		if e, ok := err.(*exec.Error); (ok && e.Err == exec.ErrNotFound) || os.IsNotExist(err) {
+			os.Exit(127)
+		}
+		os.Exit(126)
+	}
+	pid := cmd.Process.Pid
+
+	go func() {
+		for sig := range signals {
+			// The children are reaped below.
+			if sig == syscall.SIGCHLD {
+				continue
+			}
+			// The command may be gone already, its status is collected below.
+			syscall.Kill(pid, sig.(syscall.Signal))
+		}
+	}()
+
+	for {
+		var ws syscall.WaitStatus
+		wpid, err := syscall.Wait4(-1, &ws, 0, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "init: %v\n", err)
+			os.Exit(1)
+		}
+		if wpid == pid {
+			status := exitStatus(ws)
+			writeExitStatus(status)
+			os.Exit(status)
+		}
+	}
+}
+
+// writeExitStatus writes the exit status of the command to the file mounted
+// at containerExitStatusPath, if any.
+func writeExitStatus(status int) {
+	f, err := os.OpenFile(containerExitStatusPath, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(strconv.Itoa(status)); err != nil {
+		fmt.Fprintf(os.Stderr, "init: %v\n", err)
+	}
+}
+
+// exitStatus returns the exit status of a process as reported by a shell,
+// 128 plus the number of the signal which killed it, if any.
+func exitStatus(ws syscall.WaitStatus) int {
+	if ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return ws.ExitStatus()
+}
@@ -10,6 +10,7 @@ import (
 	"syscall"
 
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/mount"
 	"github.com/opencontainers/runc/libcontainer/apparmor"
 	"github.com/opencontainers/runc/libcontainer/configs"
 	"github.com/opencontainers/runc/libcontainer/devices"
@@ -33,6 +34,10 @@ func (d *Driver) createContainer(c *execdriver.Command, hooks execdriver.Hooks)
 		return nil, err
 	}
 
+	if err := d.createUser(container, c); err != nil {
+		return nil, err
+	}
+
 	if err := d.createNetwork(container, c, hooks); err != nil {
 		return nil, err
 	}
@@ -77,9 +82,13 @@ func (d *Driver) createContainer(c *execdriver.Command, hooks execdriver.Hooks)
 		container.AppArmorProfile = c.AppArmorProfile
 	}
 
+	container.Seccomp = c.Seccomp
+	container.Sysctl = c.Sysctls
+
 	if err := execdriver.SetupCgroups(container, c); err != nil {
 		return nil, err
 	}
+	d.setupCgroupHooks(container, c)
 
 	if container.Readonlyfs {
 		for i := range container.Mounts {
@@ -168,6 +177,23 @@ func (d *Driver) createNetwork(container *configs.Config, c *execdriver.Command,
 	return nil
 }
 
+// setupCgroupHooks sets the cgroups of the container which libcontainer does
+// not manage up in a prestart hook.
+func (d *Driver) setupCgroupHooks(container *configs.Config, c *execdriver.Command) {
+	if c.Resources == nil {
+		return
+	}
+	if container.Hooks == nil {
+		container.Hooks = &configs.Hooks{}
+	}
+	container.Hooks.Prestart = append(container.Hooks.Prestart, configs.NewFunctionHook(func(s configs.HookState) error {
+		if err := setBlkioDevices(s.Pid, c.Resources); err != nil {
+			return err
+		}
+		return joinPidsCgroup(s.Pid, c.Resources.PidsLimit)
+	}))
+}
+
 func (d *Driver) createIpc(container *configs.Config, c *execdriver.Command) error {
 	if c.Ipc.HostIpc {
 		container.Namespaces.Remove(configs.NEWIPC)
@@ -212,6 +238,30 @@ func (d *Driver) createUTS(container *configs.Config, c *execdriver.Command) err
 	return nil
 }
 
+func (d *Driver) createUser(container *configs.Config, c *execdriver.Command) error {
+	// Without mappings, the container runs in the user namespace of the daemon.
+	if len(c.UIDMapping) == 0 {
+		return nil
+	}
+
+	container.Namespaces.Add(configs.NEWUSER, "")
+	for _, m := range c.UIDMapping {
+		container.UidMappings = append(container.UidMappings, configs.IDMap{
+			ContainerID: m.ContainerID,
+			HostID:      m.HostID,
+			Size:        m.Size,
+		})
+	}
+	for _, m := range c.GIDMapping {
+		container.GidMappings = append(container.GidMappings, configs.IDMap{
+			ContainerID: m.ContainerID,
+			HostID:      m.HostID,
+			Size:        m.Size,
+		})
+	}
+	return nil
+}
+
 func (d *Driver) setPrivileged(container *configs.Config) (err error) {
 	container.Capabilities = execdriver.GetAllCapabilities()
 	container.Cgroups.AllowAllDevices = true
@@ -267,6 +317,24 @@ func (d *Driver) setupMounts(container *configs.Config, c *execdriver.Command) e
 	container.Mounts = defaultMounts
 
 	for _, m := range c.Mounts {
+		if m.Source == "tmpfs" {
+			options, err := execdriver.TmpfsOptions(m.Data)
+			if err != nil {
+				return err
+			}
+			flags, data, err := mount.ParseTmpfsOptions(options)
+			if err != nil {
+				return err
+			}
+			container.Mounts = append(container.Mounts, &configs.Mount{
+				Source:      m.Source,
+				Destination: m.Destination,
+				Data:        data,
+				Device:      "tmpfs",
+				Flags:       flags,
+			})
+			continue
+		}
 		flags := syscall.MS_BIND | syscall.MS_REC
 		if !m.Writable {
 			flags |= syscall.MS_RDONLY
@@ -281,6 +349,26 @@ func (d *Driver) setupMounts(container *configs.Config, c *execdriver.Command) e
 			Flags:       flags,
 		})
 	}
+
+	if c.Init || canRestore(c) {
+		if d.initPath == "" {
+			return fmt.Errorf("Cannot run an init process in the container, dockerinit could not be located")
+		}
+		container.Mounts = append(container.Mounts, &configs.Mount{
+			Source:      d.initPath,
+			Destination: containerInitPath,
+			Device:      "bind",
+			Flags:       syscall.MS_BIND | syscall.MS_RDONLY,
+		})
+	}
+	if canRestore(c) {
+		container.Mounts = append(container.Mounts, &configs.Mount{
+			Source:      d.exitStatusPath(c.ID),
+			Destination: containerExitStatusPath,
+			Device:      "bind",
+			Flags:       syscall.MS_BIND,
+		})
+	}
 	return nil
 }
 
@@ -144,6 +144,10 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		Cwd:  c.WorkingDir,
 		User: c.ProcessConfig.User,
 	}
+	if c.Init || canRestore(c) {
+		// The init process runs the entrypoint, given as its arguments.
+		p.Args = append([]string{containerInitPath}, p.Args...)
+	}
 
 	if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
 		return execdriver.ExitStatus{ExitCode: -1}, err
@@ -161,9 +165,34 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		d.cleanContainer(c.ID)
 	}()
 
-	if err := cont.Start(p); err != nil {
+	// The output of a container which should survive the daemon goes through
+	// FIFOs, which the daemon can open again after it has been restarted.
+	var stdio *fifoStdio
+	if canRestore(c) {
+		if err := d.createExitStatusFile(c.ID); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+		if stdio, err = d.createFifos(c.ID, p, pipes); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+	}
+
+	err = cont.Start(p)
+	if stdio != nil {
+		stdio.closeProcessEnds()
+		defer stdio.wait()
+	}
+	if err != nil {
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
+	if pid, err := p.Pid(); err == nil {
+		defer removePidsCgroup(pid)()
+		if err := addPidsCgroupPath(cont, pid); err != nil {
+			p.Signal(os.Kill)
+			p.Wait()
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+	}
 
 	oom := notifyOnOOM(cont)
 	if hooks.Start != nil {
@@ -377,6 +406,10 @@ func (d *Driver) Stats(id string) (*execdriver.ResourceStats, error) {
 	if err != nil {
 		return nil, err
 	}
+	var pids uint64
+	if state, err := c.State(); err == nil {
+		pids, _ = currentPids(state.InitProcessPid)
+	}
 	memoryLimit := c.Config().Cgroups.Memory
 	// if the container does not have any memory limit specified set the
 	// limit to the machines memory
@@ -387,9 +420,29 @@ func (d *Driver) Stats(id string) (*execdriver.ResourceStats, error) {
 		Stats:       stats,
 		Read:        now,
 		MemoryLimit: memoryLimit,
+		PidsCurrent: pids,
 	}, nil
 }
 
+// Update implements the exec driver Driver interface,
+// it applies the resources of the command to the live cgroups of the container.
+func (d *Driver) Update(c *execdriver.Command) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+	if active == nil {
+		return execdriver.ErrNotRunning
+	}
+	config := active.Config()
+	if err := execdriver.SetupCgroups(&config, c); err != nil {
+		return err
+	}
+	if err := raiseMemorySwapLimit(active, config.Cgroups); err != nil {
+		return err
+	}
+	return active.Set(config)
+}
+
 // TtyConsole implements the exec driver Terminal interface.
 type TtyConsole struct {
 	console libcontainer.Console
@@ -0,0 +1,257 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+const (
+	stdoutFifo = "stdout"
+	stderrFifo = "stderr"
+
+	// exitStatusFile is the file in the state directory of a container which
+	// can be restored that its init process writes the exit status of its
+	// command to.
+	exitStatusFile = "exitstatus"
+
+	// restorePollInterval is how often a restored container is checked for
+	// exit. Its process is not a child of the daemon anymore, so it cannot
+	// be waited for.
+	restorePollInterval = 100 * time.Millisecond
+)
+
+// canRestore returns whether the container of c can be restored by another
+// daemon. Its output goes through FIFOs, and its command runs under the init
+// process, which records its exit status.
+func canRestore(c *execdriver.Command) bool {
+	return c.LiveRestore && !c.ProcessConfig.Tty
+}
+
+func (d *Driver) exitStatusPath(id string) string {
+	return filepath.Join(d.root, id, exitStatusFile)
+}
+
+// createExitStatusFile creates the empty exit status file of a container,
+// writable by any user the container runs as.
+func (d *Driver) createExitStatusFile(id string) error {
+	path := d.exitStatusPath(id)
+	if err := ioutil.WriteFile(path, nil, 0666); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0666)
+}
+
+// readExitStatus returns the exit status written by the init process of a
+// restored container, or -1 if it did not write one, as when it was killed.
+func (d *Driver) readExitStatus(id string) int {
+	data, err := ioutil.ReadFile(d.exitStatusPath(id))
+	if err != nil {
+		return -1
+	}
+	status, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return -1
+	}
+	return status
+}
+
+// fifoStdio holds the FIFOs used as stdout and stderr of a container that
+// can be restored, and copies their content to the pipes of the container.
+type fifoStdio struct {
+	// processEnds are the ends passed to the container process, they are
+	// opened for reading and writing so that the container never gets
+	// EPIPE while the daemon is down.
+	processEnds []*os.File
+	copiers     sync.WaitGroup
+}
+
+// createFifos creates the FIFOs in the state directory of the container and
+// sets them up as stdout and stderr of p, in place of the pipes set up by
+// setupPipes.
+func (d *Driver) createFifos(id string, p *libcontainer.Process, pipes *execdriver.Pipes) (*fifoStdio, error) {
+	stdio := &fifoStdio{}
+	for _, name := range []string{stdoutFifo, stderrFifo} {
+		if err := syscall.Mkfifo(d.fifoPath(id, name), 0600); err != nil {
+			stdio.closeProcessEnds()
+			return nil, err
+		}
+	}
+	// The process ends have to be opened first, a FIFO without any writer
+	// would give EOF to the copiers straight away.
+	for _, name := range []string{stdoutFifo, stderrFifo} {
+		f, err := openFifo(d.fifoPath(id, name), syscall.O_RDWR)
+		if err != nil {
+			stdio.closeProcessEnds()
+			return nil, err
+		}
+		stdio.processEnds = append(stdio.processEnds, f)
+	}
+	if err := d.attachFifos(id, pipes, stdio); err != nil {
+		stdio.closeProcessEnds()
+		return nil, err
+	}
+	p.Stdout = stdio.processEnds[0]
+	p.Stderr = stdio.processEnds[1]
+	return stdio, nil
+}
+
+// attachFifos opens the FIFOs of the container for reading and copies their
+// content to the pipes until all processes of the container closed them.
+func (d *Driver) attachFifos(id string, pipes *execdriver.Pipes, stdio *fifoStdio) error {
+	var readers []*os.File
+	for _, name := range []string{stdoutFifo, stderrFifo} {
+		f, err := openFifo(d.fifoPath(id, name), syscall.O_RDONLY)
+		if err != nil {
+			for _, r := range readers {
+				r.Close()
+			}
+			return err
+		}
+		readers = append(readers, f)
+	}
+
+	for i, w := range []io.Writer{pipes.Stdout, pipes.Stderr} {
+		r := readers[i]
+		stdio.copiers.Add(1)
+		go func(w io.Writer) {
+			defer stdio.copiers.Done()
+			defer r.Close()
+			if w == nil {
+				return
+			}
+			if _, err := io.Copy(w, r); err != nil {
+				logrus.Debugf("Error copying output of container %s: %v", id, err)
+			}
+		}(w)
+	}
+	return nil
+}
+
+// closeProcessEnds closes the copies of the process ends held by the
+// daemon, once the process has been started or failed to start.
+func (s *fifoStdio) closeProcessEnds() {
+	for _, f := range s.processEnds {
+		f.Close()
+	}
+	s.processEnds = nil
+}
+
+// wait blocks until all the output of the container has been copied.
+func (s *fifoStdio) wait() {
+	s.copiers.Wait()
+}
+
+func (d *Driver) fifoPath(id, name string) string {
+	return filepath.Join(d.root, id, name)
+}
+
+// openFifo opens the FIFO at path without blocking on the other end to be
+// opened, and returns it in blocking mode.
+func openFifo(path string, mode int) (*os.File, error) {
+	fd, err := syscall.Open(path, mode|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.SetNonblock(fd, false); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
+
+// Restore implements the exec driver Driver interface,
+// it reattaches to a container started with LiveRestore set.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	if _, err := os.Stat(d.fifoPath(c.ID, stdoutFifo)); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, execdriver.ErrRestoreNotSupported
+	}
+
+	cont, err := d.factory.Load(c.ID)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	state, err := cont.State()
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	pid := state.InitProcessPid
+	if !processAlive(pid, state.InitProcessStartTime) {
+		// The container exited while the daemon was down, its exit status
+		// is read before the state directory is removed.
+		exitCode := d.readExitStatus(c.ID)
+		if nss := cont.Config().Namespaces; !nss.Contains(configs.NEWPID) {
+			killCgroupProcs(cont)
+		}
+		removePidsCgroup(pid)()
+		cont.Destroy()
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: exitCode}, execdriver.ErrNotRunning
+	}
+	if err := addPidsCgroupPath(cont, pid); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	d.Lock()
+	d.activeContainers[c.ID] = cont
+	d.Unlock()
+	defer func() {
+		cont.Destroy()
+		d.cleanContainer(c.ID)
+	}()
+	defer removePidsCgroup(pid)()
+
+	// Stdin of the container was closed along with the previous daemon.
+	if pipes.Stdin != nil {
+		pipes.Stdin.Close()
+	}
+	stdio := &fifoStdio{}
+	if err := d.attachFifos(c.ID, pipes, stdio); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	defer stdio.wait()
+	c.ProcessConfig.Terminal = &execdriver.StdConsole{}
+
+	oom := notifyOnOOM(cont)
+	if hooks.Start != nil {
+		hooks.Start(&c.ProcessConfig, pid, oom)
+	}
+
+	for processAlive(pid, state.InitProcessStartTime) {
+		time.Sleep(restorePollInterval)
+	}
+	// The exit status of the init process went to the process which adopted
+	// the container, the one of its command is read from the file it wrote,
+	// before the state directory is removed.
+	exitCode := d.readExitStatus(c.ID)
+	if nss := cont.Config().Namespaces; !nss.Contains(configs.NEWPID) {
+		killCgroupProcs(cont)
+	}
+	cont.Destroy()
+	_, oomKill := <-oom
+	return execdriver.ExitStatus{ExitCode: exitCode, OOMKilled: oomKill}, nil
+}
+
+// processAlive returns whether the process pid, started at startTime, is
+// still running.
+func processAlive(pid int, startTime string) bool {
+	currentStartTime, err := system.GetProcessStartTime(pid)
+	if err != nil {
+		return false
+	}
+	return currentStartTime == startTime
+}
@@ -15,10 +15,13 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sync"
 	"syscall"
 	"unsafe"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/mount"
 )
 
@@ -28,7 +31,7 @@ func init() {
 
 // Init returns a new BTRFS driver.
 // An error is returned if BTRFS is not supported.
-func Init(home string, options []string) (graphdriver.Driver, error) {
+func Init(home string, options []string, uidMaps, gidMaps []idtools.IDMap) (graphdriver.Driver, error) {
 	rootdir := path.Dir(home)
 
 	var buf syscall.Statfs_t
@@ -40,7 +43,11 @@ func Init(home string, options []string) (graphdriver.Driver, error) {
 		return nil, graphdriver.ErrPrerequisites
 	}
 
-	if err := os.MkdirAll(home, 0700); err != nil {
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return nil, err
+	}
+	if err := idtools.MkdirAllAs(home, 0700, rootUID, rootGID); err != nil {
 		return nil, err
 	}
 
@@ -49,16 +56,23 @@ func Init(home string, options []string) (graphdriver.Driver, error) {
 	}
 
 	driver := &Driver{
-		home: home,
+		home:    home,
+		uidMaps: uidMaps,
+		gidMaps: gidMaps,
 	}
 
-	return graphdriver.NewNaiveDiffDriver(driver), nil
+	return graphdriver.NewNaiveDiffDriver(driver, uidMaps, gidMaps), nil
 }
 
 // Driver contains information about the filesystem mounted.
 type Driver struct {
 	//root of the file system
-	home string
+	home    string
+	uidMaps []idtools.IDMap
+	gidMaps []idtools.IDMap
+
+	quotaLock    sync.Mutex // Protects quotaEnabled
+	quotaEnabled bool       // Whether the quotas have been enabled on the filesystem
 }
 
 // String prints the name of the driver (btrfs).
@@ -215,6 +229,108 @@ func subvolDelete(dirpath, name string) error {
 	return nil
 }
 
+// subvolEnableQuota enables the quota groups on the filesystem of path, and
+// waits for the usage of the existing subvolumes to be accounted.
+func subvolEnableQuota(path string) error {
+	dir, err := openDir(path)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	var args C.struct_btrfs_ioctl_quota_ctl_args
+	args.cmd = C.BTRFS_QUOTA_CTL_ENABLE
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QUOTA_CTL,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to enable btrfs quota for %s: %v", path, errno.Error())
+	}
+
+	var rescanArgs C.struct_btrfs_ioctl_quota_rescan_args
+	_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QUOTA_RESCAN_WAIT,
+		uintptr(unsafe.Pointer(&rescanArgs)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to rescan btrfs quota for %s: %v", path, errno.Error())
+	}
+	return nil
+}
+
+// subvolLimitQgroup limits the space referenced by the subvolume of path to
+// size bytes.
+func subvolLimitQgroup(path string, size uint64) error {
+	dir, err := openDir(path)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	// A qgroup id of 0 is the qgroup of the subvolume of the directory.
+	var args C.struct_btrfs_ioctl_qgroup_limit_args
+	args.lim.max_referenced = C.__u64(size)
+	args.lim.flags = C.BTRFS_QGROUP_LIMIT_MAX_RFER
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QGROUP_LIMIT,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to limit qgroup for %s: %v", path, errno.Error())
+	}
+	return nil
+}
+
+// subvolLookupQgroup returns the id of the quota group of the subvolume of
+// path, which is the id of its tree.
+func subvolLookupQgroup(path string) (uint64, error) {
+	dir, err := openDir(path)
+	if err != nil {
+		return 0, err
+	}
+	defer closeDir(dir)
+
+	var args C.struct_btrfs_ioctl_ino_lookup_args
+	args.objectid = C.BTRFS_FIRST_FREE_OBJECTID
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_INO_LOOKUP,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return 0, fmt.Errorf("Failed to lookup qgroup for %s: %v", path, errno.Error())
+	}
+	return uint64(args.treeid), nil
+}
+
+// subvolDestroyQgroup destroys the quota group of a deleted subvolume, which
+// the kernel keeps along with its limit. It does nothing if the quotas are
+// not enabled on the filesystem of path.
+func subvolDestroyQgroup(path string, qgroupid uint64) error {
+	dir, err := openDir(path)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	var args C.struct_btrfs_ioctl_qgroup_create_args
+	args.qgroupid = C.__u64(qgroupid)
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QGROUP_CREATE,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 && errno != syscall.ENOTCONN && errno != syscall.ENOENT {
+		return fmt.Errorf("Failed to destroy qgroup %d for %s: %v", qgroupid, path, errno.Error())
+	}
+	return nil
+}
+
+// enableQuota enables the quota groups on the filesystem of the driver the
+// first time a subvolume is created with a size.
+func (d *Driver) enableQuota() error {
+	d.quotaLock.Lock()
+	defer d.quotaLock.Unlock()
+
+	if d.quotaEnabled {
+		return nil
+	}
+	if err := subvolEnableQuota(d.home); err != nil {
+		return err
+	}
+	d.quotaEnabled = true
+	return nil
+}
+
 func (d *Driver) subvolumesDir() string {
 	return path.Join(d.home, "subvolumes")
 }
@@ -223,16 +339,33 @@ func (d *Driver) subvolumesDirID(id string) string {
 	return path.Join(d.subvolumesDir(), id)
 }
 
-// Create the filesystem with given id.
-func (d *Driver) Create(id string, parent string) error {
+// Create the filesystem with given id. The "size" storage option limits the
+// space referenced by the subvolume with a quota group.
+func (d *Driver) Create(id string, parent string, storageOpt map[string]string) error {
+	size, err := graphdriver.ParseStorageOptSize("btrfs", storageOpt)
+	if err != nil {
+		return err
+	}
+
 	subvolumes := path.Join(d.home, "subvolumes")
-	if err := os.MkdirAll(subvolumes, 0700); err != nil {
+	rootUID, rootGID, err := idtools.GetRootUIDGID(d.uidMaps, d.gidMaps)
+	if err != nil {
+		return err
+	}
+	if err := idtools.MkdirAllAs(subvolumes, 0700, rootUID, rootGID); err != nil {
 		return err
 	}
 	if parent == "" {
 		if err := subvolCreate(subvolumes, id); err != nil {
 			return err
 		}
+		// A snapshot keeps the owner of its parent, a new subvolume is
+		// owned by the real root.
+		if rootUID != 0 || rootGID != 0 {
+			if err := os.Chown(path.Join(subvolumes, id), rootUID, rootGID); err != nil {
+				return err
+			}
+		}
 	} else {
 		parentDir, err := d.Get(parent, "")
 		if err != nil {
@@ -242,6 +375,15 @@ func (d *Driver) Create(id string, parent string) error {
 			return err
 		}
 	}
+
+	if size > 0 {
+		if err := d.enableQuota(); err != nil {
+			return err
+		}
+		if err := subvolLimitQgroup(path.Join(subvolumes, id), size); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -251,9 +393,15 @@ func (d *Driver) Remove(id string) error {
 	if _, err := os.Stat(dir); err != nil {
 		return err
 	}
+	qgroupid, qgroupErr := subvolLookupQgroup(dir)
 	if err := subvolDelete(d.subvolumesDir(), id); err != nil {
 		return err
 	}
+	if qgroupErr == nil {
+		if err := subvolDestroyQgroup(d.subvolumesDir(), qgroupid); err != nil {
+			logrus.Warnf("btrfs: %v", err)
+		}
+	}
 	return os.RemoveAll(dir)
 }
 
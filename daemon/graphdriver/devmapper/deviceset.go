@@ -651,7 +651,7 @@ func (devices *DeviceSet) createRegisterDevice(hash string) (*devInfo, error) {
 	return info, nil
 }
 
-func (devices *DeviceSet) createRegisterSnapDevice(hash string, baseInfo *devInfo) error {
+func (devices *DeviceSet) createRegisterSnapDevice(hash string, baseInfo *devInfo, size uint64) error {
 	deviceID, err := devices.getNextFreeDeviceID()
 	if err != nil {
 		return err
@@ -686,7 +686,7 @@ func (devices *DeviceSet) createRegisterSnapDevice(hash string, baseInfo *devInf
 		break
 	}
 
-	if _, err := devices.registerDevice(deviceID, hash, baseInfo.Size, devices.OpenTransactionID); err != nil {
+	if _, err := devices.registerDevice(deviceID, hash, size, devices.OpenTransactionID); err != nil {
 		devicemapper.DeleteDevice(devices.getPoolDevName(), deviceID)
 		devices.markDeviceIDFree(deviceID)
 		logrus.Debugf("Error registering device: %s", err)
@@ -1442,11 +1442,18 @@ func (devices *DeviceSet) initDevmapper(doInit bool) error {
 	return nil
 }
 
-// AddDevice adds a device and registers in the hash.
-func (devices *DeviceSet) AddDevice(hash, baseHash string) error {
+// AddDevice adds a device and registers in the hash. The size of the device
+// is set by the "size" storage option; it defaults to the size of the base
+// device and cannot be smaller.
+func (devices *DeviceSet) AddDevice(hash, baseHash string, storageOpt map[string]string) error {
 	logrus.Debugf("[deviceset] AddDevice(hash=%s basehash=%s)", hash, baseHash)
 	defer logrus.Debugf("[deviceset] AddDevice(hash=%s basehash=%s) END", hash, baseHash)
 
+	size, err := graphdriver.ParseStorageOptSize("devmapper", storageOpt)
+	if err != nil {
+		return err
+	}
+
 	baseInfo, err := devices.lookupDeviceWithLock(baseHash)
 	if err != nil {
 		return err
@@ -1462,10 +1469,74 @@ func (devices *DeviceSet) AddDevice(hash, baseHash string) error {
 		return fmt.Errorf("device %s already exists", hash)
 	}
 
-	if err := devices.createRegisterSnapDevice(hash, baseInfo); err != nil {
+	if size == 0 {
+		size = baseInfo.Size
+	}
+	if size < baseInfo.Size {
+		return fmt.Errorf("devmapper: the device size cannot be smaller than the size of its base device, %s", units.HumanSize(float64(baseInfo.Size)))
+	}
+
+	if err := devices.createRegisterSnapDevice(hash, baseInfo, size); err != nil {
 		return err
 	}
 
+	// The filesystem of the snapshot has the size of the one of the base
+	// device, it is grown to fill the new device.
+	if size > baseInfo.Size {
+		info, err := devices.lookupDevice(hash)
+		if err != nil {
+			return err
+		}
+		if err := devices.growFS(info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// growFS grows the filesystem of the device to the size of the device.
+// Should be called with devices.Lock() held.
+func (devices *DeviceSet) growFS(info *devInfo) error {
+	if err := devices.activateDeviceIfNeeded(info); err != nil {
+		return fmt.Errorf("Error activating devmapper device for '%s': %s", info.Hash, err)
+	}
+	defer devices.deactivateDevice(info)
+
+	fstype, err := ProbeFsType(info.DevName())
+	if err != nil {
+		return err
+	}
+
+	// Both filesystems are grown online.
+	mountPoint, err := ioutil.TempDir(devices.root, "grow-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(mountPoint)
+
+	options := ""
+	if fstype == "xfs" {
+		options = joinMountOptions(options, "nouuid")
+	}
+	options = joinMountOptions(options, devices.mountOptions)
+	if err := syscall.Mount(info.DevName(), mountPoint, fstype, syscall.MS_MGC_VAL, options); err != nil {
+		return fmt.Errorf("Error mounting '%s' on '%s': %s", info.DevName(), mountPoint, err)
+	}
+	defer syscall.Unmount(mountPoint, syscall.MNT_DETACH)
+
+	var out []byte
+	switch fstype {
+	case "xfs":
+		out, err = exec.Command("xfs_growfs", mountPoint).CombinedOutput()
+	case "ext4":
+		out, err = exec.Command("resize2fs", info.DevName()).CombinedOutput()
+	default:
+		return fmt.Errorf("Unsupported filesystem type %s", fstype)
+	}
+	if err != nil {
+		return fmt.Errorf("Error growing the filesystem of '%s': %s: %s", info.DevName(), err, out)
+	}
 	return nil
 }
 
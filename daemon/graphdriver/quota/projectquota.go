@@ -0,0 +1,243 @@
+// +build linux
+
+// Package quota limits the size of directories with the project quotas of
+// xfs, for the storage drivers which keep their layers in directories.
+//
+// Each directory is assigned a project id of its own, which its files and
+// sub-directories inherit, and the blocks of the project are limited. The
+// project ids are allocated above the one of the base directory, which is
+// usually 0, and are recovered from the existing directories on start.
+package quota
+
+/*
+#include <linux/fs.h>
+#include <linux/quota.h>
+#include <linux/dqblk_xfs.h>
+
+#ifndef FS_IOC_FSGETXATTR
+#define FS_IOC_FSGETXATTR		_IOR ('X', 31, struct fsxattr)
+#endif
+#ifndef FS_IOC_FSSETXATTR
+#define FS_IOC_FSSETXATTR		_IOW ('X', 32, struct fsxattr)
+#endif
+#ifndef FS_XFLAG_PROJINHERIT
+#define FS_XFLAG_PROJINHERIT	0x00000200
+#endif
+#ifndef PRJQUOTA
+#define PRJQUOTA	2
+#endif
+#ifndef XFS_PROJ_QUOTA
+#define XFS_PROJ_QUOTA	2
+#endif
+#ifndef Q_XSETPQLIM
+#define Q_XSETPQLIM QCMD(Q_XSETQLIM, PRJQUOTA)
+#endif
+*/
+import "C"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Control sets the project quotas of the directories under a base
+// directory.
+type Control struct {
+	sync.Mutex        // Protects nextProjectID and quotas
+	backingFsBlockDev string
+	nextProjectID     uint32
+	quotas            map[string]uint32
+}
+
+// NewControl returns a Control for the directories under basePath, or an
+// error if the filesystem of basePath does not support the project quotas,
+// for example if it is not xfs mounted with the pquota option.
+func NewControl(basePath string) (*Control, error) {
+	// The project ids of the directories are above the one of the base
+	// directory.
+	minProjectID, err := getProjectID(basePath)
+	if err != nil {
+		return nil, err
+	}
+	minProjectID++
+
+	backingFsBlockDev, err := makeBackingFsDev(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setting a quota, which is unlimited, fails if the filesystem does
+	// not support the project quotas.
+	if err := setProjectQuota(backingFsBlockDev, minProjectID, 0); err != nil {
+		return nil, err
+	}
+
+	q := &Control{
+		backingFsBlockDev: backingFsBlockDev,
+		nextProjectID:     minProjectID + 1,
+		quotas:            make(map[string]uint32),
+	}
+	if err := q.findNextProjectID(basePath); err != nil {
+		return nil, err
+	}
+
+	logrus.Debugf("NewControl(%s): nextProjectID = %d", basePath, q.nextProjectID)
+	return q, nil
+}
+
+// SetQuota limits the blocks of targetPath, a directory under the base
+// directory, to size bytes. The limit applies to the files and directories
+// created in targetPath afterwards.
+func (q *Control) SetQuota(targetPath string, size uint64) error {
+	q.Lock()
+	defer q.Unlock()
+
+	projectID, ok := q.quotas[targetPath]
+	if !ok {
+		projectID = q.nextProjectID
+		if err := setProjectID(targetPath, projectID); err != nil {
+			return err
+		}
+		q.quotas[targetPath] = projectID
+		q.nextProjectID++
+	}
+
+	logrus.Debugf("SetQuota(%s, %d): projectID = %d", targetPath, size, projectID)
+	return setProjectQuota(q.backingFsBlockDev, projectID, size)
+}
+
+// RemoveQuota removes the limit of targetPath, once it has been removed, and
+// forgets its project id.
+func (q *Control) RemoveQuota(targetPath string) error {
+	q.Lock()
+	defer q.Unlock()
+
+	projectID, ok := q.quotas[targetPath]
+	if !ok {
+		return nil
+	}
+	delete(q.quotas, targetPath)
+
+	logrus.Debugf("RemoveQuota(%s): projectID = %d", targetPath, projectID)
+	return setProjectQuota(q.backingFsBlockDev, projectID, 0)
+}
+
+// setProjectQuota sets the hard and soft block limits of the project to size
+// bytes; 0 removes the limits.
+func setProjectQuota(backingFsBlockDev string, projectID uint32, size uint64) error {
+	var d C.fs_disk_quota_t
+	d.d_version = C.FS_DQUOT_VERSION
+	d.d_id = C.__u32(projectID)
+	d.d_flags = C.XFS_PROJ_QUOTA
+
+	// The limits are in basic blocks of 512 bytes.
+	d.d_fieldmask = C.FS_DQ_BHARD | C.FS_DQ_BSOFT
+	d.d_blk_hardlimit = C.__u64(size / 512)
+	d.d_blk_softlimit = d.d_blk_hardlimit
+
+	dev, err := syscall.BytePtrFromString(backingFsBlockDev)
+	if err != nil {
+		return err
+	}
+	_, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, C.Q_XSETPQLIM,
+		uintptr(unsafe.Pointer(dev)), uintptr(d.d_id),
+		uintptr(unsafe.Pointer(&d)), 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("Failed to set quota limit for projid %d on %s: %v",
+			projectID, backingFsBlockDev, errno.Error())
+	}
+	return nil
+}
+
+// getProjectID returns the project id of the directory.
+func getProjectID(targetPath string) (uint32, error) {
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return 0, err
+	}
+	defer dir.Close()
+
+	var fsx C.struct_fsxattr
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSGETXATTR,
+		uintptr(unsafe.Pointer(&fsx)))
+	if errno != 0 {
+		return 0, fmt.Errorf("Failed to get projid for %s: %v", targetPath, errno.Error())
+	}
+	return uint32(fsx.fsx_projid), nil
+}
+
+// setProjectID sets the project id of the directory, which the files and
+// directories created in it inherit.
+func setProjectID(targetPath string, projectID uint32) error {
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	var fsx C.struct_fsxattr
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSGETXATTR,
+		uintptr(unsafe.Pointer(&fsx)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to get projid for %s: %v", targetPath, errno.Error())
+	}
+	fsx.fsx_projid = C.__u32(projectID)
+	fsx.fsx_xflags |= C.FS_XFLAG_PROJINHERIT
+	_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSSETXATTR,
+		uintptr(unsafe.Pointer(&fsx)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to set projid for %s: %v", targetPath, errno.Error())
+	}
+	return nil
+}
+
+// findNextProjectID records the project ids of the existing directories, and
+// allocates the next ones above them.
+func (q *Control) findNextProjectID(home string) error {
+	files, err := ioutil.ReadDir(home)
+	if err != nil {
+		return fmt.Errorf("read directory failed: %s", home)
+	}
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		path := filepath.Join(home, file.Name())
+		projectID, err := getProjectID(path)
+		if err != nil {
+			return err
+		}
+		if projectID > 0 {
+			q.quotas[path] = projectID
+		}
+		if q.nextProjectID <= projectID {
+			q.nextProjectID = projectID + 1
+		}
+	}
+	return nil
+}
+
+// makeBackingFsDev creates a node of the block device of the filesystem of
+// home in home, which the quotactl calls refer to.
+func makeBackingFsDev(home string) (string, error) {
+	fileinfo, err := os.Stat(home)
+	if err != nil {
+		return "", err
+	}
+
+	backingFsBlockDev := filepath.Join(home, "backingFsBlockDev")
+	// The node is created again in case home was moved to another device.
+	syscall.Unlink(backingFsBlockDev)
+	stat := fileinfo.Sys().(*syscall.Stat_t)
+	if err := syscall.Mknod(backingFsBlockDev, syscall.S_IFBLK|0600, int(stat.Dev)); err != nil {
+		return "", fmt.Errorf("Failed to mknod %s: %v", backingFsBlockDev, err)
+	}
+	return backingFsBlockDev, nil
+}
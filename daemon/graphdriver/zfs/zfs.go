@@ -15,6 +15,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/parsers"
 	zfs "github.com/mistifyio/go-zfs"
@@ -41,7 +42,7 @@ func (*Logger) Log(cmd []string) {
 // Init returns a new ZFS driver.
 // It takes base mount path and a array of options which are represented as key value pairs.
 // Each option is in the for key=value. 'zfs.fsname' is expected to be a valid key in the options.
-func Init(base string, opt []string) (graphdriver.Driver, error) {
+func Init(base string, opt []string, uidMaps, gidMaps []idtools.IDMap) (graphdriver.Driver, error) {
 	var err error
 
 	if _, err := exec.LookPath("zfs"); err != nil {
@@ -102,8 +103,10 @@ func Init(base string, opt []string) (graphdriver.Driver, error) {
 		dataset:          rootDataset,
 		options:          options,
 		filesystemsCache: filesystemsCache,
+		uidMaps:          uidMaps,
+		gidMaps:          gidMaps,
 	}
-	return graphdriver.NewNaiveDiffDriver(d), nil
+	return graphdriver.NewNaiveDiffDriver(d, uidMaps, gidMaps), nil
 }
 
 func parseOptions(opt []string) (zfsOptions, error) {
@@ -156,6 +159,8 @@ type Driver struct {
 	options          zfsOptions
 	sync.Mutex       // protects filesystem cache against concurrent access
 	filesystemsCache map[string]bool
+	uidMaps          []idtools.IDMap
+	gidMaps          []idtools.IDMap
 }
 
 func (d *Driver) String() string {
@@ -236,8 +241,14 @@ func (d *Driver) mountPath(id string) string {
 }
 
 // Create prepares the dataset and filesystem for the ZFS driver for the given id under the parent.
-func (d *Driver) Create(id string, parent string) error {
-	err := d.create(id, parent)
+// The "size" storage option sets the quota of the dataset.
+func (d *Driver) Create(id string, parent string, storageOpt map[string]string) error {
+	quota, err := graphdriver.ParseStorageOptSize("zfs", storageOpt)
+	if err != nil {
+		return err
+	}
+
+	err = d.create(id, parent, quota)
 	if err == nil {
 		return nil
 	}
@@ -256,22 +267,36 @@ func (d *Driver) Create(id string, parent string) error {
 	}
 
 	// retry
-	return d.create(id, parent)
+	return d.create(id, parent, quota)
 }
 
-func (d *Driver) create(id, parent string) error {
+func (d *Driver) create(id, parent string, quota uint64) error {
 	name := d.zfsPath(id)
 	if parent == "" {
 		mountoptions := map[string]string{"mountpoint": "legacy"}
 		fs, err := zfs.CreateFilesystem(name, mountoptions)
-		if err == nil {
-			d.Lock()
-			d.filesystemsCache[fs.Name] = true
-			d.Unlock()
+		if err != nil {
+			return err
 		}
+		d.Lock()
+		d.filesystemsCache[fs.Name] = true
+		d.Unlock()
+	} else if err := d.cloneFilesystem(name, d.zfsPath(parent)); err != nil {
+		return err
+	}
+	return setQuota(name, quota)
+}
+
+// setQuota limits the space used by the dataset to quota bytes, unless it is 0.
+func setQuota(name string, quota uint64) error {
+	if quota == 0 {
+		return nil
+	}
+	fs, err := zfs.GetDataset(name)
+	if err != nil {
 		return err
 	}
-	return d.cloneFilesystem(name, d.zfsPath(parent))
+	return fs.SetProperty("quota", strconv.FormatUint(quota, 10))
 }
 
 // Remove deletes the dataset, filesystem and the cache for the given id.
@@ -294,15 +319,27 @@ func (d *Driver) Get(id, mountLabel string) (string, error) {
 	options := label.FormatMountLabel("", mountLabel)
 	logrus.Debugf(`[zfs] mount("%s", "%s", "%s")`, filesystem, mountpoint, options)
 
+	rootUID, rootGID, err := idtools.GetRootUIDGID(d.uidMaps, d.gidMaps)
+	if err != nil {
+		return "", err
+	}
 	// Create the target directories if they don't exist
-	if err := os.MkdirAll(mountpoint, 0755); err != nil {
+	if err := idtools.MkdirAllAs(mountpoint, 0755, rootUID, rootGID); err != nil {
 		return "", err
 	}
 
-	err := mount.Mount(filesystem, mountpoint, "zfs", options)
+	err = mount.Mount(filesystem, mountpoint, "zfs", options)
 	if err != nil {
 		return "", fmt.Errorf("error creating zfs mount of %s to %s: %v", filesystem, mountpoint, err)
 	}
+	// A new filesystem is owned by the real root, hand it to the remapped
+	// root when user namespaces are in use.
+	if rootUID != 0 || rootGID != 0 {
+		if err := os.Chown(mountpoint, rootUID, rootGID); err != nil {
+			mount.Unmount(mountpoint)
+			return "", fmt.Errorf("error changing the ownership of %s: %v", mountpoint, err)
+		}
+	}
 
 	return mountpoint, nil
 }
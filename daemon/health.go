@@ -0,0 +1,420 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/runconfig"
+)
+
+const (
+	// Longest healthcheck probe output message to store. Longer messages will be truncated.
+	maxOutputLen = 4096
+
+	// Default interval between probe runs (from the end of the first to the start of the second).
+	// Also the time before the first probe.
+	defaultProbeInterval = 30 * time.Second
+
+	// The maximum length of time a single probe run should take. If the probe takes longer
+	// than this, the check is considered to have failed.
+	defaultProbeTimeout = 30 * time.Second
+
+	// Default number of consecutive failures of the health check
+	// for the container to be considered unhealthy.
+	defaultProbeRetries = 3
+
+	// Maximum number of entries to record
+	maxLogEntries = 5
+)
+
+const (
+	// Exit status codes that can be returned by the probe command.
+
+	exitStatusHealthy   = 0 // Container is healthy
+	exitStatusUnhealthy = 1 // Container is unhealthy
+)
+
+// Health holds the current container health-check state
+type Health struct {
+	types.Health
+	stop chan struct{} // Closed to stop the monitor
+}
+
+// String returns a human-readable description of the health-check state
+func (s *Health) String() string {
+	if s.Status == types.Starting {
+		return "health: starting"
+	}
+	return s.Status
+}
+
+// openMonitorChannel creates and returns a new monitor channel. If there already is one,
+// it returns nil.
+func (s *Health) openMonitorChannel() chan struct{} {
+	if s.stop != nil {
+		logrus.Debugf("openMonitorChannel: monitor already open")
+		return nil
+	}
+	logrus.Debugf("openMonitorChannel")
+	s.stop = make(chan struct{})
+	return s.stop
+}
+
+// closeMonitorChannel closes any existing monitor channel.
+func (s *Health) closeMonitorChannel() {
+	if s.stop != nil {
+		logrus.Debugf("closeMonitorChannel: stopping probe")
+		// Closing rather than sending on the channel means we never block
+		// here, even while the monitor is waiting for the container lock.
+		// The monitor checks the channel again once it holds the lock, so
+		// it will not make any further updates to c.State.Health.
+		close(s.stop)
+		s.stop = nil
+		logrus.Debugf("closeMonitorChannel done")
+	}
+}
+
+// probe is the interface implemented by the different kinds of healthcheck
+// tests a container can define.
+type probe interface {
+	// Perform one run of the check. Returns the exit code and an optional
+	// short diagnostic string. The probe must give up once timeout is
+	// reached.
+	run(d *Daemon, container *Container, timeout time.Duration) (*types.HealthcheckResult, error)
+}
+
+// cmdProbe implements the "CMD" and "CMD-SHELL" tests by running the
+// command inside the container using the exec machinery.
+type cmdProbe struct {
+	// Run the command with the system's default shell instead of execing it directly.
+	shell bool
+}
+
+// exec the healthcheck command in the container.
+// Returns the exit code and probe output (if any)
+func (p *cmdProbe) run(d *Daemon, container *Container, timeout time.Duration) (*types.HealthcheckResult, error) {
+	cmdSlice := container.Config.Healthcheck.Test[1:]
+	if p.shell {
+		cmdSlice = append(getShell(), cmdSlice...)
+	}
+
+	execID, err := d.ContainerExecCreate(&runconfig.ExecConfig{
+		Container:    container.ID,
+		User:         container.Config.User,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmdSlice,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &limitedBuffer{}
+	execErr := make(chan error, 1)
+	go func() {
+		execErr <- d.ContainerExecStart(execID, nil, output, output)
+	}()
+
+	select {
+	case err := <-execErr:
+		if err != nil {
+			return nil, err
+		}
+	case <-time.After(timeout):
+		// Kill the probe, but still wait for it to exit so that dying
+		// probes don't pile up.
+		d.killExecProcess(execID)
+		<-execErr
+		return &types.HealthcheckResult{
+			ExitCode: -1,
+			Output:   fmt.Sprintf("Health check exceeded timeout (%v)", timeout),
+			End:      time.Now(),
+		}, nil
+	}
+
+	execConfig := d.execCommands.Get(execID)
+	if execConfig == nil {
+		return nil, fmt.Errorf("Exec %s for healthcheck of container %s disappeared", execID, container.ID)
+	}
+	return &types.HealthcheckResult{
+		ExitCode: execConfig.ExitCode,
+		Output:   output.String(),
+		End:      time.Now(),
+	}, nil
+}
+
+// killExecProcess sends SIGKILL to the process of the given exec, if it is
+// still running.
+func (d *Daemon) killExecProcess(execID string) {
+	execConfig := d.execCommands.Get(execID)
+	if execConfig == nil {
+		return
+	}
+	execConfig.Lock()
+	pid := execConfig.pid
+	execConfig.Unlock()
+	if pid == 0 {
+		return
+	}
+	if p, err := os.FindProcess(pid); err == nil {
+		if err := p.Kill(); err != nil {
+			logrus.Debugf("Failed to kill exec %s (pid %d): %v", execID, pid, err)
+		}
+	}
+}
+
+// Update the container's Status.Health struct based on the latest probe's result.
+func handleProbeResult(d *Daemon, c *Container, result *types.HealthcheckResult, stop chan struct{}) {
+	c.Lock()
+	defer c.Unlock()
+
+	// The monitor may have been stopped while we were waiting for the lock.
+	select {
+	case <-stop:
+		return
+	default:
+	}
+
+	retries := c.Config.Healthcheck.Retries
+	if retries <= 0 {
+		retries = defaultProbeRetries
+	}
+
+	h := c.State.Health
+	oldStatus := h.Status
+
+	if len(h.Log) >= maxLogEntries {
+		h.Log = append(h.Log[len(h.Log)+1-maxLogEntries:], result)
+	} else {
+		h.Log = append(h.Log, result)
+	}
+
+	if result.ExitCode == exitStatusHealthy {
+		h.FailingStreak = 0
+		h.Status = types.Healthy
+	} else {
+		// Failure (including invalid exit code)
+		h.FailingStreak++
+		if h.FailingStreak >= retries {
+			h.Status = types.Unhealthy
+		}
+		// Else we're starting or healthy. Stay in that state.
+	}
+
+	if err := c.toDisk(); err != nil {
+		logrus.Errorf("Error saving container %s health state to disk: %v", c.ID, err)
+	}
+
+	if oldStatus != h.Status {
+		c.logEvent("health_status: " + h.Status)
+	}
+}
+
+// Run the container's monitoring thread until notified via "stop".
+// There is never more than one monitor thread running per container at a time.
+func monitor(d *Daemon, c *Container, stop chan struct{}, probe probe) {
+	probeTimeout := timeoutWithDefault(c.Config.Healthcheck.Timeout, defaultProbeTimeout)
+	probeInterval := timeoutWithDefault(c.Config.Healthcheck.Interval, defaultProbeInterval)
+	for {
+		select {
+		case <-stop:
+			logrus.Debugf("Stop healthcheck monitoring for container %s (received while idle)", c.ID)
+			return
+		case <-time.After(probeInterval):
+			logrus.Debugf("Running health check for container %s ...", c.ID)
+			startTime := time.Now()
+			results := make(chan *types.HealthcheckResult, 1)
+			go func() {
+				result, err := probe.run(d, c, probeTimeout)
+				if err != nil {
+					logrus.Warnf("Health check for container %s error: %v", c.ID, err)
+					result = &types.HealthcheckResult{
+						ExitCode: -1,
+						Output:   err.Error(),
+						End:      time.Now(),
+					}
+				} else {
+					logrus.Debugf("Health check for container %s done (exitCode=%d)", c.ID, result.ExitCode)
+				}
+				result.Start = startTime
+				results <- result
+			}()
+			select {
+			case <-stop:
+				logrus.Debugf("Stop healthcheck monitoring for container %s (received while probing)", c.ID)
+				// The probe will be killed along with the container (or time
+				// out on its own); don't wait for it to exit.
+				return
+			case result := <-results:
+				handleProbeResult(d, c, result, stop)
+			}
+		}
+	}
+}
+
+// Get a suitable probe implementation for the container's healthcheck configuration.
+// Nil will be returned if no healthcheck was configured or NONE was set.
+func getProbe(c *Container) probe {
+	config := c.Config.Healthcheck
+	if config == nil || len(config.Test) == 0 {
+		return nil
+	}
+	switch config.Test[0] {
+	case "CMD":
+		return &cmdProbe{shell: false}
+	case "CMD-SHELL":
+		return &cmdProbe{shell: true}
+	default:
+		logrus.Warnf("Unknown healthcheck type '%s' (expected 'CMD') in container %s", config.Test[0], c.ID)
+		return nil
+	}
+}
+
+// Ensure the health-check monitor is running or not, depending on the current
+// state of the container.
+// Called from monitor.go, with c locked.
+func (d *Daemon) updateHealthMonitor(c *Container) {
+	h := c.State.Health
+	if h == nil {
+		return // No healthcheck configured
+	}
+
+	probe := getProbe(c)
+	wantRunning := c.Running && !c.Paused && probe != nil
+	if wantRunning {
+		if stop := h.openMonitorChannel(); stop != nil {
+			go monitor(d, c, stop, probe)
+		}
+	} else {
+		h.closeMonitorChannel()
+	}
+}
+
+// Reset the health state for a newly-started, restarted or restored container.
+// initHealthMonitor is called from monitor.go and we should never be running
+// two instances at once.
+// Called with c locked.
+func (d *Daemon) initHealthMonitor(c *Container) {
+	// If no healthcheck is setup then don't init the monitor
+	if getProbe(c) == nil {
+		c.State.Health = nil
+		return
+	}
+
+	// This is needed in case we're auto-restarting
+	d.stopHealthchecks(c)
+
+	if h := c.State.Health; h != nil {
+		h.Status = types.Starting
+		h.FailingStreak = 0
+	} else {
+		h := &Health{}
+		h.Status = types.Starting
+		c.State.Health = h
+	}
+
+	d.updateHealthMonitor(c)
+}
+
+// Called when the container is being stopped (whether because the health check is
+// failing or for any other reason).
+func (d *Daemon) stopHealthchecks(c *Container) {
+	h := c.State.Health
+	if h != nil {
+		h.closeMonitorChannel()
+	}
+}
+
+// Buffer up to maxOutputLen bytes. Further data is discarded.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	mu        sync.Mutex
+	truncated bool // indicates that data has been lost
+}
+
+// Append to limitedBuffer while there is room.
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bufLen := b.buf.Len()
+	dataLen := len(data)
+	keep := min(maxOutputLen-bufLen, dataLen)
+	if keep > 0 {
+		b.buf.Write(data[:keep])
+	}
+	if keep < dataLen {
+		b.truncated = true
+	}
+	return dataLen, nil
+}
+
+// The contents of the buffer, with "..." appended if it overflowed.
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := b.buf.String()
+	if b.truncated {
+		out = out + "..."
+	}
+	return out
+}
+
+// If configuredValue is zero, use defaultValue instead.
+func timeoutWithDefault(configuredValue time.Duration, defaultValue time.Duration) time.Duration {
+	if configuredValue == 0 {
+		return defaultValue
+	}
+	return configuredValue
+}
+
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+// getShell returns the command used to run a CMD-SHELL healthcheck.
+func getShell() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/S", "/C"}
+	}
+	return []string{"/bin/sh", "-c"}
+}
+
+// validateHealthcheck checks the healthcheck settings of a container config.
+func validateHealthcheck(config *runconfig.HealthConfig) error {
+	if config == nil {
+		return nil
+	}
+	if len(config.Test) > 0 {
+		switch config.Test[0] {
+		case "NONE":
+		case "CMD", "CMD-SHELL":
+			if len(config.Test) < 2 || strings.TrimSpace(strings.Join(config.Test[1:], "")) == "" {
+				return fmt.Errorf("Healthcheck %s requires a command", config.Test[0])
+			}
+		default:
+			return fmt.Errorf("Unknown healthcheck type %q (expected NONE, CMD or CMD-SHELL)", config.Test[0])
+		}
+	}
+	if config.Interval < 0 {
+		return fmt.Errorf("Healthcheck interval cannot be negative")
+	}
+	if config.Timeout < 0 {
+		return fmt.Errorf("Healthcheck timeout cannot be negative")
+	}
+	if config.Retries < 0 {
+		return fmt.Errorf("Healthcheck retries cannot be negative")
+	}
+	return nil
+}
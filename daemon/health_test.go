@@ -0,0 +1,163 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/runconfig"
+)
+
+func reset(c *Container) {
+	c.State = &State{}
+	c.State.Health = &Health{}
+	c.State.Health.Status = types.Starting
+}
+
+func TestHealthStates(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-health-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	e := events.New()
+	_, l := e.Subscribe()
+	defer e.Evict(l)
+
+	expect := func(expected string) {
+		select {
+		case event := <-l:
+			ev := event.(*eventtypes.Message)
+			if ev.Status != expected {
+				t.Errorf("Expecting event %#v, but got %#v\n", expected, ev.Status)
+			}
+		case <-time.After(1 * time.Second):
+			t.Errorf("Expecting event %#v, but got nothing\n", expected)
+		}
+	}
+
+	c := &Container{
+		CommonContainer: CommonContainer{
+			root: root,
+			ID:   "container_id",
+			Config: &runconfig.Config{
+				Image: "image_name",
+				Healthcheck: &runconfig.HealthConfig{
+					Test:    []string{"CMD", "true"},
+					Retries: 1,
+				},
+			},
+		},
+	}
+	daemon := &Daemon{
+		EventsService: e,
+	}
+	c.daemon = daemon
+	stop := make(chan struct{})
+
+	reset(c)
+
+	handleResult := func(startTime time.Time, exitCode int) {
+		handleProbeResult(daemon, c, &types.HealthcheckResult{
+			Start:    startTime,
+			End:      startTime,
+			ExitCode: exitCode,
+		}, stop)
+	}
+
+	// starting -> failed -> success -> failed
+
+	handleResult(c.State.StartedAt.Add(1*time.Second), 1)
+	expect("health_status: unhealthy")
+
+	handleResult(c.State.StartedAt.Add(2*time.Second), 0)
+	expect("health_status: healthy")
+
+	handleResult(c.State.StartedAt.Add(3*time.Second), 1)
+	expect("health_status: unhealthy")
+
+	// Test retries
+
+	reset(c)
+	c.Config.Healthcheck.Retries = 3
+
+	handleResult(c.State.StartedAt.Add(20*time.Second), 1)
+	handleResult(c.State.StartedAt.Add(40*time.Second), 1)
+	if c.State.Health.Status != types.Starting {
+		t.Errorf("Expecting starting, but got %#v\n", c.State.Health.Status)
+	}
+	if c.State.Health.FailingStreak != 2 {
+		t.Errorf("Expecting FailingStreak=2, but got %d\n", c.State.Health.FailingStreak)
+	}
+	handleResult(c.State.StartedAt.Add(60*time.Second), 1)
+	expect("health_status: unhealthy")
+
+	handleResult(c.State.StartedAt.Add(80*time.Second), 0)
+	expect("health_status: healthy")
+	if c.State.Health.FailingStreak != 0 {
+		t.Errorf("Expecting FailingStreak=0, but got %d\n", c.State.Health.FailingStreak)
+	}
+
+	// The log only keeps the most recent results
+	for i := 0; i < 2*maxLogEntries; i++ {
+		handleResult(c.State.StartedAt.Add(time.Duration(100+i)*time.Second), 0)
+	}
+	if len(c.State.Health.Log) != maxLogEntries {
+		t.Errorf("Expecting %d log entries, but got %d\n", maxLogEntries, len(c.State.Health.Log))
+	}
+
+	// Results arriving after the monitor was stopped are ignored
+	close(stop)
+	handleResult(c.State.StartedAt.Add(200*time.Second), 1)
+	if c.State.Health.FailingStreak != 0 {
+		t.Errorf("Expecting result to be ignored after stop, but got FailingStreak=%d\n", c.State.Health.FailingStreak)
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{}
+	b.Write([]byte("hello"))
+	if b.String() != "hello" {
+		t.Fatalf("Expected hello, got %q", b.String())
+	}
+
+	b.Write([]byte(strings.Repeat("x", maxOutputLen)))
+	out := b.String()
+	if len(out) != maxOutputLen+len("...") || !strings.HasSuffix(out, "...") {
+		t.Fatalf("Expected output to be truncated to %d bytes, got %d", maxOutputLen, len(out))
+	}
+}
+
+func TestValidateHealthcheck(t *testing.T) {
+	valid := []*runconfig.HealthConfig{
+		nil,
+		{},
+		{Test: []string{"NONE"}},
+		{Test: []string{"CMD", "true"}, Interval: time.Second, Timeout: time.Second, Retries: 1},
+		{Test: []string{"CMD-SHELL", "exit 0"}},
+	}
+	invalid := []*runconfig.HealthConfig{
+		{Test: []string{"CMD"}},
+		{Test: []string{"CMD-SHELL", " "}},
+		{Test: []string{"FOO", "true"}},
+		{Interval: -time.Second},
+		{Timeout: -time.Second},
+		{Retries: -1},
+	}
+	for _, config := range valid {
+		if err := validateHealthcheck(config); err != nil {
+			t.Fatalf("Expected %#v to be valid, got %v", config, err)
+		}
+	}
+	for _, config := range invalid {
+		if err := validateHealthcheck(config); err == nil {
+			t.Fatalf("Expected %#v to be invalid", config)
+		}
+	}
+}
@@ -62,6 +62,7 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 	}
 
 	sysInfo := sysinfo.New(false)
+	labels, clusterStore := daemon.getReloadableConfig()
 
 	v := &types.Info{
 		ID:                 daemon.ID,
@@ -77,22 +78,23 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		NGoroutines:        runtime.NumGoroutine(),
 		SystemTime:         time.Now().Format(time.RFC3339Nano),
 		ExecutionDriver:    daemon.ExecutionDriver().Name(),
-		LoggingDriver:      daemon.defaultLogConfig.Type,
+		LoggingDriver:      daemon.getDefaultLogConfig().Type,
 		NEventsListener:    daemon.EventsService.SubscribersCount(),
 		KernelVersion:      kernelVersion,
 		OperatingSystem:    operatingSystem,
 		IndexServerAddress: registry.IndexServer,
-		RegistryConfig:     daemon.RegistryService.Config,
+		RegistryConfig:     daemon.RegistryService.ServiceConfig(),
 		InitSha1:           dockerversion.INITSHA1,
 		InitPath:           initPath,
 		NCPU:               runtime.NumCPU(),
 		MemTotal:           meminfo.MemTotal,
 		DockerRootDir:      daemon.config().Root,
-		Labels:             daemon.config().Labels,
+		Labels:             labels,
 		ExperimentalBuild:  utils.ExperimentalBuild(),
 		ServerVersion:      dockerversion.VERSION,
-		ClusterStore:       daemon.config().ClusterStore,
+		ClusterStore:       clusterStore,
 	}
+	v.UIDMaps, v.GIDMaps = daemon.GetUIDGIDMaps()
 
 	// TODO Windows. Refactor this more once sysinfo is refactored into
 	// platform specific code. On Windows, sysinfo.cgroupMemInfo and
@@ -102,6 +104,7 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		v.MemoryLimit = sysInfo.MemoryLimit
 		v.SwapLimit = sysInfo.SwapLimit
 		v.OomKillDisable = sysInfo.OomKillDisable
+		v.PidsLimit = sysInfo.PidsLimit
 		v.CPUCfsPeriod = sysInfo.CPUCfsPeriod
 		v.CPUCfsQuota = sysInfo.CPUCfsQuota
 	}
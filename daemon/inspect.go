@@ -65,12 +65,13 @@ func (daemon *Daemon) getInspectData(container *Container) (*types.ContainerJSON
 	}
 	// we need this trick to preserve empty log driver, so
 	// container will use daemon defaults even if daemon change them
+	defaultLogConfig := daemon.getDefaultLogConfig()
 	if hostConfig.LogConfig.Type == "" {
-		hostConfig.LogConfig.Type = daemon.defaultLogConfig.Type
+		hostConfig.LogConfig.Type = defaultLogConfig.Type
 	}
 
 	if len(hostConfig.LogConfig.Config) == 0 {
-		hostConfig.LogConfig.Config = daemon.defaultLogConfig.Config
+		hostConfig.LogConfig.Config = defaultLogConfig.Config
 	}
 
 	containerState := &types.ContainerState{
@@ -87,6 +88,14 @@ func (daemon *Daemon) getInspectData(container *Container) (*types.ContainerJSON
 		FinishedAt: container.State.FinishedAt.Format(time.RFC3339Nano),
 	}
 
+	if h := container.State.Health; h != nil {
+		containerState.Health = &types.Health{
+			Status:        h.Status,
+			FailingStreak: h.FailingStreak,
+			Log:           append([]*types.HealthcheckResult{}, h.Log...),
+		}
+	}
+
 	contJSONBase := &types.ContainerJSONBase{
 		ID:              container.ID,
 		Created:         container.Created.Format(time.RFC3339Nano),
@@ -109,6 +118,8 @@ func (daemon *Daemon) getInspectData(container *Container) (*types.ContainerJSON
 	// Now set any platform-specific fields
 	contJSONBase = setPlatformSpecificContainerFields(container, contJSONBase)
 
+	contJSONBase.LogMessagesDropped = container.logMessagesDropped()
+
 	contJSONBase.GraphDriver.Name = container.Driver
 	graphDriverData, err := daemon.driver.GetMetadata(container.ID)
 	if err != nil {
@@ -136,5 +147,5 @@ func (daemon *Daemon) VolumeInspect(name string) (*types.Volume, error) {
 	if err != nil {
 		return nil, err
 	}
-	return volumeToAPIType(v), nil
+	return daemon.volumeToAPIType(v), nil
 }
@@ -10,6 +10,7 @@ import (
 // This sets platform-specific fields
 func setPlatformSpecificContainerFields(container *Container, contJSONBase *types.ContainerJSONBase) *types.ContainerJSONBase {
 	contJSONBase.AppArmorProfile = container.AppArmorProfile
+	contJSONBase.SeccompProfile = container.SeccompProfile
 	contJSONBase.ResolvConfPath = container.ResolvConfPath
 	contJSONBase.HostnamePath = container.HostnamePath
 	contJSONBase.HostsPath = container.HostsPath
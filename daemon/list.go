@@ -155,6 +155,14 @@ func (daemon *Daemon) foldFilter(config *ContainersConfig) (*listContext, error)
 		}
 	}
 
+	if i, ok := psFilters["health"]; ok {
+		for _, value := range i {
+			if !isValidHealthString(value) {
+				return nil, errors.New("Unrecognised filter value for health")
+			}
+		}
+	}
+
 	imagesFilter := map[string]bool{}
 	var ancestorFilter bool
 	if ancestors, ok := psFilters["ancestor"]; ok {
@@ -273,6 +281,11 @@ func includeContainerInList(container *Container, ctx *listContext) iterationAct
 		return excludeContainer
 	}
 
+	// Do not include container if its health doesn't match the filter
+	if !ctx.filters.ExactMatch("health", container.State.healthString()) {
+		return excludeContainer
+	}
+
 	if ctx.ancestorFilter {
 		if len(ctx.images) == 0 {
 			return excludeContainer
@@ -385,7 +398,7 @@ func (daemon *Daemon) Volumes(filter string) ([]*types.Volume, error) {
 		if filterUsed && daemon.volumes.Count(v) > 0 {
 			continue
 		}
-		volumesOut = append(volumesOut, volumeToAPIType(v))
+		volumesOut = append(volumesOut, daemon.volumeToAPIType(v))
 	}
 	return volumesOut, nil
 }
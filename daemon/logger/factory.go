@@ -2,7 +2,10 @@ package logger
 
 import (
 	"fmt"
+	"strconv"
 	"sync"
+
+	"github.com/docker/docker/pkg/units"
 )
 
 // Creator builds a logging driver instance with given context.
@@ -42,21 +45,29 @@ func (lf *logdriverFactory) registerLogOptValidator(name string, l LogOptValidat
 
 func (lf *logdriverFactory) get(name string) (Creator, error) {
 	lf.m.Lock()
-	defer lf.m.Unlock()
-
 	c, ok := lf.registry[name]
-	if !ok {
-		return c, fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	lf.m.Unlock()
+	if ok {
+		return c, nil
 	}
-	return c, nil
+
+	if c, err := getPlugin(name); err == nil {
+		return c, nil
+	}
+	return nil, fmt.Errorf("logger: no log driver named '%s' is registered", name)
 }
 
 func (lf *logdriverFactory) getLogOptValidator(name string) LogOptValidator {
 	lf.m.Lock()
-	defer lf.m.Unlock()
+	c, ok := lf.optValidator[name]
+	_, builtin := lf.registry[name]
+	lf.m.Unlock()
+	if ok || builtin {
+		return c
+	}
 
-	c, _ := lf.optValidator[name]
-	return c
+	// The options of the plugins are checked by the plugins.
+	return getPluginLogOptValidator(name)
 }
 
 var factory = &logdriverFactory{registry: make(map[string]Creator), optValidator: make(map[string]LogOptValidator)} // global factory instance
@@ -73,17 +84,97 @@ func RegisterLogOptValidator(name string, l LogOptValidator) error {
 	return factory.registerLogOptValidator(name, l)
 }
 
-// GetLogDriver provides the logging driver builder for a logging driver name.
+// GetLogDriver provides the logging driver builder for a logging driver name,
+// looking for a LogDriver plugin of that name if no driver is registered.
 func GetLogDriver(name string) (Creator, error) {
 	return factory.get(name)
 }
 
+// commonOpts are the options accepted by all the drivers, which are not
+// passed to their validators.
+var commonOpts = map[string]bool{
+	"mode":            true,
+	"max-buffer-size": true,
+	"cache-enabled":   true,
+	"cache-max-size":  true,
+	"cache-max-file":  true,
+
+	"multiline-pattern":       true,
+	"multiline-flush-timeout": true,
+	"multiline-max-size":      true,
+}
+
 // ValidateLogOpts checks the options for the given log driver. The
-// options supported are specific to the LogDriver implementation.
+// options supported are specific to the LogDriver implementation, but for
+// the delivery mode, the local cache and the multiline options which all
+// drivers accept.
 func ValidateLogOpts(name string, cfg map[string]string) error {
+	driverCfg := make(map[string]string)
+	for k, v := range cfg {
+		if !commonOpts[k] {
+			driverCfg[k] = v
+		}
+	}
+
+	switch cfg["mode"] {
+	case "", ModeBlocking, ModeNonBlock:
+	default:
+		return fmt.Errorf("logger: logging mode not supported: %s", cfg["mode"])
+	}
+	if s, ok := cfg["max-buffer-size"]; ok {
+		if cfg["mode"] != ModeNonBlock {
+			return fmt.Errorf("logger: max-buffer-size option is only supported with 'mode=%s'", ModeNonBlock)
+		}
+		if size, err := units.RAMInBytes(s); err != nil || size <= 0 {
+			return fmt.Errorf("logger: invalid max-buffer-size %s", s)
+		}
+	}
+
+	if err := validateCacheOpts(cfg); err != nil {
+		return err
+	}
+	if err := validateMultilineOpts(cfg); err != nil {
+		return err
+	}
+
 	l := factory.getLogOptValidator(name)
 	if l != nil {
-		return l(cfg)
+		return l(driverCfg)
+	}
+	return nil
+}
+
+// MaxBufferSize returns the size of the ring buffer of the non-blocking mode
+// set by the max-buffer-size option of cfg, or the default one.
+func MaxBufferSize(cfg map[string]string) (int64, error) {
+	s, ok := cfg["max-buffer-size"]
+	if !ok {
+		return DefaultMaxBufferSize, nil
+	}
+	return units.RAMInBytes(s)
+}
+
+func validateCacheOpts(cfg map[string]string) error {
+	enabled, err := strconv.ParseBool(cfg["cache-enabled"])
+	if s, ok := cfg["cache-enabled"]; ok && err != nil {
+		return fmt.Errorf("logger: invalid cache-enabled %s", s)
+	}
+	if !enabled {
+		for _, key := range []string{"cache-max-size", "cache-max-file"} {
+			if _, ok := cfg[key]; ok {
+				return fmt.Errorf("logger: %s option is only supported with cache-enabled=true", key)
+			}
+		}
+	}
+	if s, ok := cfg["cache-max-size"]; ok {
+		if size, err := units.FromHumanSize(s); err != nil || size <= 0 {
+			return fmt.Errorf("logger: invalid cache-max-size %s", s)
+		}
+	}
+	if s, ok := cfg["cache-max-file"]; ok {
+		if n, err := strconv.Atoi(s); err != nil || n < 1 {
+			return fmt.Errorf("logger: invalid cache-max-file %s", s)
+		}
 	}
 	return nil
 }
@@ -4,6 +4,7 @@
 package jsonfilelog
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -17,7 +18,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/logger"
-	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/daemon/logger/loggerutils"
 	"github.com/docker/docker/pkg/jsonlog"
 	"github.com/docker/docker/pkg/pubsub"
 	"github.com/docker/docker/pkg/tailfile"
@@ -38,6 +39,7 @@ type JSONFileLogger struct {
 	mu           sync.Mutex // protects buffer
 	capacity     int64      //maximum size of each file
 	n            int        //maximum number of files
+	compress     bool       //whether the rotated files are compressed
 	ctx          logger.Context
 	readers      map[*logger.LogWatcher]struct{} // stores the active log followers
 	notifyRotate *pubsub.Publisher
@@ -77,12 +79,23 @@ func New(ctx logger.Context) (logger.Logger, error) {
 			return nil, fmt.Errorf("max-file cannot be less than 1")
 		}
 	}
+	var compress bool
+	if compressString, ok := ctx.Config["compress"]; ok {
+		compress, err = strconv.ParseBool(compressString)
+		if err != nil {
+			return nil, err
+		}
+		if compress && maxFiles < 2 {
+			return nil, fmt.Errorf("compress cannot be true when max-file is less than 2")
+		}
+	}
 	return &JSONFileLogger{
 		f:            log,
 		buf:          bytes.NewBuffer(nil),
 		ctx:          ctx,
 		capacity:     capval,
 		n:            maxFiles,
+		compress:     compress,
 		readers:      make(map[*logger.LogWatcher]struct{}),
 		notifyRotate: pubsub.NewPublisher(0, 1),
 	}, nil
@@ -119,7 +132,7 @@ func writeLog(l *JSONFileLogger) (int64, error) {
 		if err := l.f.Close(); err != nil {
 			return -1, err
 		}
-		if err := rotate(name, l.n); err != nil {
+		if err := loggerutils.Rotate(name, l.n, l.compress); err != nil {
 			return -1, err
 		}
 		file, err := os.OpenFile(name, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0666)
@@ -140,47 +153,17 @@ func writeToBuf(l *JSONFileLogger) (int64, error) {
 	return i, err
 }
 
-func rotate(name string, n int) error {
-	if n < 2 {
-		return nil
-	}
-	for i := n - 1; i > 1; i-- {
-		oldFile := name + "." + strconv.Itoa(i)
-		replacingFile := name + "." + strconv.Itoa(i-1)
-		if err := backup(oldFile, replacingFile); err != nil {
-			return err
-		}
-	}
-	if err := backup(name+".1", name); err != nil {
-		return err
-	}
-	return nil
-}
-
-// backup renames a file from curr to old, creating an empty file curr if it does not exist.
-func backup(old, curr string) error {
-	if _, err := os.Stat(old); !os.IsNotExist(err) {
-		err := os.Remove(old)
-		if err != nil {
-			return err
-		}
-	}
-	if _, err := os.Stat(curr); os.IsNotExist(err) {
-		f, err := os.Create(curr)
-		if err != nil {
-			return err
-		}
-		f.Close()
-	}
-	return os.Rename(curr, old)
-}
-
-// ValidateLogOpt looks for json specific log options max-file & max-size.
+// ValidateLogOpt looks for json specific log options max-file, max-size &
+// compress.
 func ValidateLogOpt(cfg map[string]string) error {
 	for key := range cfg {
 		switch key {
 		case "max-file":
 		case "max-size":
+		case "compress":
+			if _, err := strconv.ParseBool(cfg[key]); err != nil {
+				return fmt.Errorf("invalid value for compress: %s", cfg[key])
+			}
 		default:
 			return fmt.Errorf("unknown log opt '%s' for json-file log driver", key)
 		}
@@ -236,19 +219,12 @@ func (l *JSONFileLogger) readLogs(logWatcher *logger.LogWatcher, config logger.R
 	defer close(logWatcher.Msg)
 
 	pth := l.ctx.LogPath
-	var files []io.ReadSeeker
-	for i := l.n; i > 1; i-- {
-		f, err := os.Open(fmt.Sprintf("%s.%d", pth, i-1))
-		if err != nil {
-			if !os.IsNotExist(err) {
-				logWatcher.Err <- err
-				break
-			}
-			continue
-		}
-		defer f.Close()
-		files = append(files, f)
+	rotated, err := loggerutils.OpenRotatedFiles(pth, l.n)
+	if err != nil {
+		logWatcher.Err <- err
+		return
 	}
+	defer loggerutils.CloseRotatedFiles(rotated)
 
 	latestFile, err := os.Open(pth)
 	if err != nil {
@@ -257,11 +233,8 @@ func (l *JSONFileLogger) readLogs(logWatcher *logger.LogWatcher, config logger.R
 	}
 	defer latestFile.Close()
 
-	files = append(files, latestFile)
-	tailer := ioutils.MultiReadSeeker(files...)
-
 	if config.Tail != 0 {
-		tailFile(tailer, logWatcher, config.Tail, config.Since)
+		tailFiles(latestFile, rotated, logWatcher, config.Tail, config.Since)
 	}
 
 	if !config.Follow {
@@ -286,15 +259,52 @@ func (l *JSONFileLogger) readLogs(logWatcher *logger.LogWatcher, config logger.R
 	l.notifyRotate.Evict(notifyRotate)
 }
 
-func tailFile(f io.ReadSeeker, logWatcher *logger.LogWatcher, tail int, since time.Time) {
-	var rdr io.Reader = f
+// tailFiles sends the last tail lines of the rotated files, given newest
+// first, and of the latest file, or all of them if tail is negative. The
+// rotated files older than the tail are not read.
+func tailFiles(latest io.ReadSeeker, rotated []*loggerutils.RotatedFile, logWatcher *logger.LogWatcher, tail int, since time.Time) {
+	var rdr io.Reader
 	if tail > 0 {
-		ls, err := tailfile.TailFile(f, tail)
+		lines, err := tailfile.TailFile(latest, tail)
 		if err != nil {
 			logWatcher.Err <- err
 			return
 		}
-		rdr = bytes.NewBuffer(bytes.Join(ls, []byte("\n")))
+		for _, f := range rotated {
+			if len(lines) >= tail {
+				break
+			}
+			var ls [][]byte
+			if f.Compressed {
+				var r io.Reader
+				if r, err = f.Reader(); err == nil {
+					ls, err = tailLines(r, tail-len(lines))
+				}
+			} else {
+				ls, err = tailfile.TailFile(f, tail-len(lines))
+			}
+			if err != nil {
+				logWatcher.Err <- err
+				return
+			}
+			lines = append(ls, lines...)
+		}
+		rdr = bytes.NewBuffer(bytes.Join(lines, []byte("\n")))
+	} else {
+		readers := make([]io.Reader, 0, len(rotated)+1)
+		for i := len(rotated) - 1; i >= 0; i-- {
+			r, err := rotated[i].Reader()
+			if err != nil {
+				logWatcher.Err <- err
+				return
+			}
+			readers = append(readers, r)
+		}
+		if _, err := latest.Seek(0, os.SEEK_SET); err != nil {
+			logWatcher.Err <- err
+			return
+		}
+		rdr = io.MultiReader(append(readers, latest)...)
 	}
 	dec := json.NewDecoder(rdr)
 	l := &jsonlog.JSONLog{}
@@ -313,6 +323,32 @@ func tailFile(f io.ReadSeeker, logWatcher *logger.LogWatcher, tail int, since ti
 	}
 }
 
+// tailLines returns the last n complete lines read from r, which can not be
+// seeked such as a compressed file, without their newline.
+func tailLines(r io.Reader, n int) ([][]byte, error) {
+	var (
+		lines [][]byte // the last lines, the oldest one at next once full
+		next  int
+	)
+	rdr := bufio.NewReader(r)
+	for {
+		line, err := rdr.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				return append(lines[next:], lines[:next]...), nil
+			}
+			return nil, err
+		}
+		line = line[:len(line)-1]
+		if len(lines) < n {
+			lines = append(lines, line)
+		} else {
+			lines[next] = line
+			next = (next + 1) % n
+		}
+	}
+}
+
 func followLogs(f *os.File, logWatcher *logger.LogWatcher, notifyRotate chan interface{}, since time.Time) {
 	dec := json.NewDecoder(f)
 	l := &jsonlog.JSONLog{}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
@@ -149,3 +150,76 @@ func TestJSONFileLoggerWithOpts(t *testing.T) {
 	}
 
 }
+
+func TestJSONFileLoggerCompress(t *testing.T) {
+	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	config := map[string]string{"max-file": "3", "max-size": "1k", "compress": "true"}
+	l, err := New(logger.Context{
+		ContainerID: cid,
+		LogPath:     filename,
+		Config:      config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < 40; i++ {
+		if err := l.Log(&logger.Message{ContainerID: cid, Line: []byte("line" + strconv.Itoa(i)), Source: "src1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The last rotated file is compressed in the background.
+	if _, err := os.Stat(filename + ".2.gz"); err != nil {
+		t.Fatalf("Expected the rotated file %s to be compressed: %v", filename+".2.gz", err)
+	}
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(filename + ".1"); os.IsNotExist(err) {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Expected no uncompressed rotated file, got %v", err)
+		}
+	}
+	if _, err := os.Stat(filename + ".1.gz"); err != nil {
+		t.Fatalf("Expected the rotated file %s to be compressed: %v", filename+".1.gz", err)
+	}
+
+	// The files hold 16 lines, the last 10 lines span .1.gz and the current
+	// file.
+	watcher := l.(logger.LogReader).ReadLogs(logger.ReadConfig{Tail: 10})
+	var lines []string
+	for msg := range watcher.Msg {
+		lines = append(lines, string(msg.Line))
+	}
+	select {
+	case err := <-watcher.Err:
+		t.Fatal(err)
+	default:
+	}
+	var expected []string
+	for i := 30; i < 40; i++ {
+		expected = append(expected, "line"+strconv.Itoa(i)+"\n")
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("Expected %q, got %q", expected, lines)
+	}
+}
+
+func TestValidateLogOptCompress(t *testing.T) {
+	if err := ValidateLogOpt(map[string]string{"compress": "true"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateLogOpt(map[string]string{"compress": "maybe"}); err == nil {
+		t.Fatal("Expected an error with an invalid compress value")
+	}
+	if _, err := New(logger.Context{Config: map[string]string{"compress": "true"}}); err == nil {
+		t.Fatal("Expected an error with compress and a single file")
+	}
+}
@@ -0,0 +1,197 @@
+// Package local provides a Logger implementation storing the logs on the
+// host server in a compact binary format, which is faster to write and to
+// read back than the JSON lines of json-file.
+package local
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	"github.com/docker/docker/pkg/pubsub"
+	"github.com/docker/docker/pkg/units"
+)
+
+const (
+	// Name is the name of the local logging driver.
+	Name = "local"
+
+	defaultMaxSize  = 20 * 1024 * 1024
+	defaultMaxFile  = 5
+	defaultCompress = true
+)
+
+// Logger logs the messages to files on the host, rotated once they reach
+// their maximum size.
+type Logger struct {
+	mu           sync.Mutex // protects the file and the buffer
+	f            *os.File
+	size         int64 // size of the current file
+	buf          []byte
+	capacity     int64 // maximum size of each file
+	n            int   // maximum number of files
+	compress     bool  // whether the rotated files are compressed
+	ctx          logger.Context
+	readers      map[*logger.LogWatcher]struct{} // stores the active log followers
+	notifyRotate *pubsub.Publisher
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+// New creates a local logger writing to the file at the log path of ctx.
+func New(ctx logger.Context) (logger.Logger, error) {
+	var capacity int64 = defaultMaxSize
+	if s, ok := ctx.Config["max-size"]; ok {
+		var err error
+		capacity, err = units.FromHumanSize(s)
+		if err != nil {
+			return nil, err
+		}
+		if capacity <= 0 {
+			return nil, fmt.Errorf("max-size must be a positive number")
+		}
+	}
+	maxFiles := defaultMaxFile
+	if s, ok := ctx.Config["max-file"]; ok {
+		var err error
+		maxFiles, err = strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		if maxFiles < 1 {
+			return nil, fmt.Errorf("max-file cannot be less than 1")
+		}
+	}
+	compress := defaultCompress
+	if s, ok := ctx.Config["compress"]; ok {
+		var err error
+		compress, err = strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// A single file is truncated rather than rotated
+	if maxFiles < 2 {
+		compress = false
+	}
+
+	f, err := os.OpenFile(ctx.LogPath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	// A record torn by a crash is removed, for the next ones to be read.
+	size, err := lastRecordEnd(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if size < fi.Size() {
+		logrus.Warnf("Truncating the torn last record of the log file %s", ctx.LogPath)
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &Logger{
+		f:            f,
+		size:         size,
+		capacity:     capacity,
+		n:            maxFiles,
+		compress:     compress,
+		ctx:          ctx,
+		readers:      make(map[*logger.LogWatcher]struct{}),
+		notifyRotate: pubsub.NewPublisher(0, 1),
+	}, nil
+}
+
+// Log writes the message to the file as a single record, rotating the file
+// first if it is full.
+func (l *Logger) Log(msg *logger.Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = encodeRecord(l.buf[:0], msg)
+	if l.size > 0 && l.size+int64(len(l.buf)) > l.capacity {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.f.Write(l.buf)
+	l.size += int64(n)
+	return err
+}
+
+func (l *Logger) rotate() error {
+	name := l.f.Name()
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	if err := loggerutils.Rotate(name, l.n, l.compress); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	l.size = 0
+	l.notifyRotate.Publish(struct{}{})
+	return nil
+}
+
+// ValidateLogOpt looks for the local specific log options max-file,
+// max-size & compress.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key, value := range cfg {
+		switch key {
+		case "max-file":
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return fmt.Errorf("invalid value for max-file: %s", value)
+			}
+		case "max-size":
+			if size, err := units.FromHumanSize(value); err != nil || size <= 0 {
+				return fmt.Errorf("invalid value for max-size: %s", value)
+			}
+		case "compress":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid value for compress: %s", value)
+			}
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	return nil
+}
+
+// Close closes the file and signals all the readers to stop.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	err := l.f.Close()
+	for r := range l.readers {
+		r.Close()
+		delete(l.readers, r)
+	}
+	l.mu.Unlock()
+	return err
+}
+
+// Name returns the name of the logger.
+func (l *Logger) Name() string {
+	return Name
+}
@@ -0,0 +1,225 @@
+package local
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	now := time.Now().UTC()
+	msgs := []*logger.Message{
+		{Line: []byte("line1"), Source: "stdout", Timestamp: now},
+		{Line: []byte{}, Source: "stderr", Timestamp: now.Add(time.Second)},
+	}
+	var buf []byte
+	for _, msg := range msgs {
+		buf = encodeRecord(buf, msg)
+	}
+
+	r := bytes.NewReader(buf)
+	var b []byte
+	for _, expected := range msgs {
+		msg, nb, err := decodeRecord(r, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b = nb
+		if string(msg.Line) != string(expected.Line)+"\n" || msg.Source != expected.Source || !msg.Timestamp.Equal(expected.Timestamp) {
+			t.Fatalf("Expected %q on %s at %s, got %q on %s at %s", expected.Line, expected.Source, expected.Timestamp, msg.Line, msg.Source, msg.Timestamp)
+		}
+	}
+	if _, _, err := decodeRecord(r, b); err != io.EOF {
+		t.Fatalf("Expected EOF, got %v", err)
+	}
+
+	first := encodeRecord(nil, msgs[0])
+	if _, _, err := decodeRecord(bytes.NewReader(first[:len(first)-1]), nil); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected a truncated record, got %v", err)
+	}
+}
+
+func TestSeekTail(t *testing.T) {
+	var buf []byte
+	for i := 0; i < 5; i++ {
+		buf = encodeRecord(buf, &logger.Message{Line: []byte("line" + strconv.Itoa(i)), Source: "stdout", Timestamp: time.Now()})
+	}
+	r := bytes.NewReader(buf)
+
+	n, err := seekTail(r, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("Expected 2 records, got %d", n)
+	}
+	msg, _, err := decodeRecord(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.Line) != "line3\n" {
+		t.Fatalf("Expected line3, got %q", msg.Line)
+	}
+
+	n, err = seekTail(r, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatalf("Expected 5 records, got %d", n)
+	}
+	if offset, _ := r.Seek(0, os.SEEK_CUR); offset != 0 {
+		t.Fatalf("Expected to seek to the first record, got offset %d", offset)
+	}
+}
+
+func TestLocalLoggerRotateAndTail(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+
+	l, err := New(logger.Context{
+		ContainerID: "test",
+		LogPath:     filename,
+		Config:      map[string]string{"max-size": "1k", "max-file": "3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// Each record is 30 bytes, the files hold 33 of them.
+	now := time.Now().UTC()
+	for i := 0; i < 90; i++ {
+		msg := &logger.Message{ContainerID: "test", Line: []byte("line" + strconv.Itoa(i+100)), Source: "stdout", Timestamp: now.Add(time.Duration(i) * time.Second)}
+		if err := l.Log(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The last rotated file is compressed in the background, and may be
+	// read either way.
+	if _, err := os.Stat(filename + ".2.gz"); err != nil {
+		t.Fatalf("Expected the rotated file %s to be compressed: %v", filename+".2.gz", err)
+	}
+
+	readLines := func(config logger.ReadConfig) []string {
+		watcher := l.(logger.LogReader).ReadLogs(config)
+		var lines []string
+		for msg := range watcher.Msg {
+			lines = append(lines, string(msg.Line))
+		}
+		select {
+		case err := <-watcher.Err:
+			t.Fatal(err)
+		default:
+		}
+		return lines
+	}
+	expectedLines := func(from, to int) []string {
+		var lines []string
+		for i := from; i < to; i++ {
+			lines = append(lines, "line"+strconv.Itoa(i+100)+"\n")
+		}
+		return lines
+	}
+
+	// The current file holds the last 24 records, the tail spans .1.gz.
+	if lines, expected := readLines(logger.ReadConfig{Tail: 40}), expectedLines(50, 90); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("Expected %q, got %q", expected, lines)
+	}
+	if lines, expected := readLines(logger.ReadConfig{Tail: -1}), expectedLines(0, 90); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("Expected %q, got %q", expected, lines)
+	}
+	since := now.Add(85 * time.Second)
+	if lines, expected := readLines(logger.ReadConfig{Tail: -1, Since: since}), expectedLines(85, 90); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("Expected %q, got %q", expected, lines)
+	}
+}
+
+func TestLocalLoggerFollow(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	l, err := New(logger.Context{ContainerID: "test", LogPath: filepath.Join(tmp, "container.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	watcher := l.(logger.LogReader).ReadLogs(logger.ReadConfig{Tail: -1, Follow: true})
+	defer watcher.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := l.Log(&logger.Message{ContainerID: "test", Line: []byte("line" + strconv.Itoa(i)), Source: "stdout", Timestamp: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case msg := <-watcher.Msg:
+			if expected := "line" + strconv.Itoa(i) + "\n"; string(msg.Line) != expected {
+				t.Fatalf("Expected %q, got %q", expected, msg.Line)
+			}
+		case err := <-watcher.Err:
+			t.Fatal(err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timeout following the logs")
+		}
+	}
+}
+
+func TestLocalLoggerTruncatesTornRecord(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+
+	// A crash left the last record cut.
+	now := time.Now().UTC()
+	var buf []byte
+	for i := 0; i < 3; i++ {
+		buf = encodeRecord(buf, &logger.Message{Line: []byte("line" + strconv.Itoa(i)), Source: "stdout", Timestamp: now})
+	}
+	if err := ioutil.WriteFile(filename, buf[:len(buf)-5], 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := New(logger.Context{ContainerID: "test", LogPath: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Log(&logger.Message{ContainerID: "test", Line: []byte("line3"), Source: "stdout", Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := l.(logger.LogReader).ReadLogs(logger.ReadConfig{Tail: -1})
+	var lines []string
+	for msg := range watcher.Msg {
+		lines = append(lines, string(msg.Line))
+	}
+	select {
+	case err := <-watcher.Err:
+		t.Fatal(err)
+	default:
+	}
+	if expected := []string{"line0\n", "line1\n", "line3\n"}; !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("Expected %q, got %q", expected, lines)
+	}
+}
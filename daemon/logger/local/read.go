@@ -0,0 +1,248 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/fsnotify.v1"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+)
+
+// ReadLogs implements the logger's LogReader interface for the logs
+// created by this driver.
+func (l *Logger) ReadLogs(config logger.ReadConfig) *logger.LogWatcher {
+	logWatcher := logger.NewLogWatcher()
+
+	go l.readLogs(logWatcher, config)
+	return logWatcher
+}
+
+func (l *Logger) readLogs(logWatcher *logger.LogWatcher, config logger.ReadConfig) {
+	defer close(logWatcher.Msg)
+
+	pth := l.ctx.LogPath
+	rotated, err := loggerutils.OpenRotatedFiles(pth, l.n)
+	if err != nil {
+		logWatcher.Err <- err
+		return
+	}
+	defer loggerutils.CloseRotatedFiles(rotated)
+
+	latestFile, err := os.Open(pth)
+	if err != nil {
+		logWatcher.Err <- err
+		return
+	}
+	defer latestFile.Close()
+
+	if config.Tail != 0 {
+		tailFiles(latestFile, rotated, logWatcher, config.Tail, config.Since)
+	}
+
+	if !config.Follow {
+		return
+	}
+
+	if config.Tail >= 0 {
+		latestFile.Seek(0, os.SEEK_END)
+	}
+
+	l.mu.Lock()
+	l.readers[logWatcher] = struct{}{}
+	l.mu.Unlock()
+
+	notifyRotate := l.notifyRotate.Subscribe()
+	followLogs(latestFile, logWatcher, notifyRotate, config.Since)
+
+	l.mu.Lock()
+	delete(l.readers, logWatcher)
+	l.mu.Unlock()
+
+	l.notifyRotate.Evict(notifyRotate)
+}
+
+// tailFiles sends the last tail records of the rotated files, given newest
+// first, and of the latest file, or all of them if tail is negative. The
+// rotated files older than the tail are not read.
+func tailFiles(latest io.ReadSeeker, rotated []*loggerutils.RotatedFile, logWatcher *logger.LogWatcher, tail int, since time.Time) {
+	var readers []io.Reader
+	if tail > 0 {
+		n, err := seekTail(latest, tail)
+		if err != nil {
+			logWatcher.Err <- err
+			return
+		}
+		readers = append(readers, latest)
+		for _, f := range rotated {
+			if n >= tail {
+				break
+			}
+			var (
+				r     io.Reader
+				count int
+			)
+			if f.Compressed {
+				if r, err = f.Reader(); err == nil {
+					var records []byte
+					records, count, err = tailRecords(r, tail-n)
+					r = bytes.NewReader(records)
+				}
+			} else {
+				count, err = seekTail(f, tail-n)
+				r = f
+			}
+			if err != nil {
+				logWatcher.Err <- err
+				return
+			}
+			readers = append([]io.Reader{r}, readers...)
+			n += count
+		}
+	} else {
+		for i := len(rotated) - 1; i >= 0; i-- {
+			r, err := rotated[i].Reader()
+			if err != nil {
+				logWatcher.Err <- err
+				return
+			}
+			readers = append(readers, r)
+		}
+		if _, err := latest.Seek(0, os.SEEK_SET); err != nil {
+			logWatcher.Err <- err
+			return
+		}
+		readers = append(readers, latest)
+	}
+
+	rdr := bufio.NewReader(io.MultiReader(readers...))
+
+	var buf []byte
+	for {
+		msg, b, err := decodeRecord(rdr, buf)
+		buf = b
+		if err != nil {
+			if err != io.EOF {
+				logWatcher.Err <- err
+			}
+			return
+		}
+		if !since.IsZero() && msg.Timestamp.Before(since) {
+			continue
+		}
+		select {
+		case logWatcher.Msg <- msg:
+		case <-logWatcher.WatchClose():
+			return
+		}
+	}
+}
+
+// nextRecord reads the next record of f. A truncated record, which is being
+// written, is read again on the next call.
+func nextRecord(f *os.File, buf []byte) (*logger.Message, []byte, error) {
+	offset, err := f.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return nil, buf, err
+	}
+	msg, buf, err := decodeRecord(f, buf)
+	if err == io.ErrUnexpectedEOF {
+		if _, err := f.Seek(offset, os.SEEK_SET); err != nil {
+			return nil, buf, err
+		}
+		return nil, buf, io.EOF
+	}
+	return msg, buf, err
+}
+
+func followLogs(f *os.File, logWatcher *logger.LogWatcher, notifyRotate chan interface{}, since time.Time) {
+	fileWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logWatcher.Err <- err
+		return
+	}
+	defer fileWatcher.Close()
+	if err := fileWatcher.Add(f.Name()); err != nil {
+		logWatcher.Err <- err
+		return
+	}
+	// The files opened after a rotation are closed once done
+	latestFile := f
+	defer func() {
+		if f != latestFile {
+			f.Close()
+		}
+	}()
+
+	var buf []byte
+	for {
+		msg, b, err := nextRecord(f, buf)
+		buf = b
+		if err != nil {
+			if err != io.EOF {
+				logWatcher.Err <- err
+				return
+			}
+
+			select {
+			case <-fileWatcher.Events:
+				continue
+			case err := <-fileWatcher.Errors:
+				logWatcher.Err <- err
+				return
+			case <-logWatcher.WatchClose():
+				return
+			case <-notifyRotate:
+				// Read the records left in the rotated file first
+				for {
+					msg, b, err := nextRecord(f, buf)
+					buf = b
+					if err != nil {
+						break
+					}
+					if since.IsZero() || !msg.Timestamp.Before(since) {
+						logWatcher.Msg <- msg
+					}
+				}
+				fileWatcher.Remove(f.Name())
+				if f != latestFile {
+					f.Close()
+				}
+
+				f, err = os.Open(f.Name())
+				if err != nil {
+					logWatcher.Err <- err
+					return
+				}
+				if err := fileWatcher.Add(f.Name()); err != nil {
+					logWatcher.Err <- err
+				}
+				continue
+			}
+		}
+
+		if !since.IsZero() && msg.Timestamp.Before(since) {
+			continue
+		}
+		select {
+		case logWatcher.Msg <- msg:
+		case <-logWatcher.WatchClose():
+			logWatcher.Msg <- msg
+			for {
+				msg, b, err := nextRecord(f, buf)
+				buf = b
+				if err != nil {
+					return
+				}
+				if !since.IsZero() && msg.Timestamp.Before(since) {
+					continue
+				}
+				logWatcher.Msg <- msg
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+// A record stores a message as:
+//
+//	| size | timestamp | len(source) | source | line | size |
+//
+// where the sizes are the big endian uint32 size of the fields between
+// them, the timestamp is the big endian int64 nanoseconds since the Unix
+// epoch, and the length of the source a byte. The size repeated at the end
+// of the record allows to read the records backward.
+const (
+	sizeLen   = 4
+	headerLen = 8 + 1
+)
+
+var errCorruptRecord = errors.New("local: corrupt log record")
+
+// encodeRecord appends the record of msg to buf.
+func encodeRecord(buf []byte, msg *logger.Message) []byte {
+	source := msg.Source
+	if len(source) > 255 {
+		source = source[:255]
+	}
+	size := headerLen + len(source) + len(msg.Line)
+
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:sizeLen], uint32(size))
+	buf = append(buf, b[:sizeLen]...)
+	binary.BigEndian.PutUint64(b[:], uint64(msg.Timestamp.UnixNano()))
+	buf = append(buf, b[:]...)
+	buf = append(buf, byte(len(source)))
+	buf = append(buf, source...)
+	buf = append(buf, msg.Line...)
+	binary.BigEndian.PutUint32(b[:sizeLen], uint32(size))
+	return append(buf, b[:sizeLen]...)
+}
+
+// decodeRecord reads the next record from r. It returns io.EOF if there is
+// no more record, and io.ErrUnexpectedEOF if the record is truncated. The
+// line of the message ends with a newline, as for the other log readers.
+func decodeRecord(r io.Reader, buf []byte) (*logger.Message, []byte, error) {
+	var b [sizeLen]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return nil, buf, err
+	}
+	size := int(binary.BigEndian.Uint32(b[:]))
+	if size < headerLen {
+		return nil, buf, errCorruptRecord
+	}
+	if cap(buf) < size+sizeLen {
+		buf = make([]byte, size+sizeLen)
+	}
+	buf = buf[:size+sizeLen]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, buf, err
+	}
+	if int(binary.BigEndian.Uint32(buf[size:])) != size {
+		return nil, buf, errCorruptRecord
+	}
+
+	sourceLen := int(buf[8])
+	if headerLen+sourceLen > size {
+		return nil, buf, errCorruptRecord
+	}
+	line := buf[headerLen+sourceLen : size]
+	msg := &logger.Message{
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(buf[:8]))).UTC(),
+		Source:    string(buf[headerLen : headerLen+sourceLen]),
+		Line:      append(append(make([]byte, 0, len(line)+1), line...), '\n'),
+	}
+	return msg, buf, nil
+}
+
+// seekTail seeks f to the beginning of its last n records, or of all of
+// them if there are fewer, and returns the number of records after the new
+// offset.
+func seekTail(f io.ReadSeeker, n int) (int, error) {
+	offset, err := f.Seek(0, os.SEEK_END)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		b     [sizeLen]byte
+		count int
+	)
+	for count < n && offset > 0 {
+		if offset < 2*sizeLen+headerLen {
+			return count, errCorruptRecord
+		}
+		if _, err := f.Seek(offset-sizeLen, os.SEEK_SET); err != nil {
+			return count, err
+		}
+		if _, err := io.ReadFull(f, b[:]); err != nil {
+			return count, err
+		}
+		offset -= int64(binary.BigEndian.Uint32(b[:])) + 2*sizeLen
+		if offset < 0 {
+			return count, errCorruptRecord
+		}
+		count++
+	}
+	_, err = f.Seek(offset, os.SEEK_SET)
+	return count, err
+}
+
+// tailRecords returns the last n records read from r, which can not be
+// seeked such as a compressed file, as they are encoded, and their number.
+func tailRecords(r io.Reader, n int) ([]byte, int, error) {
+	var (
+		records [][]byte // the last records, the oldest one at next once full
+		next    int
+		buf     []byte
+	)
+	rdr := bufio.NewReader(r)
+	for {
+		_, b, err := decodeRecord(rdr, buf)
+		buf = b
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		record := make([]byte, sizeLen+len(b))
+		binary.BigEndian.PutUint32(record, uint32(len(b)-sizeLen))
+		copy(record[sizeLen:], b)
+		if len(records) < n {
+			records = append(records, record)
+		} else {
+			records[next] = record
+			next = (next + 1) % n
+		}
+	}
+	return bytes.Join(append(records[next:], records[:next]...), nil), len(records), nil
+}
+
+// lastRecordEnd returns the offset of the end of the last complete record of
+// f, whose size is size. It is size unless the last record was torn, such as
+// by a crash of the daemon while it was written.
+func lastRecordEnd(f io.ReaderAt, size int64) (int64, error) {
+	if size == 0 {
+		return 0, nil
+	}
+	// The size at the end of a complete record matches the one at its
+	// beginning.
+	var b [sizeLen]byte
+	if size >= 2*sizeLen+headerLen {
+		if _, err := f.ReadAt(b[:], size-sizeLen); err != nil {
+			return 0, err
+		}
+		recordSize := int64(binary.BigEndian.Uint32(b[:]))
+		if start := size - recordSize - 2*sizeLen; recordSize >= headerLen && start >= 0 {
+			if _, err := f.ReadAt(b[:], start); err != nil {
+				return 0, err
+			}
+			if int64(binary.BigEndian.Uint32(b[:])) == recordSize {
+				return size, nil
+			}
+		}
+	}
+
+	// Otherwise the records are read from the start of the file.
+	var (
+		offset int64
+		buf    []byte
+	)
+	rdr := bufio.NewReader(io.NewSectionReader(f, 0, size))
+	for {
+		_, b, err := decodeRecord(rdr, buf)
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF, errCorruptRecord:
+			return offset, nil
+		default:
+			return 0, err
+		}
+		buf = b
+		offset += int64(sizeLen + len(b))
+	}
+}
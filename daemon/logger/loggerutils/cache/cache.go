@@ -0,0 +1,89 @@
+// Package cache provides a local cache of the logs sent to the drivers which
+// can not read them back, so that `docker logs` works with every driver.
+package cache
+
+import (
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
+)
+
+const (
+	// DefaultMaxSize is the default size of a file of the cache.
+	DefaultMaxSize = "20m"
+	// DefaultMaxFile is the default number of files of the cache.
+	DefaultMaxFile = "5"
+)
+
+// Enabled returns whether the logs are to be cached with the options cfg,
+// that is if the cache-enabled option is set. The logs of the drivers which
+// read them back are not cached either way.
+func Enabled(cfg map[string]string) bool {
+	enabled, _ := strconv.ParseBool(cfg["cache-enabled"])
+	return enabled
+}
+
+// WithLocalCache returns a logger sending the messages to l, and writing
+// them to a json-file at logPath as well, from which they are read back.
+// The file is rotated as set by the cache-max-size and cache-max-file
+// options of ctx.
+func WithLocalCache(l logger.Logger, ctx logger.Context, logPath string) (logger.Logger, error) {
+	c, err := NewReader(ctx, logPath)
+	if err != nil {
+		return nil, err
+	}
+	return &loggerWithCache{l: l, cache: c}, nil
+}
+
+// NewReader returns a logger reading back the logs cached at logPath with
+// the options of ctx, without the driver, such as for a stopped container.
+func NewReader(ctx logger.Context, logPath string) (logger.Logger, error) {
+	cacheCtx := ctx
+	cacheCtx.LogPath = logPath
+	cacheCtx.Config = map[string]string{
+		"max-size": DefaultMaxSize,
+		"max-file": DefaultMaxFile,
+	}
+	if s, ok := ctx.Config["cache-max-size"]; ok {
+		cacheCtx.Config["max-size"] = s
+	}
+	if s, ok := ctx.Config["cache-max-file"]; ok {
+		cacheCtx.Config["max-file"] = s
+	}
+	return jsonfilelog.New(cacheCtx)
+}
+
+type loggerWithCache struct {
+	l     logger.Logger
+	cache logger.Logger
+}
+
+// Log writes the message to the cache, then sends it to the driver. A
+// message which can not be cached is still sent to the driver.
+func (l *loggerWithCache) Log(msg *logger.Message) error {
+	if err := l.cache.Log(msg); err != nil {
+		logrus.Errorf("Failed to cache msg %q for logger %s: %s", msg.Line, l.l.Name(), err)
+	}
+	return l.l.Log(msg)
+}
+
+// Name returns the name of the driver.
+func (l *loggerWithCache) Name() string {
+	return l.l.Name()
+}
+
+// ReadLogs reads the logs from the cache.
+func (l *loggerWithCache) ReadLogs(config logger.ReadConfig) *logger.LogWatcher {
+	return l.cache.(logger.LogReader).ReadLogs(config)
+}
+
+// Close closes the driver and the cache.
+func (l *loggerWithCache) Close() error {
+	err := l.l.Close()
+	if cacheErr := l.cache.Close(); err == nil {
+		err = cacheErr
+	}
+	return err
+}
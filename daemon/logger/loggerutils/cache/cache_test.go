@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+type testLogger struct {
+	messages []*logger.Message
+	closed   bool
+}
+
+func (l *testLogger) Log(msg *logger.Message) error {
+	l.messages = append(l.messages, msg)
+	return nil
+}
+
+func (l *testLogger) Name() string {
+	return "test"
+}
+
+func (l *testLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
+func TestEnabled(t *testing.T) {
+	if Enabled(nil) {
+		t.Fatal("Expected the logs not to be cached by default")
+	}
+	if !Enabled(map[string]string{"cache-enabled": "true"}) {
+		t.Fatal("Expected the cache to be enabled with cache-enabled=true")
+	}
+}
+
+func TestLocalCache(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	driver := &testLogger{}
+	l, err := WithLocalCache(driver, logger.Context{ContainerID: "test"}, filepath.Join(tmp, "cache.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	for _, line := range []string{"line1", "line2", "line3"} {
+		if err := l.Log(&logger.Message{ContainerID: "test", Line: []byte(line), Source: "stdout", Timestamp: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(driver.messages) != 3 {
+		t.Fatalf("Expected 3 messages sent to the driver, got %d", len(driver.messages))
+	}
+
+	reader, ok := l.(logger.LogReader)
+	if !ok {
+		t.Fatal("Expected the cached logger to read the logs back")
+	}
+	watcher := reader.ReadLogs(logger.ReadConfig{Tail: 2})
+	for _, expected := range []string{"line2\n", "line3\n"} {
+		select {
+		case msg := <-watcher.Msg:
+			if string(msg.Line) != expected || msg.Source != "stdout" {
+				t.Fatalf("Expected %q on stdout, got %q on %s", expected, msg.Line, msg.Source)
+			}
+		case err := <-watcher.Err:
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timeout reading the cached logs")
+		}
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !driver.closed {
+		t.Fatal("Expected the driver to be closed with the cache")
+	}
+}
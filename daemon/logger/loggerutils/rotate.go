@@ -0,0 +1,192 @@
+package loggerutils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// compressions holds the rotated files being compressed, by the name of
+// their log file, for Rotate to wait for them before shifting the files.
+var compressions = struct {
+	sync.Mutex
+	m map[string]chan struct{}
+}{m: make(map[string]chan struct{})}
+
+// Rotate renames the log file name to name.1, shifting the files rotated
+// before up to name.<n-1>, and removing the oldest one. A rotated file is
+// named name.<i>.gz if it is compressed, and keeps its name as it is shifted
+// whatever compress is. If compress is set, name.1 is compressed to
+// name.1.gz in the background, which the next rotation waits for.
+func Rotate(name string, n int, compress bool) error {
+	if n < 2 {
+		return nil
+	}
+	waitCompression(name)
+
+	for _, ext := range []string{"", ".gz"} {
+		if err := os.Remove(rotatedName(name, n-1) + ext); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	for i := n - 1; i > 1; i-- {
+		for _, ext := range []string{"", ".gz"} {
+			if err := os.Rename(rotatedName(name, i-1)+ext, rotatedName(name, i)+ext); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(name, rotatedName(name, 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if compress {
+		startCompression(name)
+	}
+	return nil
+}
+
+func rotatedName(name string, i int) string {
+	return fmt.Sprintf("%s.%d", name, i)
+}
+
+// startCompression compresses name.1 to name.1.gz in a goroutine.
+func startCompression(name string) {
+	done := make(chan struct{})
+	compressions.Lock()
+	compressions.m[name] = done
+	compressions.Unlock()
+
+	go func() {
+		defer func() {
+			compressions.Lock()
+			delete(compressions.m, name)
+			compressions.Unlock()
+			close(done)
+		}()
+		src := rotatedName(name, 1)
+		if err := compressFile(src+".gz", src); err != nil {
+			logrus.Errorf("Failed to compress the rotated log file %s: %v", src, err)
+		}
+	}()
+}
+
+// waitCompression waits for the rotated file of the log file name being
+// compressed, if any.
+func waitCompression(name string) {
+	compressions.Lock()
+	done := compressions.m[name]
+	compressions.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// compressFile writes the gzip compression of src to dst, and removes src.
+// The compression is written to a temporary file renamed to dst once
+// complete, so that the readers open either src or the whole dst.
+func compressFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(out)
+	if _, err := io.Copy(w, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := w.Close(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// RotatedFile is a file rotated from a log file by Rotate.
+type RotatedFile struct {
+	*os.File
+	// Compressed is set if the file is compressed, in which case it is read
+	// with Reader only.
+	Compressed bool
+}
+
+// Reader returns a reader of the content of the file from its start,
+// decompressing it as it is read if it is compressed.
+func (f *RotatedFile) Reader() (io.Reader, error) {
+	if _, err := f.Seek(0, os.SEEK_SET); err != nil {
+		return nil, err
+	}
+	if !f.Compressed {
+		return f.File, nil
+	}
+	r, err := gzip.NewReader(f.File)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing log file %s: %v", f.Name(), err)
+	}
+	return r, nil
+}
+
+// OpenRotatedFiles opens the files rotated from the log file name by Rotate
+// with up to n files, newest first. They are all opened at once, so that
+// they are read as they were even if the log file is rotated meanwhile.
+func OpenRotatedFiles(name string, n int) ([]*RotatedFile, error) {
+	var files []*RotatedFile
+	for i := 1; i < n; i++ {
+		f, err := openRotatedFile(rotatedName(name, i))
+		if err != nil {
+			CloseRotatedFiles(files)
+			return nil, err
+		}
+		if f != nil {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// CloseRotatedFiles closes the files opened by OpenRotatedFiles.
+func CloseRotatedFiles(files []*RotatedFile) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// openRotatedFile opens the rotated file name, or name.gz if it only exists
+// compressed. It returns nil if neither exists.
+func openRotatedFile(name string) (*RotatedFile, error) {
+	f, err := os.Open(name)
+	if err == nil {
+		return &RotatedFile{File: f}, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err = os.Open(name + ".gz")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &RotatedFile{File: f, Compressed: true}, nil
+}
@@ -0,0 +1,54 @@
+package loggerutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateKeepsCompressionOfRotatedFiles(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-rotate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	name := filepath.Join(tmp, "container.log")
+
+	// The files are rotated compressed, then uncompressed.
+	for i, compress := range []bool{true, true, false} {
+		if err := ioutil.WriteFile(name, []byte{byte('a' + i)}, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := Rotate(name, 4, compress); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitCompression(name)
+	for _, rotated := range []string{name + ".1", name + ".2.gz", name + ".3.gz"} {
+		if _, err := os.Stat(rotated); err != nil {
+			t.Fatalf("Expected the rotated file %s: %v", rotated, err)
+		}
+	}
+
+	files, err := OpenRotatedFiles(name, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CloseRotatedFiles(files)
+	var content string
+	for _, f := range files {
+		r, err := f.Reader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content += string(b)
+	}
+	if content != "cba" {
+		t.Fatalf("Expected the rotated files newest first, got %q", content)
+	}
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/units"
+)
+
+const (
+	// DefaultMultilineFlushTimeout is the default time after which a record
+	// is logged if no other line is added to it.
+	DefaultMultilineFlushTimeout = time.Second
+	// DefaultMultilineMaxSize is the default maximum size of a record, in
+	// bytes of its lines.
+	DefaultMultilineMaxSize = 256 * 1024
+)
+
+// multilineLogger merges the consecutive lines of a stream which belong to
+// the same record, such as a stack trace, into a single message. A record
+// starts with a line matching the pattern, and is logged when the next one
+// starts, when it would grow larger than maxSize, or when no line is added
+// to it for the flush timeout.
+type multilineLogger struct {
+	l       Logger
+	pattern *regexp.Regexp
+	timeout time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	records map[string]*multilineRecord // the pending records, by source
+	closed  bool
+}
+
+type multilineRecord struct {
+	msg   *Message
+	timer *time.Timer
+}
+
+// multilineWithReader is a multilineLogger whose logger reads the logs back.
+type multilineWithReader struct {
+	*multilineLogger
+}
+
+// ReadLogs reads the logs from the wrapped logger.
+func (m *multilineWithReader) ReadLogs(config ReadConfig) *LogWatcher {
+	return m.l.(LogReader).ReadLogs(config)
+}
+
+// NewMultilineLogger returns a logger merging the lines of the records
+// before logging them to l, as set by the multiline-pattern,
+// multiline-flush-timeout and multiline-max-size options of cfg. It reads
+// the logs back if l does.
+func NewMultilineLogger(l Logger, cfg map[string]string) (Logger, error) {
+	pattern, err := regexp.Compile(cfg["multiline-pattern"])
+	if err != nil {
+		return nil, err
+	}
+	timeout := DefaultMultilineFlushTimeout
+	if s, ok := cfg["multiline-flush-timeout"]; ok {
+		if timeout, err = time.ParseDuration(s); err != nil {
+			return nil, err
+		}
+	}
+	var maxSize int64 = DefaultMultilineMaxSize
+	if s, ok := cfg["multiline-max-size"]; ok {
+		if maxSize, err = units.RAMInBytes(s); err != nil {
+			return nil, err
+		}
+	}
+
+	m := &multilineLogger{
+		l:       l,
+		pattern: pattern,
+		timeout: timeout,
+		maxSize: int(maxSize),
+		records: make(map[string]*multilineRecord),
+	}
+	if _, ok := l.(LogReader); ok {
+		return &multilineWithReader{m}, nil
+	}
+	return m, nil
+}
+
+// Log adds the line of the message to the pending record of its source,
+// logging the record first if the line starts another one.
+func (m *multilineLogger) Log(msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return m.l.Log(msg)
+	}
+
+	var err error
+	r, ok := m.records[msg.Source]
+	if ok {
+		if !m.pattern.Match(msg.Line) && len(r.msg.Line)+1+len(msg.Line) <= m.maxSize {
+			r.msg.Line = append(append(r.msg.Line, '\n'), msg.Line...)
+			r.timer.Reset(m.timeout)
+			return nil
+		}
+		err = m.flush(msg.Source)
+	}
+
+	// The line is copied, as it is appended to.
+	record := *msg
+	record.Line = append(make([]byte, 0, len(msg.Line)), msg.Line...)
+	source := msg.Source
+	m.records[source] = &multilineRecord{
+		msg: &record,
+		timer: time.AfterFunc(m.timeout, func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if r, ok := m.records[source]; ok && r.msg == &record {
+				if err := m.flush(source); err != nil {
+					logrus.Errorf("Failed to log msg %q for logger %s: %s", r.msg.Line, m.l.Name(), err)
+				}
+			}
+		}),
+	}
+	return err
+}
+
+// flush logs the pending record of the source. It is called with the lock
+// held.
+func (m *multilineLogger) flush(source string) error {
+	r := m.records[source]
+	delete(m.records, source)
+	r.timer.Stop()
+	return m.l.Log(r.msg)
+}
+
+// Name returns the name of the wrapped logger.
+func (m *multilineLogger) Name() string {
+	return m.l.Name()
+}
+
+// Close logs the pending records, and closes the wrapped logger.
+func (m *multilineLogger) Close() error {
+	m.mu.Lock()
+	for source, r := range m.records {
+		if err := m.flush(source); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", r.msg.Line, m.l.Name(), err)
+		}
+	}
+	m.closed = true
+	m.mu.Unlock()
+	return m.l.Close()
+}
+
+func validateMultilineOpts(cfg map[string]string) error {
+	pattern, ok := cfg["multiline-pattern"]
+	if !ok {
+		for _, key := range []string{"multiline-flush-timeout", "multiline-max-size"} {
+			if _, ok := cfg[key]; ok {
+				return fmt.Errorf("logger: %s option is only supported with multiline-pattern", key)
+			}
+		}
+		return nil
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("logger: invalid multiline-pattern %s: %v", pattern, err)
+	}
+	if s, ok := cfg["multiline-flush-timeout"]; ok {
+		if d, err := time.ParseDuration(s); err != nil || d <= 0 {
+			return fmt.Errorf("logger: invalid multiline-flush-timeout %s", s)
+		}
+	}
+	if s, ok := cfg["multiline-max-size"]; ok {
+		if size, err := units.RAMInBytes(s); err != nil || size <= 0 {
+			return fmt.Errorf("logger: invalid multiline-max-size %s", s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureLogger records the lines of the messages it logs.
+type captureLogger struct {
+	mu     sync.Mutex
+	logged []string
+	closed bool
+}
+
+func (l *captureLogger) Log(msg *Message) error {
+	l.mu.Lock()
+	l.logged = append(l.logged, msg.Source+":"+string(msg.Line))
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *captureLogger) Name() string {
+	return "capture"
+}
+
+func (l *captureLogger) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *captureLogger) lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.logged...)
+}
+
+func TestMultilineLoggerMergesRecords(t *testing.T) {
+	c := &captureLogger{}
+	l, err := NewMultilineLogger(c, map[string]string{
+		"multiline-pattern":       `^\d{4}-`,
+		"multiline-flush-timeout": "1h",
+		"multiline-max-size":      "64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []*Message{
+		{Source: "stdout", Line: []byte("2015-09-01 Exception in thread main")},
+		{Source: "stderr", Line: []byte("not a record")},
+		{Source: "stdout", Line: []byte("  at Main.java")},
+		{Source: "stdout", Line: []byte("2015-09-01 started")},
+		{Source: "stdout", Line: []byte("  a continuation line longer than the rest of the record")},
+	} {
+		if err := l.Log(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The record larger than the maximum size is logged when it would grow.
+	expected := []string{
+		"stdout:2015-09-01 Exception in thread main\n  at Main.java",
+		"stdout:2015-09-01 started",
+	}
+	if lines := c.lines(); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("Expected %q, got %q", expected, lines)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := c.lines()
+	if len(lines) != 4 || !c.closed {
+		t.Fatalf("Expected the pending records to be logged on close, got %q", lines)
+	}
+}
+
+func TestMultilineLoggerFlushTimeout(t *testing.T) {
+	c := &captureLogger{}
+	l, err := NewMultilineLogger(c, map[string]string{
+		"multiline-pattern":       `^\S`,
+		"multiline-flush-timeout": "10ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Log(&Message{Source: "stdout", Line: []byte("panic: boom")})
+	l.Log(&Message{Source: "stdout", Line: []byte("\tmain.go:12")})
+
+	expected := []string{"stdout:panic: boom\n\tmain.go:12"}
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if lines := c.lines(); reflect.DeepEqual(lines, expected) {
+			return
+		}
+	}
+	t.Fatalf("Expected %q to be logged after the flush timeout, got %q", expected, c.lines())
+}
+
+func TestValidateMultilineOpts(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"multiline-pattern": "("},
+		{"multiline-max-size": "1k"},
+		{"multiline-pattern": "^a", "multiline-flush-timeout": "-1s"},
+		{"multiline-pattern": "^a", "multiline-max-size": "big"},
+	} {
+		if err := validateMultilineOpts(cfg); err == nil {
+			t.Fatalf("Expected an error validating %v", cfg)
+		}
+	}
+	if err := validateMultilineOpts(map[string]string{"multiline-pattern": "^a", "multiline-flush-timeout": "2s", "multiline-max-size": "1m"}); err != nil {
+		t.Fatal(err)
+	}
+}
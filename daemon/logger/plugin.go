@@ -0,0 +1,273 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types/plugins/logdriver"
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/pkg/stringid"
+)
+
+const (
+	// extName is the name of the plugins implementing log drivers.
+	extName = "LogDriver"
+
+	// pluginQueueSize is the number of entries waiting to be written to the
+	// FIFO of a plugin, beyond which the entries are dropped rather than
+	// blocking the copier of the container.
+	pluginQueueSize = 1024
+)
+
+var (
+	// pluginCloseTimeout is the time left to a plugin to read the entries
+	// queued when the logger is closed.
+	pluginCloseTimeout = 10 * time.Second
+
+	errPluginLoggerClosed = errors.New("logger: the plugin logger is closed")
+)
+
+// getPlugin returns the creator of the loggers of the LogDriver plugin name.
+// Unlike the volume plugins, the plugin must be installed already: an
+// unknown log driver is reported without waiting.
+func getPlugin(name string) (Creator, error) {
+	pl, err := plugins.GetWithoutRetry(name, extName)
+	if err != nil {
+		return nil, err
+	}
+	return makePluginCreator(name, &logPluginProxy{pl.Client}), nil
+}
+
+// getPluginLogOptValidator returns a validator forwarding the options to the
+// LogDriver plugin name, or nil if there is no such plugin.
+func getPluginLogOptValidator(name string) LogOptValidator {
+	pl, err := plugins.GetWithoutRetry(name, extName)
+	if err != nil {
+		return nil
+	}
+	proxy := &logPluginProxy{pl.Client}
+	return proxy.ValidateLogOpts
+}
+
+func makePluginCreator(name string, proxy *logPluginProxy) Creator {
+	return func(ctx Context) (Logger, error) {
+		path := filepath.Join(pluginFifoDir, ctx.ContainerID+"-"+stringid.TruncateID(stringid.GenerateNonCryptoID()))
+		if err := createPluginFifo(path); err != nil {
+			return nil, err
+		}
+		// The FIFO is opened while the plugin is told to start logging, as
+		// it may open the FIFO before responding.
+		stop := make(chan struct{})
+		opened := make(chan error, 1)
+		var f io.WriteCloser
+		go func() {
+			var err error
+			f, err = openPluginFifo(path, stop)
+			opened <- err
+		}()
+		if err := proxy.StartLogging(path, ctx); err != nil {
+			close(stop)
+			if <-opened == nil {
+				f.Close()
+			}
+			os.Remove(path)
+			return nil, err
+		}
+		if err := <-opened; err != nil {
+			proxy.StopLogging(path)
+			os.Remove(path)
+			return nil, err
+		}
+
+		a := &pluginAdapter{
+			driverName: name,
+			proxy:      proxy,
+			path:       path,
+			ctx:        ctx,
+			f:          f,
+			enc:        logdriver.NewLogEntryEncoder(f),
+			queue:      make(chan *logdriver.LogEntry, pluginQueueSize),
+			stop:       stop,
+			done:       make(chan struct{}),
+		}
+		go a.run()
+
+		capability, err := proxy.Capabilities()
+		if err != nil {
+			logrus.Debugf("Failed to get the capabilities of log driver %s: %v", name, err)
+		}
+		if capability.ReadLogs {
+			return &pluginAdapterWithRead{a}, nil
+		}
+		return a, nil
+	}
+}
+
+// pluginAdapter is the logger of a container whose entries are sent to a
+// LogDriver plugin over a FIFO. The entries are queued and written by a
+// goroutine of their own, so that a plugin which is slow or went away does
+// not block the container. The goroutine owns the FIFO, and closes it once
+// it is done.
+type pluginAdapter struct {
+	driverName string
+	proxy      *logPluginProxy
+	path       string
+	ctx        Context
+	f          io.WriteCloser
+	enc        *logdriver.LogEntryEncoder
+	queue      chan *logdriver.LogEntry
+	stop       chan struct{} // stop aborts a write waiting for the plugin
+	done       chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	dropped int
+}
+
+// Log queues the message to be written to the FIFO, dropping it if the queue
+// is full.
+func (a *pluginAdapter) Log(msg *Message) error {
+	entry := &logdriver.LogEntry{
+		Source:   msg.Source,
+		TimeNano: msg.Timestamp.UnixNano(),
+		Line:     msg.Line,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return errPluginLoggerClosed
+	}
+	select {
+	case a.queue <- entry:
+	default:
+		if a.dropped == 0 {
+			logrus.Warnf("Log driver %s does not keep up with container %s, dropping its messages", a.driverName, a.ctx.ContainerID)
+		}
+		a.dropped++
+	}
+	return nil
+}
+
+// run writes the queued entries to the FIFO until the queue is closed, and
+// closes the FIFO. The entries are dropped once a write failed, as when the
+// plugin crashed or stopped reading.
+func (a *pluginAdapter) run() {
+	defer close(a.done)
+	defer a.f.Close()
+	var failed bool
+	for entry := range a.queue {
+		if failed {
+			continue
+		}
+		if err := a.enc.Encode(entry); err != nil {
+			if err != errPluginLoggerClosed {
+				logrus.Errorf("Failed to send the logs of container %s to log driver %s: %v", a.ctx.ContainerID, a.driverName, err)
+			}
+			failed = true
+		}
+	}
+}
+
+// Name returns the name of the plugin.
+func (a *pluginAdapter) Name() string {
+	return a.driverName
+}
+
+// Close writes the queued entries, waiting for the plugin to read them for
+// a while, closes the FIFO and tells the plugin to stop logging.
+func (a *pluginAdapter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.queue)
+	dropped := a.dropped
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+	case <-time.After(pluginCloseTimeout):
+		logrus.Warnf("Log driver %s did not read the last messages of container %s", a.driverName, a.ctx.ContainerID)
+		// The pending write is aborted and the remaining entries are
+		// dropped, the FIFO is then closed straight away.
+		close(a.stop)
+		<-a.done
+	}
+	if dropped > 0 {
+		logrus.Warnf("Log driver %s dropped %d messages of container %s", a.driverName, dropped, a.ctx.ContainerID)
+	}
+
+	err := a.proxy.StopLogging(a.path)
+	if rmErr := os.Remove(a.path); rmErr != nil && !os.IsNotExist(rmErr) {
+		logrus.Debugf("Failed to remove the FIFO of log driver %s: %v", a.driverName, rmErr)
+	}
+	return err
+}
+
+// pluginAdapterWithRead is the logger of a LogDriver plugin implementing
+// /LogDriver.ReadLogs.
+type pluginAdapterWithRead struct {
+	*pluginAdapter
+}
+
+// ReadLogs reads the logs of the container back from the plugin.
+func (a *pluginAdapterWithRead) ReadLogs(config ReadConfig) *LogWatcher {
+	watcher := NewLogWatcher()
+
+	go func() {
+		defer close(watcher.Msg)
+
+		stream, err := a.proxy.ReadLogs(a.ctx, config)
+		if err != nil {
+			watcher.Err <- err
+			return
+		}
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			// A follower waits for the entries, the stream is closed to
+			// stop it.
+			select {
+			case <-watcher.WatchClose():
+			case <-done:
+			}
+			stream.Close()
+		}()
+
+		dec := logdriver.NewLogEntryDecoder(stream)
+		for {
+			var entry logdriver.LogEntry
+			if err := dec.Decode(&entry); err != nil {
+				select {
+				case <-watcher.WatchClose():
+				default:
+					if err != io.EOF {
+						watcher.Err <- err
+					}
+				}
+				return
+			}
+			msg := &Message{
+				ContainerID: a.ctx.ContainerID,
+				Line:        append(entry.Line, '\n'),
+				Source:      entry.Source,
+				Timestamp:   time.Unix(0, entry.TimeNano).UTC(),
+			}
+			select {
+			case watcher.Msg <- msg:
+			case <-watcher.WatchClose():
+				return
+			}
+		}
+	}()
+
+	return watcher
+}
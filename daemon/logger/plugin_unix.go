@@ -0,0 +1,108 @@
+// +build !windows
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// pluginFifoDir is the directory of the FIFOs the entries are sent to the
+// LogDriver plugins over.
+var pluginFifoDir = "/run/docker/logging"
+
+var (
+	// pluginOpenTimeout is the time left to a plugin to open the FIFO for
+	// reading once it is told to start logging.
+	pluginOpenTimeout = 10 * time.Second
+	// pluginWriteTimeout is the time left to a plugin which does not read
+	// the FIFO to make room for an entry before it is deemed to have failed.
+	pluginWriteTimeout = 10 * time.Second
+	// pluginFifoPollInterval is how often the FIFO is checked while waiting
+	// for the plugin.
+	pluginFifoPollInterval = 10 * time.Millisecond
+
+	errPluginNotReading = errors.New("logger: the plugin does not read the FIFO")
+)
+
+// createPluginFifo creates the FIFO at path, which the plugin opens for
+// reading when it is told to start logging.
+func createPluginFifo(path string) error {
+	if err := os.MkdirAll(pluginFifoDir, 0700); err != nil {
+		return err
+	}
+	return syscall.Mkfifo(path, 0600)
+}
+
+// openPluginFifo opens the FIFO at path for writing once the plugin opened
+// it for reading, or fails once stop is closed. It is opened in non-blocking
+// mode, so that the writes fail once the plugin went away instead of
+// blocking, and only wait for a plugin which does not read for
+// pluginWriteTimeout, or until stop is closed.
+func openPluginFifo(path string, stop <-chan struct{}) (io.WriteCloser, error) {
+	deadline := time.Now().Add(pluginOpenTimeout)
+	for {
+		fd, err := syscall.Open(path, syscall.O_WRONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+		if err == nil {
+			return &pluginFifo{fd: fd, stop: stop}, nil
+		}
+		// ENXIO is returned while the FIFO has no reader.
+		if err != syscall.ENXIO {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("logger: the plugin did not open the FIFO %s", path)
+		}
+		select {
+		case <-stop:
+			return nil, errPluginLoggerClosed
+		case <-time.After(pluginFifoPollInterval):
+		}
+	}
+}
+
+// pluginFifo is the non-blocking write end of the FIFO of a plugin. It is
+// written and closed by a single goroutine.
+type pluginFifo struct {
+	fd   int
+	stop <-chan struct{}
+}
+
+// Write writes b to the FIFO, waiting for the plugin to make room for it if
+// the FIFO is full. It fails with EPIPE once the plugin closed the FIFO, as
+// when it crashed.
+func (f *pluginFifo) Write(b []byte) (int, error) {
+	var written int
+	deadline := time.Now().Add(pluginWriteTimeout)
+	for written < len(b) {
+		n, err := syscall.Write(f.fd, b[written:])
+		if n > 0 {
+			written += n
+			deadline = time.Now().Add(pluginWriteTimeout)
+		}
+		switch err {
+		case nil, syscall.EINTR:
+		case syscall.EAGAIN:
+			if time.Now().After(deadline) {
+				return written, errPluginNotReading
+			}
+			select {
+			case <-f.stop:
+				return written, errPluginLoggerClosed
+			case <-time.After(pluginFifoPollInterval):
+			}
+		default:
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Close closes the FIFO, the plugin then reads EOF.
+func (f *pluginFifo) Close() error {
+	return syscall.Close(f.fd)
+}
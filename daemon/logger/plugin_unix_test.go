@@ -0,0 +1,303 @@
+// +build !windows
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/plugins/logdriver"
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/pkg/tlsconfig"
+)
+
+// testPluginMode is how a testLogPlugin handles the FIFOs.
+type testPluginMode int
+
+const (
+	pluginReads      testPluginMode = iota
+	pluginStalls                    // the FIFOs are opened but not read
+	pluginCrashes                   // the FIFOs are closed once opened
+	pluginNeverOpens                // the FIFOs are not opened
+)
+
+// testLogPlugin is a LogDriver plugin keeping the entries it reads in memory.
+type testLogPlugin struct {
+	server  *httptest.Server
+	mode    testPluginMode
+	mu      sync.Mutex
+	entries []logdriver.LogEntry
+	stopped []string
+	stalled []*os.File
+	readers sync.WaitGroup
+}
+
+func newTestLogPlugin(t *testing.T, mode testPluginMode) *testLogPlugin {
+	p := &testLogPlugin{mode: mode}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/LogDriver.StartLogging", func(w http.ResponseWriter, r *http.Request) {
+		var req logPluginProxyStartLoggingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		if p.mode != pluginNeverOpens {
+			// The open returns once the daemon opened the FIFO for
+			// writing.
+			f, err := os.Open(req.File)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			switch p.mode {
+			case pluginStalls:
+				p.mu.Lock()
+				p.stalled = append(p.stalled, f)
+				p.mu.Unlock()
+			case pluginCrashes:
+				f.Close()
+			default:
+				p.readers.Add(1)
+				go func() {
+					defer p.readers.Done()
+					defer f.Close()
+					dec := logdriver.NewLogEntryDecoder(f)
+					for {
+						var entry logdriver.LogEntry
+						if err := dec.Decode(&entry); err != nil {
+							return
+						}
+						p.mu.Lock()
+						p.entries = append(p.entries, entry)
+						p.mu.Unlock()
+					}
+				}()
+			}
+		}
+		json.NewEncoder(w).Encode(logPluginProxyResponse{})
+	})
+	mux.HandleFunc("/LogDriver.StopLogging", func(w http.ResponseWriter, r *http.Request) {
+		var req logPluginProxyStopLoggingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		p.mu.Lock()
+		p.stopped = append(p.stopped, req.File)
+		p.mu.Unlock()
+		json.NewEncoder(w).Encode(logPluginProxyResponse{})
+	})
+	mux.HandleFunc("/LogDriver.Capabilities", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(logPluginProxyCapabilitiesResponse{Cap: Capability{ReadLogs: true}})
+	})
+	mux.HandleFunc("/LogDriver.ValidateLogOpts", func(w http.ResponseWriter, r *http.Request) {
+		var req logPluginProxyValidateLogOptsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		var resp logPluginProxyResponse
+		for k := range req.Config {
+			if k != "endpoint" {
+				resp.Err = fmt.Sprintf("unknown log opt '%s'", k)
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/LogDriver.ReadLogs", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		enc := logdriver.NewLogEntryEncoder(w)
+		for i := range p.entries {
+			enc.Encode(&p.entries[i])
+		}
+	})
+	p.server = httptest.NewServer(mux)
+	return p
+}
+
+func (p *testLogPlugin) proxy(t *testing.T) *logPluginProxy {
+	c, err := plugins.NewClient(p.server.URL, tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &logPluginProxy{c}
+}
+
+func setupPluginFifoDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "logging-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := pluginFifoDir
+	pluginFifoDir = dir
+	return func() {
+		pluginFifoDir = orig
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPluginLogger(t *testing.T) {
+	defer setupPluginFifoDir(t)()
+	p := newTestLogPlugin(t, pluginReads)
+	defer p.server.Close()
+
+	l, err := makePluginCreator("test", p.proxy(t))(Context{ContainerID: "cid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name() != "test" {
+		t.Fatalf("Expected the name of the plugin, got %s", l.Name())
+	}
+	now := time.Now().UTC()
+	for i := 0; i < 3; i++ {
+		if err := l.Log(&Message{ContainerID: "cid", Line: []byte(fmt.Sprintf("line %d", i)), Source: "stdout", Timestamp: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p.readers.Wait()
+
+	if len(p.entries) != 3 || string(p.entries[2].Line) != "line 2" || p.entries[2].Source != "stdout" || p.entries[2].TimeNano != now.UnixNano() {
+		t.Fatalf("Expected the 3 lines to be read by the plugin, got %+v", p.entries)
+	}
+	if len(p.stopped) != 1 || !strings.HasPrefix(p.stopped[0], pluginFifoDir) {
+		t.Fatalf("Expected the plugin to stop logging, got %v", p.stopped)
+	}
+	if _, err := os.Stat(p.stopped[0]); !os.IsNotExist(err) {
+		t.Fatalf("Expected the FIFO to be removed, got %v", err)
+	}
+	if err := l.Log(&Message{Line: []byte("closed")}); err != errPluginLoggerClosed {
+		t.Fatalf("Expected an error logging to a closed logger, got %v", err)
+	}
+
+	// The plugin implements ReadLogs.
+	reader, ok := l.(LogReader)
+	if !ok {
+		t.Fatal("Expected the logger to read the logs back")
+	}
+	watcher := reader.ReadLogs(ReadConfig{Tail: -1})
+	var lines []string
+	for msg := range watcher.Msg {
+		if msg.ContainerID != "cid" || !msg.Timestamp.Equal(now) {
+			t.Fatalf("Unexpected message %+v", msg)
+		}
+		lines = append(lines, string(msg.Line))
+	}
+	if strings.Join(lines, "") != "line 0\nline 1\nline 2\n" {
+		t.Fatalf("Expected the lines logged, got %q", lines)
+	}
+}
+
+// logLines logs n lines of 1KB to l, failing if it blocks.
+func logLines(t *testing.T, l Logger, n int) {
+	done := make(chan error)
+	go func() {
+		line := []byte(strings.Repeat("a", 1024))
+		for i := 0; i < n; i++ {
+			if err := l.Log(&Message{ContainerID: "cid", Line: line, Source: "stdout", Timestamp: time.Now()}); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Logging to the plugin blocked")
+	}
+}
+
+// closeWithin closes l, failing if it takes longer than timeout.
+func closeWithin(t *testing.T, l Logger, timeout time.Duration) {
+	start := time.Now()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > timeout {
+		t.Fatalf("Expected the logger to be closed within %v, took %v", timeout, d)
+	}
+}
+
+func TestPluginLoggerDoesNotBlock(t *testing.T) {
+	defer setupPluginFifoDir(t)()
+	p := newTestLogPlugin(t, pluginStalls)
+	defer p.server.Close()
+	defer func(timeout time.Duration) { pluginCloseTimeout = timeout }(pluginCloseTimeout)
+	pluginCloseTimeout = 100 * time.Millisecond
+
+	l, err := makePluginCreator("test", p.proxy(t))(Context{ContainerID: "cid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Far more than the FIFO and the queue hold.
+	logLines(t, l, 4*pluginQueueSize)
+
+	// The write waiting for the plugin is aborted.
+	closeWithin(t, l, 5*time.Second)
+	for _, f := range p.stalled {
+		f.Close()
+	}
+}
+
+func TestPluginLoggerCrashedPlugin(t *testing.T) {
+	defer setupPluginFifoDir(t)()
+	p := newTestLogPlugin(t, pluginCrashes)
+	defer p.server.Close()
+
+	l, err := makePluginCreator("test", p.proxy(t))(Context{ContainerID: "cid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The writes fail once the plugin closed the FIFO, and the entries are
+	// dropped without waiting for it.
+	logLines(t, l, 4*pluginQueueSize)
+	closeWithin(t, l, 5*time.Second)
+}
+
+func TestPluginLoggerFifoNotOpened(t *testing.T) {
+	defer setupPluginFifoDir(t)()
+	p := newTestLogPlugin(t, pluginNeverOpens)
+	defer p.server.Close()
+	defer func(timeout time.Duration) { pluginOpenTimeout = timeout }(pluginOpenTimeout)
+	pluginOpenTimeout = 100 * time.Millisecond
+
+	if _, err := makePluginCreator("test", p.proxy(t))(Context{ContainerID: "cid"}); err == nil {
+		t.Fatal("Expected an error when the plugin does not open the FIFO")
+	}
+	if len(p.stopped) != 1 {
+		t.Fatalf("Expected the plugin to stop logging, got %v", p.stopped)
+	}
+	if _, err := os.Stat(p.stopped[0]); !os.IsNotExist(err) {
+		t.Fatalf("Expected the FIFO to be removed, got %v", err)
+	}
+}
+
+func TestPluginValidateLogOpts(t *testing.T) {
+	p := newTestLogPlugin(t, pluginReads)
+	defer p.server.Close()
+	proxy := p.proxy(t)
+
+	if err := proxy.ValidateLogOpts(map[string]string{"endpoint": "localhost"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.ValidateLogOpts(map[string]string{"foo": "bar"}); err == nil || err.Error() != "unknown log opt 'foo'" {
+		t.Fatalf("Expected the error of the plugin, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+// +build windows
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+var pluginFifoDir = ""
+
+var errPluginsNotSupported = errors.New("logger: log driver plugins are not supported on this platform")
+
+func createPluginFifo(path string) error {
+	return errPluginsNotSupported
+}
+
+func openPluginFifo(path string, stop <-chan struct{}) (io.WriteCloser, error) {
+	return nil, errPluginsNotSupported
+}
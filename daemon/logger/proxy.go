@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+type client interface {
+	Call(string, interface{}, interface{}) error
+	CallWithoutRetry(string, interface{}, interface{}) error
+	Stream(string, interface{}) (io.ReadCloser, error)
+}
+
+// logPluginProxy calls the methods of a LogDriver plugin. It is written by
+// hand since ReadLogs streams its result.
+type logPluginProxy struct {
+	client
+}
+
+type logPluginProxyStartLoggingRequest struct {
+	File string
+	Info Context
+}
+
+type logPluginProxyStopLoggingRequest struct {
+	File string
+}
+
+type logPluginProxyValidateLogOptsRequest struct {
+	Config map[string]string
+}
+
+type logPluginProxyReadLogsRequest struct {
+	Info   Context
+	Config ReadConfig
+}
+
+type logPluginProxyResponse struct {
+	Err string
+}
+
+// Capability lists the optional features a LogDriver plugin implements.
+type Capability struct {
+	// ReadLogs is true if the plugin implements /LogDriver.ReadLogs, for
+	// docker logs to read the logs back.
+	ReadLogs bool
+}
+
+type logPluginProxyCapabilitiesResponse struct {
+	Cap Capability
+	Err string
+}
+
+// StartLogging asks the plugin to read the entries logged by the container
+// of info from the FIFO at file.
+func (pp *logPluginProxy) StartLogging(file string, info Context) error {
+	var ret logPluginProxyResponse
+	if err := pp.Call("LogDriver.StartLogging", logPluginProxyStartLoggingRequest{File: file, Info: info}, &ret); err != nil {
+		return err
+	}
+	return respErr(ret.Err)
+}
+
+// StopLogging tells the plugin that no more entries are written to the FIFO
+// at file. It does not wait for a plugin which went away.
+func (pp *logPluginProxy) StopLogging(file string) error {
+	var ret logPluginProxyResponse
+	if err := pp.CallWithoutRetry("LogDriver.StopLogging", logPluginProxyStopLoggingRequest{File: file}, &ret); err != nil {
+		return err
+	}
+	return respErr(ret.Err)
+}
+
+// ValidateLogOpts asks the plugin to check the log options of a container.
+func (pp *logPluginProxy) ValidateLogOpts(config map[string]string) error {
+	var ret logPluginProxyResponse
+	if err := pp.Call("LogDriver.ValidateLogOpts", logPluginProxyValidateLogOptsRequest{Config: config}, &ret); err != nil {
+		return err
+	}
+	return respErr(ret.Err)
+}
+
+// Capabilities returns the optional features the plugin implements.
+func (pp *logPluginProxy) Capabilities() (Capability, error) {
+	var ret logPluginProxyCapabilitiesResponse
+	if err := pp.Call("LogDriver.Capabilities", nil, &ret); err != nil {
+		return Capability{}, err
+	}
+	return ret.Cap, respErr(ret.Err)
+}
+
+// ReadLogs returns the stream of the entries the plugin logged for the
+// container of info, encoded like on the FIFO.
+func (pp *logPluginProxy) ReadLogs(info Context, config ReadConfig) (io.ReadCloser, error) {
+	return pp.Stream("LogDriver.ReadLogs", logPluginProxyReadLogsRequest{Info: info, Config: config})
+}
+
+func respErr(err string) error {
+	if err != "" {
+		return errors.New(err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// ModeBlocking is the default delivery mode, in which the copier of a
+	// container waits for each message to be logged.
+	ModeBlocking = "blocking"
+	// ModeNonBlock is the delivery mode in which the messages are queued in
+	// a ring buffer, the oldest ones being dropped when it is full.
+	ModeNonBlock = "non-blocking"
+
+	// DefaultMaxBufferSize is the default size of the ring buffer, in bytes
+	// of the lines queued.
+	DefaultMaxBufferSize = 1024 * 1024
+)
+
+// ringCloseTimeout is the time a RingLogger is given to log the messages left
+// in its buffer when it is closed, the ones still queued then are dropped.
+var ringCloseTimeout = 10 * time.Second
+
+var errRingClosed = errors.New("logger: the ring buffer is closed")
+
+// DropCounter is implemented by the loggers which drop messages rather than
+// block the container.
+type DropCounter interface {
+	// DroppedMessages returns the number of messages dropped so far.
+	DroppedMessages() int64
+}
+
+// RingLogger queues the messages in a ring buffer bounded by the size of
+// their lines, and logs them to the wrapped logger from a goroutine of its
+// own. The copier of the container never waits for a slow logger, the
+// oldest messages are dropped instead when the buffer is full.
+type RingLogger struct {
+	l       Logger
+	ring    *messageRing
+	dropped int64
+	done    chan struct{}
+}
+
+// ringWithReader is a RingLogger whose logger reads the logs back.
+type ringWithReader struct {
+	*RingLogger
+}
+
+// ReadLogs reads the logs from the wrapped logger.
+func (r *ringWithReader) ReadLogs(config ReadConfig) *LogWatcher {
+	return r.l.(LogReader).ReadLogs(config)
+}
+
+// NewRingLogger returns a logger queuing up to maxSize bytes of messages to
+// be logged to l. It reads the logs back if l does.
+func NewRingLogger(l Logger, maxSize int64) Logger {
+	r := &RingLogger{
+		l:    l,
+		ring: newMessageRing(maxSize),
+		done: make(chan struct{}),
+	}
+	go r.run()
+	if _, ok := l.(LogReader); ok {
+		return &ringWithReader{r}
+	}
+	return r
+}
+
+// Log queues the message, dropping the oldest ones if the buffer is full.
+func (r *RingLogger) Log(msg *Message) error {
+	dropped, err := r.ring.Enqueue(msg)
+	if dropped > 0 {
+		if atomic.AddInt64(&r.dropped, int64(dropped)) == int64(dropped) {
+			logrus.Warnf("Log driver %s does not keep up with container %s, dropping its oldest messages", r.l.Name(), msg.ContainerID)
+		}
+	}
+	return err
+}
+
+// Name returns the name of the wrapped logger.
+func (r *RingLogger) Name() string {
+	return r.l.Name()
+}
+
+// DroppedMessages returns the number of messages dropped since the logger
+// was created.
+func (r *RingLogger) DroppedMessages() int64 {
+	return atomic.LoadInt64(&r.dropped)
+}
+
+// Close logs the messages left in the buffer, and closes the wrapped logger.
+// The messages not logged within ringCloseTimeout are dropped, and counted as
+// such. Close then returns without waiting for the message being logged, if
+// any, and the wrapped logger is closed once it is logged.
+func (r *RingLogger) Close() error {
+	r.ring.Close()
+	select {
+	case <-r.done:
+	case <-time.After(ringCloseTimeout):
+		if dropped := r.ring.Drain(); dropped > 0 {
+			atomic.AddInt64(&r.dropped, int64(dropped))
+			logrus.Warnf("Log driver %s did not log the last %d messages in time, dropping them", r.l.Name(), dropped)
+		}
+		go func() {
+			<-r.done
+			if err := r.l.Close(); err != nil {
+				logrus.Errorf("Failed to close logger %s: %v", r.l.Name(), err)
+			}
+		}()
+		return nil
+	}
+	return r.l.Close()
+}
+
+func (r *RingLogger) run() {
+	defer close(r.done)
+	for {
+		msg, err := r.ring.Dequeue()
+		if err != nil {
+			return
+		}
+		if err := r.l.Log(msg); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", msg.Line, r.l.Name(), err)
+		}
+	}
+}
+
+// messageRing is a queue of messages bounded by the size of their lines.
+type messageRing struct {
+	mu       sync.Mutex
+	wait     *sync.Cond
+	size     int64
+	maxSize  int64
+	messages []*Message
+	closed   bool
+}
+
+func newMessageRing(maxSize int64) *messageRing {
+	r := &messageRing{maxSize: maxSize}
+	r.wait = sync.NewCond(&r.mu)
+	return r
+}
+
+// Enqueue adds the message to the ring, removing the oldest ones until it
+// fits, and returns the number of messages removed. A message larger than
+// the ring is queued alone.
+func (r *messageRing) Enqueue(msg *Message) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, errRingClosed
+	}
+
+	var dropped int
+	size := int64(len(msg.Line))
+	for len(r.messages) > 0 && r.size+size > r.maxSize {
+		r.size -= int64(len(r.messages[0].Line))
+		r.messages[0] = nil
+		r.messages = r.messages[1:]
+		dropped++
+	}
+	r.messages = append(r.messages, msg)
+	r.size += size
+	r.wait.Signal()
+	return dropped, nil
+}
+
+// Dequeue removes the oldest message of the ring, waiting for one if it is
+// empty. Once the ring is closed, it returns the messages left, then an
+// error.
+func (r *messageRing) Dequeue() (*Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.messages) == 0 && !r.closed {
+		r.wait.Wait()
+	}
+	if len(r.messages) == 0 {
+		return nil, errRingClosed
+	}
+	msg := r.messages[0]
+	r.messages[0] = nil
+	r.messages = r.messages[1:]
+	r.size -= int64(len(msg.Line))
+	return msg, nil
+}
+
+// Drain removes the messages left in the ring, and returns their number.
+func (r *messageRing) Drain() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.messages)
+	r.messages = nil
+	r.size = 0
+	return n
+}
+
+// Close stops the ring from accepting messages.
+func (r *messageRing) Close() {
+	r.mu.Lock()
+	r.closed = true
+	r.wait.Broadcast()
+	r.mu.Unlock()
+}
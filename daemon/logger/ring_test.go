@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingLogger is a logger blocking until it is released.
+type blockingLogger struct {
+	release chan struct{}
+	mu      sync.Mutex
+	logged  []string
+	closed  bool
+}
+
+func (l *blockingLogger) Log(msg *Message) error {
+	<-l.release
+	l.mu.Lock()
+	l.logged = append(l.logged, string(msg.Line))
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *blockingLogger) Name() string {
+	return "blocking"
+}
+
+func (l *blockingLogger) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	return nil
+}
+
+func TestMessageRingDropsOldest(t *testing.T) {
+	r := newMessageRing(10)
+	for i, expected := range []int{0, 0, 1} {
+		if dropped, err := r.Enqueue(&Message{Line: []byte(fmt.Sprintf("line%d", i))}); err != nil || dropped != expected {
+			t.Fatalf("Expected %d messages dropped enqueuing line%d, got %d, %v", expected, i, dropped, err)
+		}
+	}
+	// A message larger than the ring is queued alone.
+	if dropped, _ := r.Enqueue(&Message{Line: []byte("a line larger than the ring")}); dropped != 2 {
+		t.Fatalf("Expected the other messages to be dropped, got %d dropped", dropped)
+	}
+	r.Close()
+	if _, err := r.Enqueue(&Message{}); err != errRingClosed {
+		t.Fatalf("Expected the closed ring to refuse messages, got %v", err)
+	}
+
+	msg, err := r.Dequeue()
+	if err != nil || string(msg.Line) != "a line larger than the ring" {
+		t.Fatalf("Expected the large message, got %v, %v", msg, err)
+	}
+	if _, err := r.Dequeue(); err != errRingClosed {
+		t.Fatalf("Expected the closed ring to be empty, got %v", err)
+	}
+}
+
+func TestRingLoggerDoesNotBlock(t *testing.T) {
+	bl := &blockingLogger{release: make(chan struct{})}
+	l := NewRingLogger(bl, 100)
+	if _, ok := l.(LogReader); ok {
+		t.Fatal("Expected the ring logger not to read logs the wrapped logger does not read")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			l.Log(&Message{Line: []byte(fmt.Sprintf("line%02d", i))})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Logging to a blocked logger blocked")
+	}
+
+	close(bl.release)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bl.closed {
+		t.Fatal("Expected the wrapped logger to be closed")
+	}
+	// At most 100 bytes of lines are queued, the first one may have been
+	// dequeued before the logger blocked.
+	dropped := l.(DropCounter).DroppedMessages()
+	if dropped < 100-100/6-1 || int(dropped)+len(bl.logged) != 100 {
+		t.Fatalf("Expected the oldest messages to be dropped, got %d dropped and %d logged", dropped, len(bl.logged))
+	}
+	if bl.logged[len(bl.logged)-1] != "line99" {
+		t.Fatalf("Expected the last messages to be logged, got %v", bl.logged)
+	}
+}
+
+func TestValidateLogOptsMode(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"mode": "non-blocking", "max-buffer-size": "4m"},
+		{"mode": "blocking"},
+		{"mode": "non-blocking"},
+	} {
+		if err := ValidateLogOpts("none", cfg); err != nil {
+			t.Fatalf("Expected %v to be valid, got %v", cfg, err)
+		}
+	}
+	for _, cfg := range []map[string]string{
+		{"mode": "unknown"},
+		{"max-buffer-size": "4m"},
+		{"mode": "non-blocking", "max-buffer-size": "foo"},
+	} {
+		if err := ValidateLogOpts("none", cfg); err == nil {
+			t.Fatalf("Expected %v to be refused", cfg)
+		}
+	}
+
+	// The mode options are not passed to the validator of the driver.
+	if err := RegisterLogOptValidator("test-mode", func(cfg map[string]string) error {
+		if len(cfg) != 0 {
+			return fmt.Errorf("unknown log opts %v", cfg)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateLogOpts("test-mode", map[string]string{"mode": "non-blocking", "max-buffer-size": "1k"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRingLoggerCloseTimeout(t *testing.T) {
+	old := ringCloseTimeout
+	ringCloseTimeout = 10 * time.Millisecond
+	defer func() { ringCloseTimeout = old }()
+
+	bl := &blockingLogger{release: make(chan struct{})}
+	l := NewRingLogger(bl, 1024)
+	for i := 0; i < 10; i++ {
+		l.Log(&Message{Line: []byte(fmt.Sprintf("line%d", i))})
+	}
+
+	// Close does not wait for the message being logged when the timeout
+	// expires.
+	closed := make(chan error)
+	go func() {
+		closed <- l.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on a logger which never returns")
+	}
+
+	// The message being logged is still logged, and the logger closed then.
+	close(bl.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bl.mu.Lock()
+		logged, isClosed := len(bl.logged), bl.closed
+		bl.mu.Unlock()
+		if isClosed {
+			if dropped := l.(DropCounter).DroppedMessages(); dropped == 0 || int(dropped)+logged != 10 {
+				t.Fatalf("Expected the messages left to be dropped, got %d dropped and %d logged", dropped, logged)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("The logger was not closed once the message was logged")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
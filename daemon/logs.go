@@ -43,10 +43,15 @@ func (daemon *Daemon) ContainerLogs(container *Container, config *ContainerLogsC
 	}
 	config.OutStream = outStream
 
-	cLog, err := container.getLogger()
+	cLog, err := container.getLogReader()
 	if err != nil {
 		return err
 	}
+	// A logger created to read the logs of a stopped container is closed
+	// once they are read, for the plugins to stop logging.
+	if cLog != container.logDriver {
+		defer cLog.Close()
+	}
 	logReader, ok := cLog.(logger.LogReader)
 	if !ok {
 		return logger.ErrReadLogsNotSupported
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// countedContainerActions are the actions of the containers counted in the
+// metrics. The other ones, such as the exec and health events, have details
+// which would make too many label values.
+var countedContainerActions = map[string]bool{
+	"create":  true,
+	"start":   true,
+	"restart": true,
+	"stop":    true,
+	"kill":    true,
+	"die":     true,
+	"oom":     true,
+	"pause":   true,
+	"unpause": true,
+	"destroy": true,
+}
+
+var containerActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "engine_daemon_container_actions_total",
+	Help: "The number of actions of the containers, by action.",
+}, []string{"action"})
+
+func init() {
+	prometheus.MustRegister(containerActions)
+}
+
+var (
+	containerLabels = []string{"id", "name"}
+	interfaceLabels = []string{"id", "name", "interface"}
+
+	cpuDesc         = prometheus.NewDesc("engine_daemon_container_cpu_usage_seconds_total", "The CPU time used by the container.", containerLabels, nil)
+	memUsageDesc    = prometheus.NewDesc("engine_daemon_container_memory_usage_bytes", "The memory used by the container.", containerLabels, nil)
+	memLimitDesc    = prometheus.NewDesc("engine_daemon_container_memory_limit_bytes", "The memory limit of the container.", containerLabels, nil)
+	rxBytesDesc     = prometheus.NewDesc("engine_daemon_container_network_receive_bytes_total", "The bytes received by the container, by interface.", interfaceLabels, nil)
+	txBytesDesc     = prometheus.NewDesc("engine_daemon_container_network_transmit_bytes_total", "The bytes transmitted by the container, by interface.", interfaceLabels, nil)
+	blkReadDesc     = prometheus.NewDesc("engine_daemon_container_blkio_read_bytes_total", "The bytes read by the container from the block devices.", containerLabels, nil)
+	blkWriteDesc    = prometheus.NewDesc("engine_daemon_container_blkio_write_bytes_total", "The bytes written by the container to the block devices.", containerLabels, nil)
+	logDroppedDesc  = prometheus.NewDesc("engine_daemon_container_log_dropped_messages_total", "The messages the logger of the container dropped in non-blocking mode since it was started.", containerLabels, nil)
+	subscribersDesc = prometheus.NewDesc("engine_daemon_events_subscribers", "The number of subscribers to the events.", nil, nil)
+)
+
+// containerStats are the stats of a container collected for the metrics.
+type containerStats struct {
+	container *Container
+	stats     *execdriver.ResourceStats
+}
+
+func countContainerAction(action string) {
+	if countedContainerActions[action] {
+		containerActions.WithLabelValues(action).Inc()
+	}
+}
+
+// metricsCollector collects the metrics of the running containers, from the
+// stats the stats collector of the daemon last collected, and the number of
+// events subscribers.
+type metricsCollector struct {
+	daemon *Daemon
+}
+
+// RegisterMetrics makes the stats collector of the daemon collect the stats of
+// all the running containers, and registers the collector of their metrics.
+func (daemon *Daemon) RegisterMetrics() error {
+	daemon.statsCollector.collectRunning(func() []*Container {
+		var running []*Container
+		for _, c := range daemon.List() {
+			if c.IsRunning() {
+				running = append(running, c)
+			}
+		}
+		return running
+	})
+	return prometheus.Register(metricsCollector{daemon})
+}
+
+// Describe implements prometheus.Collector.
+func (m metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{cpuDesc, memUsageDesc, memLimitDesc, rxBytesDesc, txBytesDesc, blkReadDesc, blkWriteDesc, logDroppedDesc, subscribersDesc} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, rs := range m.daemon.statsCollector.snapshot() {
+		id, name := rs.container.ID, strings.TrimPrefix(rs.container.Name, "/")
+		stats := convertStatsToAPITypes(rs.stats.Stats)
+
+		ch <- prometheus.MustNewConstMetric(cpuDesc, prometheus.CounterValue, float64(stats.CPUStats.CPUUsage.TotalUsage)/float64(time.Second), id, name)
+		ch <- prometheus.MustNewConstMetric(memUsageDesc, prometheus.GaugeValue, float64(stats.MemoryStats.Usage), id, name)
+		if rs.stats.MemoryLimit > 0 {
+			ch <- prometheus.MustNewConstMetric(memLimitDesc, prometheus.GaugeValue, float64(rs.stats.MemoryLimit), id, name)
+		}
+		var ifaces []string
+		for iface := range stats.Networks {
+			ifaces = append(ifaces, iface)
+		}
+		sort.Strings(ifaces)
+		for _, iface := range ifaces {
+			ch <- prometheus.MustNewConstMetric(rxBytesDesc, prometheus.CounterValue, float64(stats.Networks[iface].RxBytes), id, name, iface)
+			ch <- prometheus.MustNewConstMetric(txBytesDesc, prometheus.CounterValue, float64(stats.Networks[iface].TxBytes), id, name, iface)
+		}
+		var read, write uint64
+		for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+			switch strings.ToLower(entry.Op) {
+			case "read":
+				read += entry.Value
+			case "write":
+				write += entry.Value
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(blkReadDesc, prometheus.CounterValue, float64(read), id, name)
+		ch <- prometheus.MustNewConstMetric(blkWriteDesc, prometheus.CounterValue, float64(write), id, name)
+
+		rs.container.Lock()
+		if dc, ok := rs.container.logDriver.(logger.DropCounter); ok {
+			ch <- prometheus.MustNewConstMetric(logDroppedDesc, prometheus.CounterValue, float64(dc.DroppedMessages()), id, name)
+		}
+		rs.container.Unlock()
+	}
+	ch <- prometheus.MustNewConstMetric(subscribersDesc, prometheus.GaugeValue, float64(m.daemon.EventsService.SubscribersCount()))
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"io"
 	"os/exec"
+	"strconv"
 	"sync"
 	"time"
 
@@ -53,6 +54,10 @@ type containerMonitor struct {
 
 	// lastStartTime is the time which the monitor last exec'd the container's process
 	lastStartTime time.Time
+
+	// restoring is set when the monitor starts by reattaching to a container
+	// which kept running while the daemon was down
+	restoring bool
 }
 
 // newContainerMonitor returns an initialized containerMonitor for the provided container
@@ -110,6 +115,15 @@ func (m *containerMonitor) Start() error {
 		afterRun bool
 	)
 
+	// remove the container once it exited for good, unless the daemon is
+	// shutting down, in which case it is removed when the daemon starts
+	// again. A failure to start is handled by ContainerStart, as the
+	// container is still locked then.
+	defer func() {
+		if afterRun && m.container.hostConfig.AutoRemove && !m.container.daemon.shutdown {
+			m.container.daemon.autoRemove(m.container)
+		}
+	}()
 	// ensure that when the monitor finally exits we release the networking and unmount the rootfs
 	defer func() {
 		if afterRun {
@@ -124,8 +138,13 @@ func (m *containerMonitor) Start() error {
 		m.container.HasBeenManuallyStopped = false
 	}
 
-	// reset the restart count
-	m.container.RestartCount = -1
+	// reset the restart count, unless the container is restored and keeps
+	// counting from where the previous daemon left it
+	if m.restoring {
+		m.container.RestartCount--
+	} else {
+		m.container.RestartCount = -1
+	}
 
 	for {
 		m.container.RestartCount++
@@ -138,15 +157,28 @@ func (m *containerMonitor) Start() error {
 
 		pipes := execdriver.NewPipes(m.container.stdin, m.container.stdout, m.container.stderr, m.container.Config.OpenStdin)
 
-		m.container.logEvent("start")
+		if m.restoring {
+			m.lastStartTime = m.container.StartedAt
+			exitStatus, err = m.container.daemon.reattach(m.container, pipes, m.callback)
+		} else {
+			m.container.logEvent("start")
 
-		m.lastStartTime = time.Now()
+			m.lastStartTime = time.Now()
 
-		if exitStatus, err = m.container.daemon.run(m.container, pipes, m.callback); err != nil {
+			exitStatus, err = m.container.daemon.run(m.container, pipes, m.callback)
+		}
+		if err != nil {
 			// if we receive an internal error from the initial start of a container then lets
 			// return it instead of entering the restart loop
-			if m.container.RestartCount == 0 {
-				m.container.ExitCode = -1
+			if m.container.RestartCount == 0 || m.restoring {
+				if m.restoring && err == execdriver.ErrNotRunning {
+					// the container exited while the daemon was down,
+					// record the exit code its process left
+					m.container.setStopped(&exitStatus)
+					m.logDieEvent(exitStatus.ExitCode)
+				} else {
+					m.container.ExitCode = -1
+				}
 				m.resetContainer(false)
 
 				return err
@@ -154,15 +186,20 @@ func (m *containerMonitor) Start() error {
 
 			logrus.Errorf("Error running container: %s", err)
 		}
+		m.restoring = false
 
 		// here container.Lock is already lost
 		afterRun = true
 
+		m.container.Lock()
+		m.container.daemon.stopHealthchecks(m.container)
+		m.container.Unlock()
+
 		m.resetMonitor(err == nil && exitStatus.ExitCode == 0)
 
 		if m.shouldRestart(exitStatus.ExitCode) {
 			m.container.setRestarting(&exitStatus)
-			m.container.logEvent("die")
+			m.logDieEvent(exitStatus.ExitCode)
 			m.resetContainer(true)
 
 			// sleep with a small time increment between each restart to help avoid issues cased by quickly
@@ -177,7 +214,7 @@ func (m *containerMonitor) Start() error {
 			continue
 		}
 
-		m.container.logEvent("die")
+		m.logDieEvent(exitStatus.ExitCode)
 		m.resetContainer(true)
 		return err
 	}
@@ -262,7 +299,14 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 		}
 	}
 
-	m.container.setRunning(pid)
+	if m.restoring {
+		// the container kept running while the daemon was down, so
+		// its state is still valid apart from the pid
+		m.container.Pid = pid
+	} else {
+		m.container.setRunning(pid)
+	}
+	m.container.daemon.initHealthMonitor(m.container)
 
 	// signal that the process has started
 	// close channel only if not closed
@@ -278,6 +322,14 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 	return nil
 }
 
+// logDieEvent logs the die event of the container with its exit code.
+func (m *containerMonitor) logDieEvent(exitCode int) {
+	attributes := map[string]string{
+		"exitCode": strconv.Itoa(exitCode),
+	}
+	m.container.daemon.LogContainerEventWithAttributes(m.container, "die", attributes)
+}
+
 // resetContainer resets the container's IO and ensures that the command is able to be executed again
 // by copying the data into a new struct
 // if lock is true, then container locked during reset
@@ -327,6 +379,7 @@ func (m *containerMonitor) resetContainer(lock bool) {
 			}
 		}
 		container.logDriver.Close()
+		container.logDropped = container.logMessagesDropped()
 		container.logCopier = nil
 		container.logDriver = nil
 	}
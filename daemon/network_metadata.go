@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// networkMetadata is what the daemon knows of a network besides what
+// libnetwork keeps. The creation time of the networks the daemon did not see
+// created is the time it first listed them.
+type networkMetadata struct {
+	Labels    map[string]string
+	CreatedAt time.Time
+}
+
+// networkMetadataStore keeps the metadata of the networks, by ID, in a file.
+type networkMetadataStore struct {
+	mu       sync.Mutex
+	path     string
+	networks map[string]*networkMetadata
+}
+
+// newNetworkMetadataStore loads the metadata of the networks from the file at
+// path, where it is saved from then on.
+func newNetworkMetadataStore(path string) (*networkMetadataStore, error) {
+	s := &networkMetadataStore{
+		path:     path,
+		networks: make(map[string]*networkMetadata),
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.networks); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// save saves the metadata of the networks. It is called with the lock held.
+func (s *networkMetadataStore) save() {
+	data, err := json.Marshal(s.networks)
+	if err == nil {
+		tmp := s.path + ".tmp"
+		if err = ioutil.WriteFile(tmp, data, 0600); err == nil {
+			err = os.Rename(tmp, s.path)
+		}
+	}
+	if err != nil {
+		logrus.Errorf("Failed to save the metadata of the networks: %v", err)
+	}
+}
+
+// add records the labels of a network created now.
+func (s *networkMetadataStore) add(id string, labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.networks[id] = &networkMetadata{Labels: labels, CreatedAt: time.Now().UTC()}
+	s.save()
+}
+
+// get returns the metadata of a network, recording it first if the network
+// is not known yet.
+func (s *networkMetadataStore) get(id string) *networkMetadata {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.networks[id]
+	if !ok {
+		m = &networkMetadata{CreatedAt: time.Now().UTC()}
+		s.networks[id] = m
+		s.save()
+	}
+	return m
+}
+
+// remove forgets the metadata of a removed network.
+func (s *networkMetadataStore) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.networks[id]; ok {
+		delete(s.networks, id)
+		s.save()
+	}
+}
+
+// NetworkCreated records the labels and the creation time of a network
+// created through the API.
+func (daemon *Daemon) NetworkCreated(networkID string, labels map[string]string) {
+	daemon.networkMetadata.add(networkID, labels)
+}
+
+// NetworkRemoved forgets the metadata of a network removed through the API.
+func (daemon *Daemon) NetworkRemoved(networkID string) {
+	daemon.networkMetadata.remove(networkID)
+}
@@ -0,0 +1,232 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/timeutils"
+	"github.com/docker/docker/volume"
+	"github.com/docker/docker/volume/store"
+)
+
+var (
+	acceptedContainersPruneFilters = map[string]bool{"label": true, "until": true}
+	acceptedImagesPruneFilters     = map[string]bool{"label": true, "until": true, "dangling": true}
+	acceptedVolumesPruneFilters    = map[string]bool{"label": true, "until": true}
+	acceptedNetworksPruneFilters   = map[string]bool{"label": true, "until": true}
+
+	// predefinedNetworks are the networks created by the daemon on start,
+	// which are never pruned.
+	predefinedNetworks = map[string]bool{"bridge": true, "host": true, "none": true}
+)
+
+// ContainersPrune removes the stopped containers matching the filters, and
+// returns their IDs and the space their writable layers used.
+func (daemon *Daemon) ContainersPrune(filterArgs string) (*types.ContainersPruneReport, error) {
+	pruneFilters, until, err := parsePruneFilters(filterArgs, acceptedContainersPruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &types.ContainersPruneReport{}
+	for _, container := range daemon.List() {
+		if container.IsRunning() {
+			continue
+		}
+		if !until.IsZero() && !container.Created.Before(until) {
+			continue
+		}
+		if !pruneFilters.MatchKVList("label", container.Config.Labels) {
+			continue
+		}
+
+		size := container.getSizeRw()
+		if err := daemon.ContainerRm(container.ID, &ContainerRmConfig{}); err != nil {
+			logrus.Warnf("Failed to prune container %s: %v", container.ID, err)
+			continue
+		}
+		if size > 0 {
+			rep.SpaceReclaimed += uint64(size)
+		}
+		rep.ContainersDeleted = append(rep.ContainersDeleted, container.ID)
+	}
+	return rep, nil
+}
+
+// ImagesPrune removes the images matching the filters which no container
+// uses, along with their parents which nothing else refers to, and returns
+// the references untagged, the IDs deleted and the space reclaimed. Unless
+// the dangling filter is false, only the images without any repository
+// reference are removed.
+func (daemon *Daemon) ImagesPrune(filterArgs string) (*types.ImagesPruneReport, error) {
+	pruneFilters, until, err := parsePruneFilters(filterArgs, acceptedImagesPruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	danglingOnly := true
+	if v, ok := pruneFilters["dangling"]; ok {
+		if len(v) > 1 {
+			return nil, derr.ErrorCodeDanglingOne
+		}
+		danglingOnly = strings.ToLower(v[0]) == "true" || v[0] == "1"
+	}
+
+	// The sizes are looked up before the layers are deleted.
+	layers := daemon.Graph().Map()
+
+	rep := &types.ImagesPruneReport{}
+	for _, img := range daemon.Graph().Heads() {
+		if danglingOnly && !daemon.imageIsDangling(img) {
+			continue
+		}
+		if !until.IsZero() && !img.Created.Before(until) {
+			continue
+		}
+		var labels map[string]string
+		if img.Config != nil {
+			labels = img.Config.Labels
+		}
+		if !pruneFilters.MatchKVList("label", labels) {
+			continue
+		}
+		// A stopped container is only a soft conflict, which is forced
+		// below along with the references of the tagged images.
+		if daemon.getContainerUsingImage(img.ID) != nil {
+			continue
+		}
+
+		// The hard conflicts, such as an ongoing pull or build, are still
+		// checked and the parents are pruned quietly, as by docker rmi.
+		var records []types.ImageDelete
+		err := daemon.imageDeleteHelper(img, &records, !danglingOnly, true, false)
+		// The records of a partial removal are reported along with the error.
+		rep.ImagesDeleted = append(rep.ImagesDeleted, records...)
+		for _, r := range records {
+			if layer, ok := layers[r.Deleted]; ok {
+				rep.SpaceReclaimed += uint64(layer.Size)
+			}
+		}
+		if err != nil {
+			logrus.Warnf("Failed to prune image %s: %v", img.ID, err)
+		}
+	}
+	return rep, nil
+}
+
+// VolumesPrune removes the volumes matching the filters which no container
+// uses, and returns their names and the space the local ones used.
+func (daemon *Daemon) VolumesPrune(filterArgs string) (*types.VolumesPruneReport, error) {
+	pruneFilters, until, err := parsePruneFilters(filterArgs, acceptedVolumesPruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &types.VolumesPruneReport{}
+	for _, v := range daemon.volumes.List() {
+		if daemon.volumes.Count(v) > 0 {
+			continue
+		}
+		if !until.IsZero() && !daemon.volumes.CreatedAt(v).Before(until) {
+			continue
+		}
+		if !pruneFilters.MatchKVList("label", daemon.volumes.Labels(v)) {
+			continue
+		}
+
+		var size int64
+		if v.DriverName() == volume.DefaultDriverName {
+			var err error
+			if size, err = directory.Size(v.Path()); err != nil {
+				logrus.Warnf("Failed to compute the size of volume %s: %v", v.Name(), err)
+			}
+		}
+		if err := daemon.volumes.Remove(v); err != nil {
+			// The volume was mounted in the meantime.
+			if err != store.ErrVolumeInUse {
+				logrus.Warnf("Failed to prune volume %s: %v", v.Name(), err)
+			}
+			continue
+		}
+		daemon.LogVolumeEvent(v.Name(), "destroy", map[string]string{"driver": v.DriverName()})
+		if size > 0 {
+			rep.SpaceReclaimed += uint64(size)
+		}
+		rep.VolumesDeleted = append(rep.VolumesDeleted, v.Name())
+	}
+	return rep, nil
+}
+
+// NetworksPrune removes the networks matching the filters without any
+// endpoint, but for the ones created by the daemon, and returns their names.
+func (daemon *Daemon) NetworksPrune(filterArgs string) (*types.NetworksPruneReport, error) {
+	pruneFilters, until, err := parsePruneFilters(filterArgs, acceptedNetworksPruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &types.NetworksPruneReport{}
+	c := daemon.NetworkController()
+	if c == nil {
+		return rep, nil
+	}
+	for _, n := range c.Networks() {
+		if predefinedNetworks[n.Name()] || len(n.Endpoints()) > 0 {
+			continue
+		}
+		m := daemon.networkMetadata.get(n.ID())
+		if !until.IsZero() && !m.CreatedAt.Before(until) {
+			continue
+		}
+		if !pruneFilters.MatchKVList("label", m.Labels) {
+			continue
+		}
+		if err := n.Delete(); err != nil {
+			logrus.Warnf("Failed to prune network %s: %v", n.Name(), err)
+			continue
+		}
+		daemon.networkMetadata.remove(n.ID())
+		daemon.LogNetworkEvent(n.ID(), n.Name(), n.Type(), "destroy")
+		rep.NetworksDeleted = append(rep.NetworksDeleted, n.Name())
+	}
+	return rep, nil
+}
+
+// parsePruneFilters parses the JSON-encoded filters of a prune request,
+// rejecting the ones not accepted, and returns them along with the time
+// before which the objects must have been created to be pruned, which is
+// zero without an until filter. The until filter is either a timestamp or a
+// duration relative to the daemon's time.
+func parsePruneFilters(filterArgs string, accepted map[string]bool) (filters.Args, time.Time, error) {
+	var until time.Time
+
+	pruneFilters, err := filters.FromParam(filterArgs)
+	if err != nil {
+		return nil, until, err
+	}
+	for name := range pruneFilters {
+		if !accepted[name] {
+			return nil, until, fmt.Errorf("Invalid filter '%s'", name)
+		}
+	}
+
+	if v, ok := pruneFilters["until"]; ok {
+		if len(v) > 1 {
+			return nil, until, errors.New("Conflict: cannot use more than 1 value for `until` filter")
+		}
+		ts, err := strconv.ParseInt(timeutils.GetTimestamp(v[0], time.Now()), 10, 64)
+		if err != nil {
+			return nil, until, fmt.Errorf("Invalid value for `until` filter: %s", v[0])
+		}
+		until = time.Unix(ts, 0)
+	}
+	return pruneFilters, until, nil
+}
@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePruneFilters(t *testing.T) {
+	accepted := map[string]bool{"label": true, "until": true}
+
+	pruneFilters, until, err := parsePruneFilters("", accepted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruneFilters) != 0 || !until.IsZero() {
+		t.Fatalf("Expected no filters, got %v and %v", pruneFilters, until)
+	}
+
+	pruneFilters, until, err = parsePruneFilters(`{"label":["foo=bar"],"until":["1h"]}`, accepted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pruneFilters.MatchKVList("label", map[string]string{"foo": "bar"}) {
+		t.Fatalf("Expected the label filter to match foo=bar, got %v", pruneFilters)
+	}
+	if d := time.Since(until); d < time.Hour || d > time.Hour+time.Minute {
+		t.Fatalf("Expected until to be an hour ago, got %v", until)
+	}
+
+	if _, until, err = parsePruneFilters(`{"until":["1445000000"]}`, accepted); err != nil {
+		t.Fatal(err)
+	}
+	if until.Unix() != 1445000000 {
+		t.Fatalf("Expected until to be 1445000000, got %d", until.Unix())
+	}
+
+	invalid := []string{
+		`{"dangling":["true"]}`,
+		`{"until":["yesterday"]}`,
+		`{"until":["1h","2h"]}`,
+	}
+	for _, filterArgs := range invalid {
+		if _, _, err := parsePruneFilters(filterArgs, accepted); err == nil {
+			t.Fatalf("Expected an error for %s", filterArgs)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/discovery"
+	"github.com/docker/docker/runconfig"
+)
+
+// Reload applies the settings of config which can be changed while the
+// daemon is running: labels, the default log driver and its options, and the
+// cluster discovery. Only the settings for which reloaded returns true, given
+// the long name of their flag, are applied; the others keep their current
+// value. Nothing is applied if one of the settings is invalid.
+func (daemon *Daemon) Reload(config *Config, reloaded func(name string) bool) error {
+	daemon.reloadLock.Lock()
+	defer daemon.reloadLock.Unlock()
+
+	current := daemon.configStore
+
+	logConfig := daemon.defaultLogConfig
+	if reloaded("log-driver") {
+		logConfig.Type = config.LogConfig.Type
+	}
+	if reloaded("log-opt") {
+		logConfig.Config = config.LogConfig.Config
+	}
+	if reloaded("log-driver") || reloaded("log-opt") {
+		if err := validateLogConfig(logConfig); err != nil {
+			return err
+		}
+	}
+
+	store, advertise := current.ClusterStore, current.ClusterAdvertise
+	if reloaded("cluster-store") {
+		store = config.ClusterStore
+	}
+	if reloaded("cluster-advertise") {
+		advertise = config.ClusterAdvertise
+	}
+	if err := daemon.reloadClusterDiscovery(store, advertise); err != nil {
+		return err
+	}
+
+	if reloaded("label") {
+		current.Labels = config.Labels
+	}
+	daemon.defaultLogConfig = logConfig
+	current.LogConfig = logConfig
+
+	logrus.Infof("Reloaded configuration: labels=%v, log driver=%s, log opts=%v, cluster store=%q, cluster advertise=%q",
+		current.Labels, logConfig.Type, logConfig.Config, current.ClusterStore, current.ClusterAdvertise)
+
+	attributes := map[string]string{
+		"labels":            fmt.Sprintf("%v", current.Labels),
+		"log-driver":        logConfig.Type,
+		"cluster-store":     current.ClusterStore,
+		"cluster-advertise": current.ClusterAdvertise,
+	}
+	daemon.LogDaemonEventWithAttributes("reload", attributes)
+	return nil
+}
+
+// getDefaultLogConfig returns the log config of the containers which do not
+// set one.
+func (daemon *Daemon) getDefaultLogConfig() runconfig.LogConfig {
+	daemon.reloadLock.RLock()
+	defer daemon.reloadLock.RUnlock()
+	return daemon.defaultLogConfig
+}
+
+// getReloadableConfig returns the labels and the cluster store of the
+// daemon.
+func (daemon *Daemon) getReloadableConfig() (labels []string, clusterStore string) {
+	daemon.reloadLock.RLock()
+	defer daemon.reloadLock.RUnlock()
+	return daemon.configStore.Labels, daemon.configStore.ClusterStore
+}
+
+// validateLogConfig checks that the log driver of cfg exists and accepts the
+// options of cfg.
+func validateLogConfig(cfg runconfig.LogConfig) error {
+	if cfg.Type != "none" {
+		if _, err := logger.GetLogDriver(cfg.Type); err != nil {
+			return fmt.Errorf("error finding the logging driver: %v", err)
+		}
+	}
+	return logger.ValidateLogOpts(cfg.Type, cfg.Config)
+}
+
+// reloadClusterDiscovery restarts the node discovery with the given store
+// and advertised address if they changed. Multi-host networking keeps using
+// the store the daemon was started with.
+func (daemon *Daemon) reloadClusterDiscovery(store, advertise string) error {
+	current := daemon.configStore
+	if store == current.ClusterStore && advertise == current.ClusterAdvertise {
+		return nil
+	}
+
+	var (
+		watcher discovery.Watcher
+		stop    chan struct{}
+	)
+	// Discovery is only enabled when the daemon has an address to advertise.
+	if store != "" && advertise != "" {
+		var err error
+		stop = make(chan struct{})
+		if watcher, err = initDiscovery(store, advertise, stop); err != nil {
+			return fmt.Errorf("discovery reload failed (%v)", err)
+		}
+	}
+
+	if daemon.discoveryStop != nil {
+		close(daemon.discoveryStop)
+	}
+	daemon.discoveryWatcher = watcher
+	daemon.discoveryStop = stop
+	current.ClusterStore = store
+	current.ClusterAdvertise = advertise
+	return nil
+}
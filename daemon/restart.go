@@ -6,16 +6,21 @@ import (
 
 // ContainerRestart stops and starts a container. It attempts to
 // gracefully stop the container within the given timeout, forcefully
-// stopping it if the timeout is exceeded. If given a negative
+// stopping it if the timeout is exceeded. If seconds is nil, the stop
+// timeout of the container is used. If given a negative
 // timeout, ContainerRestart will wait forever until a graceful
 // stop. Returns an error if the container cannot be found, or if
 // there is an underlying error at any stage of the restart.
-func (daemon *Daemon) ContainerRestart(name string, seconds int) error {
+func (daemon *Daemon) ContainerRestart(name string, seconds *int) error {
 	container, err := daemon.Get(name)
 	if err != nil {
 		return err
 	}
-	if err := container.Restart(seconds); err != nil {
+	if seconds == nil {
+		stopTimeout := container.stopTimeout()
+		seconds = &stopTimeout
+	}
+	if err := container.Restart(*seconds); err != nil {
 		return derr.ErrorCodeCantRestart.WithArgs(name, err)
 	}
 	return nil
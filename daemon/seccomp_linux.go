@@ -0,0 +1,6 @@
+// +build linux,seccomp,cgo
+
+package daemon
+
+// supportsSeccomp is true when the daemon is built with seccomp support.
+const supportsSeccomp = true
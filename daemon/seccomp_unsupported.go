@@ -0,0 +1,5 @@
+// +build !linux !seccomp !cgo
+
+package daemon
+
+const supportsSeccomp = false
@@ -45,6 +45,9 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConf
 	}
 
 	if err := container.Start(); err != nil {
+		if container.hostConfig.AutoRemove {
+			daemon.autoRemove(container)
+		}
 		return derr.ErrorCodeCantStart.WithArgs(name, utils.GetErrorMessage(err))
 	}
 
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/daemon/execdriver"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/units"
@@ -28,6 +29,7 @@ type State struct {
 	Error             string // contains last known error when starting the container
 	StartedAt         time.Time
 	FinishedAt        time.Time
+	Health            *Health
 	waitChan          chan struct{}
 }
 
@@ -48,6 +50,10 @@ func (s *State) String() string {
 			return fmt.Sprintf("Restarting (%d) %s ago", s.ExitCode, units.HumanDuration(time.Now().UTC().Sub(s.FinishedAt)))
 		}
 
+		if h := s.Health; h != nil {
+			return fmt.Sprintf("Up %s (%s)", units.HumanDuration(time.Now().UTC().Sub(s.StartedAt)), h.String())
+		}
+
 		return fmt.Sprintf("Up %s", units.HumanDuration(time.Now().UTC().Sub(s.StartedAt)))
 	}
 
@@ -105,6 +111,22 @@ func isValidStateString(s string) bool {
 	return true
 }
 
+// healthString returns the health status of the container, or "none" if
+// it has no healthcheck.
+func (s *State) healthString() string {
+	if s.Health == nil {
+		return types.NoHealthcheck
+	}
+	return s.Health.Status
+}
+
+func isValidHealthString(s string) bool {
+	return s == types.Starting ||
+		s == types.Healthy ||
+		s == types.Unhealthy ||
+		s == types.NoHealthcheck
+}
+
 func wait(waitChan <-chan struct{}, timeout time.Duration) error {
 	if timeout < 0 {
 		<-waitChan
@@ -155,6 +177,43 @@ func (s *State) WaitStop(timeout time.Duration) (int, error) {
 	return s.getExitCode(), nil
 }
 
+// stopWaiter returns a function which waits until state is stopped, like
+// WaitStop does, but which watches the state from the moment stopWaiter is
+// called, so that a stop happening before the function is called is not
+// missed. If nextRun is set and the state is not running, the function
+// waits for the next run to stop instead of returning immediately.
+func (s *State) stopWaiter(nextRun bool) func(timeout time.Duration) (int, error) {
+	s.Lock()
+	running := s.Running
+	exitCode := s.ExitCode
+	waitChan := s.waitChan
+	s.Unlock()
+
+	return func(timeout time.Duration) (int, error) {
+		if !running {
+			if !nextRun {
+				return exitCode, nil
+			}
+			// wait for the next start, the state might already be
+			// stopped again once it happened
+			if err := wait(waitChan, timeout); err != nil {
+				return -1, err
+			}
+			s.Lock()
+			running = s.Running
+			waitChan = s.waitChan
+			s.Unlock()
+			if !running {
+				return s.getExitCode(), nil
+			}
+		}
+		if err := wait(waitChan, timeout); err != nil {
+			return -1, err
+		}
+		return s.getExitCode(), nil
+	}
+}
+
 // IsRunning returns whether the running flag is set. Used by Container to check whether a container is running.
 func (s *State) IsRunning() bool {
 	s.Lock()
@@ -228,6 +287,13 @@ func (s *State) setRestarting(exitStatus *execdriver.ExitStatus) {
 	s.waitChan = make(chan struct{})
 }
 
+// setStartFailed fires the waiters for start when the container failed to
+// start.
+func (s *State) setStartFailed() {
+	close(s.waitChan)
+	s.waitChan = make(chan struct{})
+}
+
 // setError sets the container's error state. This is useful when we want to
 // know the error that occurred when container transits to another state
 // when inspecting it
@@ -109,3 +109,38 @@ func TestStateTimeoutWait(t *testing.T) {
 	}
 
 }
+
+func TestStateStopWaiter(t *testing.T) {
+	s := NewState()
+	s.ExitCode = 3
+
+	// A stopped state is returned straight away, unless waiting for the
+	// next run.
+	if exitCode, err := s.stopWaiter(false)(-1 * time.Second); err != nil || exitCode != 3 {
+		t.Fatalf("stopWaiter returned exitCode: %v, err: %v, expected exitCode: 3", exitCode, err)
+	}
+	waitNext := s.stopWaiter(true)
+	if _, err := waitNext(100 * time.Millisecond); err == nil {
+		t.Fatal("Expected stopWaiter to wait for the next run")
+	}
+
+	// The whole run happens before the waiter is called, it is not missed.
+	waitNext = s.stopWaiter(true)
+	s.Lock()
+	s.setRunning(100)
+	s.Unlock()
+	s.setStoppedLocking(&execdriver.ExitStatus{ExitCode: 5})
+	if exitCode, err := waitNext(100 * time.Millisecond); err != nil || exitCode != 5 {
+		t.Fatalf("stopWaiter returned exitCode: %v, err: %v, expected exitCode: 5", exitCode, err)
+	}
+
+	// A failed start releases the waiter.
+	waitNext = s.stopWaiter(true)
+	s.Lock()
+	s.ExitCode = 128
+	s.setStartFailed()
+	s.Unlock()
+	if exitCode, err := waitNext(100 * time.Millisecond); err != nil || exitCode != 128 {
+		t.Fatalf("stopWaiter returned exitCode: %v, err: %v, expected exitCode: 128", exitCode, err)
+	}
+}
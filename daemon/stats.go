@@ -59,6 +59,10 @@ func (daemon *Daemon) ContainerStats(prefixOrName string, config *ContainerStats
 		ss.MemoryStats.Limit = uint64(update.MemoryLimit)
 		ss.Read = update.Read
 		ss.CPUStats.SystemUsage = update.SystemUsage
+		ss.PidsStats.Current = update.PidsCurrent
+		container.Lock()
+		ss.LogMessagesDropped = container.logMessagesDropped()
+		container.Unlock()
 		preCPUStats = ss.CPUStats
 		return ss
 	}
@@ -25,6 +25,7 @@ func newStatsCollector(interval time.Duration) *statsCollector {
 	s := &statsCollector{
 		interval:            interval,
 		publishers:          make(map[*Container]*pubsub.Publisher),
+		latest:              make(map[*Container]*execdriver.ResourceStats),
 		clockTicksPerSecond: uint64(system.GetClockTicks()),
 		bufReader:           bufio.NewReaderSize(nil, 128),
 	}
@@ -39,6 +40,11 @@ type statsCollector struct {
 	clockTicksPerSecond uint64
 	publishers          map[*Container]*pubsub.Publisher
 	bufReader           *bufio.Reader
+	// running lists the running containers whose stats are collected
+	// whether or not they are subscribed to, and latest holds their last
+	// stats, for the metrics.
+	running func() []*Container
+	latest  map[*Container]*execdriver.ResourceStats
 }
 
 // collect registers the container with the collector and adds it to
@@ -63,6 +69,7 @@ func (s *statsCollector) stopCollection(c *Container) {
 		publisher.Close()
 		delete(s.publishers, c)
 	}
+	delete(s.latest, c)
 	s.m.Unlock()
 }
 
@@ -93,13 +100,25 @@ func (s *statsCollector) run() {
 		// but saves allocations in further iterations
 		pairs = pairs[:0]
 
+		s.m.Lock()
+		listRunning := s.running
+		s.m.Unlock()
+		var running []*Container
+		if listRunning != nil {
+			running = listRunning()
+		}
 		s.m.Lock()
 		for container, publisher := range s.publishers {
 			// copy pointers here to release the lock ASAP
 			pairs = append(pairs, publishersPair{container, publisher})
 		}
+		for _, container := range running {
+			if _, exists := s.publishers[container]; !exists {
+				pairs = append(pairs, publishersPair{container, nil})
+			}
+		}
 		s.m.Unlock()
-		if len(pairs) == 0 {
+		if len(pairs) == 0 && listRunning == nil {
 			continue
 		}
 
@@ -109,6 +128,7 @@ func (s *statsCollector) run() {
 			continue
 		}
 
+		latest := make(map[*Container]*execdriver.ResourceStats, len(pairs))
 		for _, pair := range pairs {
 			stats, err := pair.container.stats()
 			if err != nil {
@@ -118,9 +138,38 @@ func (s *statsCollector) run() {
 				continue
 			}
 			stats.SystemUsage = systemUsage
-			pair.publisher.Publish(stats)
+			latest[pair.container] = stats
+			if pair.publisher != nil {
+				pair.publisher.Publish(stats)
+			}
 		}
+		if listRunning != nil {
+			s.m.Lock()
+			s.latest = latest
+			s.m.Unlock()
+		}
+	}
+}
+
+// collectRunning makes the collector collect the stats of the containers
+// listed by running at each interval, whether or not they are subscribed to.
+// It is set before the stats are read with snapshot.
+func (s *statsCollector) collectRunning(running func() []*Container) {
+	s.m.Lock()
+	s.running = running
+	s.m.Unlock()
+}
+
+// snapshot returns the last stats collected of the running containers, for
+// the metrics to be scraped at once rather than streamed per container.
+func (s *statsCollector) snapshot() []containerStats {
+	s.m.Lock()
+	defer s.m.Unlock()
+	all := make([]containerStats, 0, len(s.latest))
+	for c, stats := range s.latest {
+		all = append(all, containerStats{c, stats})
 	}
+	return all
 }
 
 const nanoSecondsPerSecond = 1e9
@@ -26,6 +26,16 @@ func (s *statsCollector) collect(c *Container) chan interface{} {
 func (s *statsCollector) stopCollection(c *Container) {
 }
 
+// collectRunning makes the collector collect the stats of the running
+// containers whether or not they are subscribed to.
+func (s *statsCollector) collectRunning(running func() []*Container) {
+}
+
+// snapshot returns the last stats collected of the running containers.
+func (s *statsCollector) snapshot() []containerStats {
+	return nil
+}
+
 // unsubscribe removes a specific subscriber from receiving updates for a container's stats.
 func (s *statsCollector) unsubscribe(c *Container, ch chan interface{}) {
 }
@@ -6,11 +6,12 @@ import (
 
 // ContainerStop looks for the given container and terminates it,
 // waiting the given number of seconds before forcefully killing the
-// container. If a negative number of seconds is given, ContainerStop
+// container. If seconds is nil, the stop timeout of the container is
+// used. If a negative number of seconds is given, ContainerStop
 // will wait for a graceful termination. An error is returned if the
 // container is not found, is already stopped, or if there is a
 // problem stopping the container.
-func (daemon *Daemon) ContainerStop(name string, seconds int) error {
+func (daemon *Daemon) ContainerStop(name string, seconds *int) error {
 	container, err := daemon.Get(name)
 	if err != nil {
 		return err
@@ -18,7 +19,11 @@ func (daemon *Daemon) ContainerStop(name string, seconds int) error {
 	if !container.IsRunning() {
 		return derr.ErrorCodeStopped
 	}
-	if err := container.Stop(seconds); err != nil {
+	if seconds == nil {
+		stopTimeout := container.stopTimeout()
+		seconds = &stopTimeout
+	}
+	if err := container.Stop(*seconds); err != nil {
 		return derr.ErrorCodeCantStop.WithArgs(name, err)
 	}
 	return nil
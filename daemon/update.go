@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+)
+
+// ContainerUpdate updates the resource limits of a container. The new limits
+// are applied straight away to a running container, and are stored in the
+// container's hostconfig so that they are kept across restarts.
+func (daemon *Daemon) ContainerUpdate(name string, config *runconfig.UpdateConfig) ([]string, error) {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	if container.removalInProgress || container.Dead {
+		return nil, derr.ErrorCodeCantUpdate.WithArgs(container.ID, "container is marked for removal")
+	}
+
+	// The kernel only allows the kernel memory limit to be set while no
+	// process is running in the cgroup.
+	if container.Running && config.KernelMemory != 0 {
+		return nil, derr.ErrorCodeUpdateKernelMemory.WithArgs(container.ID)
+	}
+
+	hostConfig := *container.hostConfig
+	mergeUpdateConfig(&hostConfig, config)
+	daemon.adaptContainerSettings(&hostConfig, false)
+
+	warnings, err := daemon.verifyContainerSettings(&hostConfig, nil)
+	if err != nil {
+		return warnings, derr.ErrorCodeCantUpdate.WithArgs(container.ID, err)
+	}
+
+	if container.Running {
+		if err := daemon.updateRunningResources(container, &hostConfig); err != nil {
+			return warnings, derr.ErrorCodeCantUpdate.WithArgs(container.ID, err)
+		}
+	}
+
+	container.hostConfig = &hostConfig
+	if err := container.toDisk(); err != nil {
+		return warnings, derr.ErrorCodeCantUpdate.WithArgs(container.ID, err)
+	}
+
+	container.logEvent("update")
+	return warnings, nil
+}
+
+// updateRunningResources applies the resource limits of hostConfig to the
+// running container through the exec driver. The command of the container
+// is left untouched if the driver fails to apply them.
+func (daemon *Daemon) updateRunningResources(container *Container, hostConfig *runconfig.HostConfig) error {
+	if container.command == nil || container.command.Resources == nil {
+		return nil
+	}
+	resources := container.command.Resources
+	old := *resources
+
+	resources.BlkioWeight = hostConfig.BlkioWeight
+	resources.CPUShares = hostConfig.CPUShares
+	resources.CPUQuota = hostConfig.CPUQuota
+	resources.CpusetCpus = hostConfig.CpusetCpus
+	resources.Memory = hostConfig.Memory
+	resources.MemorySwap = hostConfig.MemorySwap
+	resources.MemoryReservation = hostConfig.MemoryReservation
+	resources.KernelMemory = hostConfig.KernelMemory
+
+	if err := daemon.execDriver.Update(container.command); err != nil {
+		*resources = old
+		return err
+	}
+	return nil
+}
+
+// mergeUpdateConfig copies the resource limits set in config to hostConfig.
+func mergeUpdateConfig(hostConfig *runconfig.HostConfig, config *runconfig.UpdateConfig) {
+	if config.BlkioWeight != 0 {
+		hostConfig.BlkioWeight = config.BlkioWeight
+	}
+	if config.CPUShares != 0 {
+		hostConfig.CPUShares = config.CPUShares
+	}
+	if config.CPUQuota != 0 {
+		hostConfig.CPUQuota = config.CPUQuota
+	}
+	if config.CpusetCpus != "" {
+		hostConfig.CpusetCpus = config.CpusetCpus
+	}
+	if config.Memory != 0 {
+		// The memory reservation defaults to the memory limit, so keep it
+		// that way unless it was set explicitly.
+		if hostConfig.MemoryReservation == hostConfig.Memory {
+			hostConfig.MemoryReservation = config.Memory
+		}
+		hostConfig.Memory = config.Memory
+	}
+	if config.MemorySwap != 0 {
+		hostConfig.MemorySwap = config.MemorySwap
+	}
+	if config.KernelMemory != 0 {
+		hostConfig.KernelMemory = config.KernelMemory
+	}
+}
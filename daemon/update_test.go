@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/runconfig"
+)
+
+func TestMergeUpdateConfig(t *testing.T) {
+	hostConfig := &runconfig.HostConfig{
+		Memory:            100,
+		MemoryReservation: 100,
+		MemorySwap:        200,
+		CPUShares:         1024,
+		CpusetCpus:        "0",
+		BlkioWeight:       500,
+	}
+
+	mergeUpdateConfig(hostConfig, &runconfig.UpdateConfig{
+		Memory:     300,
+		MemorySwap: 600,
+		CPUQuota:   50000,
+		CpusetCpus: "0-1",
+	})
+
+	expected := runconfig.HostConfig{
+		Memory:            300,
+		MemoryReservation: 300,
+		MemorySwap:        600,
+		CPUShares:         1024,
+		CPUQuota:          50000,
+		CpusetCpus:        "0-1",
+		BlkioWeight:       500,
+	}
+	if hostConfig.Memory != expected.Memory ||
+		hostConfig.MemoryReservation != expected.MemoryReservation ||
+		hostConfig.MemorySwap != expected.MemorySwap ||
+		hostConfig.CPUShares != expected.CPUShares ||
+		hostConfig.CPUQuota != expected.CPUQuota ||
+		hostConfig.CpusetCpus != expected.CpusetCpus ||
+		hostConfig.BlkioWeight != expected.BlkioWeight {
+		t.Fatalf("Expected %+v, got %+v", expected, *hostConfig)
+	}
+
+	// An explicit memory reservation is not changed.
+	hostConfig.MemoryReservation = 50
+	mergeUpdateConfig(hostConfig, &runconfig.UpdateConfig{Memory: 400})
+	if hostConfig.MemoryReservation != 50 {
+		t.Fatalf("Expected memory reservation to stay at 50, got %d", hostConfig.MemoryReservation)
+	}
+}
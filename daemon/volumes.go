@@ -13,6 +13,7 @@ import (
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/system"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/volume"
 )
 
@@ -100,10 +101,39 @@ func copyExistingContents(source, destination string) error {
 }
 
 // volumeToAPIType converts a volume.Volume to the type used by the remote API
-func volumeToAPIType(v volume.Volume) *types.Volume {
+func (daemon *Daemon) volumeToAPIType(v volume.Volume) *types.Volume {
 	return &types.Volume{
 		Name:       v.Name(),
 		Driver:     v.DriverName(),
 		Mountpoint: v.Path(),
+		Labels:     daemon.volumes.Labels(v),
 	}
 }
+
+// volumeCreate creates a volume, or returns the existing one with the same
+// name, and logs the creation of a new volume. The labels are only set on a
+// new volume.
+func (daemon *Daemon) volumeCreate(name, driverName string, opts, labels map[string]string) (volume.Volume, error) {
+	_, err := daemon.volumes.Get(name)
+	exists := err == nil
+
+	v, err := daemon.volumes.Create(name, driverName, opts, labels)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		daemon.LogVolumeEvent(v.Name(), "create", map[string]string{"driver": v.DriverName()})
+	}
+	return v, nil
+}
+
+// verifyTmpfsDestinations returns an error if one of the tmpfs mounts of
+// hostConfig would hide one of the mountPoints of the container.
+func verifyTmpfsDestinations(hostConfig *runconfig.HostConfig, mountPoints map[string]*mountPoint) error {
+	for dest := range hostConfig.Tmpfs {
+		if _, exists := mountPoints[filepath.Clean(dest)]; exists {
+			return derr.ErrorCodeTmpfsDup.WithArgs(dest)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package daemon
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/docker/docker/runconfig"
+)
 
 func TestParseVolumesFrom(t *testing.T) {
 	cases := []struct {
@@ -33,3 +37,19 @@ func TestParseVolumesFrom(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyTmpfsDestinations(t *testing.T) {
+	mountPoints := map[string]*mountPoint{
+		"/data": {Destination: "/data"},
+	}
+
+	hostConfig := &runconfig.HostConfig{Tmpfs: map[string]string{"/run": ""}}
+	if err := verifyTmpfsDestinations(hostConfig, mountPoints); err != nil {
+		t.Fatal(err)
+	}
+
+	hostConfig.Tmpfs["/data/"] = "size=1m"
+	if err := verifyTmpfsDestinations(hostConfig, mountPoints); err == nil {
+		t.Fatal("Expected an error for a tmpfs mounted on a volume")
+	}
+}
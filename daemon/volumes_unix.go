@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Sirupsen/logrus"
@@ -45,6 +46,15 @@ func (container *Container) setupMounts() ([]execdriver.Mount, error) {
 		if err != nil {
 			return nil, err
 		}
+		if m.Volume != nil {
+			attributes := map[string]string{
+				"driver":      m.Volume.DriverName(),
+				"container":   container.ID,
+				"destination": m.Destination,
+				"read/write":  strconv.FormatBool(m.RW),
+			}
+			container.daemon.LogVolumeEvent(m.Volume.Name(), "mount", attributes)
+		}
 		if !container.trySetNetworkMount(m.Destination, path) {
 			mounts = append(mounts, execdriver.Mount{
 				Source:      path,
@@ -363,6 +373,10 @@ func (daemon *Daemon) registerMountPoints(container *Container, hostConfig *runc
 		mountPoints[bind.Destination] = bind
 	}
 
+	if err := verifyTmpfsDestinations(hostConfig, mountPoints); err != nil {
+		return err
+	}
+
 	// Keep backwards compatible structures
 	bcVolumes := map[string]string{}
 	bcVolumesRW := map[string]bool{}
@@ -389,7 +403,7 @@ func (daemon *Daemon) registerMountPoints(container *Container, hostConfig *runc
 
 // createVolume creates a volume.
 func (daemon *Daemon) createVolume(name, driverName string, opts map[string]string) (volume.Volume, error) {
-	v, err := daemon.volumes.Create(name, driverName, opts)
+	v, err := daemon.volumeCreate(name, driverName, opts, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,10 @@
 package daemon
 
-import "time"
+import (
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+)
 
 // ContainerWait stops processing until the given container is
 // stopped. If the container is not found, an error is returned. On a
@@ -8,10 +12,63 @@ import "time"
 // timeout, an error is returned. If you want to wait forever, supply
 // a negative duration for the timeout.
 func (daemon *Daemon) ContainerWait(name string, timeout time.Duration) (int, error) {
-	container, err := daemon.Get(name)
+	wait, err := daemon.ContainerWaiter(name)
 	if err != nil {
 		return -1, err
 	}
 
-	return container.WaitStop(timeout)
+	return wait(timeout)
+}
+
+// ContainerWaiter returns a function which stops processing until the given
+// container is stopped, like ContainerWait. The container is watched from
+// the moment ContainerWaiter returns. A container created with AutoRemove is
+// removed once it exited, so the function waits for it to be removed too,
+// and if it is not running, waits for its next run to stop. The function
+// must be called once, it releases the resources used to watch the
+// container when it returns.
+func (daemon *Daemon) ContainerWaiter(name string) (func(timeout time.Duration) (int, error), error) {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !container.hostConfig.AutoRemove {
+		return container.stopWaiter(false), nil
+	}
+
+	removed := make(chan struct{})
+	done := make(chan struct{})
+	_, events := daemon.EventsService.Subscribe()
+	go func() {
+		defer daemon.EventsService.Evict(events)
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				if m, ok := ev.(*eventtypes.Message); ok && m.Type == eventtypes.ContainerEventType && m.Actor.ID == container.ID && m.Action == "destroy" {
+					close(removed)
+					return
+				}
+			case <-done:
+				// The wait returned or timed out.
+				return
+			}
+		}
+	}()
+	waitStop := container.stopWaiter(true)
+
+	return func(timeout time.Duration) (int, error) {
+		defer close(done)
+		exitCode, err := waitStop(timeout)
+		if err != nil {
+			return -1, err
+		}
+		if err := wait(removed, timeout); err != nil {
+			return -1, err
+		}
+		return exitCode, nil
+	}, nil
 }
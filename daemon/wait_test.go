@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/runconfig"
+)
+
+func TestContainerWaiterEvictsSubscription(t *testing.T) {
+	c := &Container{
+		CommonContainer: CommonContainer{
+			ID:         "5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57",
+			Name:       "tender_bardeen",
+			State:      NewState(),
+			hostConfig: &runconfig.HostConfig{AutoRemove: true},
+		},
+	}
+	daemon := &Daemon{
+		containers:    &contStore{s: map[string]*Container{c.ID: c}},
+		EventsService: events.New(),
+	}
+
+	wait, err := daemon.ContainerWaiter(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := daemon.EventsService.SubscribersCount(); n != 1 {
+		t.Fatalf("Expected the waiter to subscribe to the events, got %d subscribers", n)
+	}
+	if _, err := wait(10 * time.Millisecond); err == nil {
+		t.Fatal("Expected the wait to time out")
+	}
+	for start := time.Now(); daemon.EventsService.SubscribersCount() != 0; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("Expected the subscription to be evicted once the wait timed out")
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -28,6 +30,7 @@ import (
 	"github.com/docker/docker/pkg/tlsconfig"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/utils"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const daemonUsage = "       docker daemon [ --help | ... ]\n"
@@ -167,6 +170,22 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 
 	daemonFlags.ParseFlags(args, true)
+
+	// Remember the flags set on the command line, the configuration file
+	// must not set them again.
+	setFlags := make(map[string]bool)
+	visitSet := func(f *flag.Flag) {
+		for _, name := range f.Names {
+			setFlags[strings.TrimPrefix(name, "#")] = true
+		}
+	}
+	daemonFlags.Visit(visitSet)
+	flag.CommandLine.Visit(visitSet)
+	isSet := func(name string) bool { return setFlags[name] }
+
+	if err := cli.loadConfigFile(isSet); err != nil {
+		logrus.Fatalf("Error loading the daemon configuration file: %v", err)
+	}
 	commonFlags.PostParse()
 
 	if len(commonFlags.Hosts) == 0 {
@@ -242,6 +261,18 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 
 	logrus.Info("Daemon has completed initialization")
 
+	if cli.Config.MetricsAddress != "" {
+		if err := startMetricsServer(d, cli.Config.MetricsAddress); err != nil {
+			logrus.Fatalf("Error starting the metrics server: %v", err)
+		}
+	}
+
+	setupConfigReloadTrap(func() {
+		if err := cli.reloadConfigFile(d, registryService, isSet); err != nil {
+			logrus.Errorf("Error reloading the daemon configuration file: %v", err)
+		}
+	})
+
 	logrus.WithFields(logrus.Fields{
 		"version":     dockerversion.VERSION,
 		"commit":      dockerversion.GITCOMMIT,
@@ -268,7 +299,7 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	signal.Trap(func() {
 		api.Close()
 		<-serveAPIWait
-		shutdownDaemon(d, 15)
+		shutdownDaemon(d)
 		if pfile != nil {
 			if err := pfile.Remove(); err != nil {
 				logrus.Error(err)
@@ -284,7 +315,7 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	// Daemon is fully initialized and handling API traffic
 	// Wait for serve API to complete
 	errAPI := <-serveAPIWait
-	shutdownDaemon(d, 15)
+	shutdownDaemon(d)
 	if errAPI != nil {
 		if pfile != nil {
 			if err := pfile.Remove(); err != nil {
@@ -296,10 +327,92 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	return nil
 }
 
+// loadConfigFile merges the options of the daemon configuration file into the
+// daemon flags. A missing file is only an error when its path was set on the
+// command line.
+func (cli *DaemonCli) loadConfigFile(isSet func(name string) bool) error {
+	options, err := daemon.ReadConfigFile(cli.ConfigFile)
+	if err != nil {
+		if os.IsNotExist(err) && !isSet("-config-file") {
+			return nil
+		}
+		return err
+	}
+	if err := daemon.ValidateConfigFile(options, daemonFlags, isSet); err != nil {
+		return err
+	}
+	return daemon.ApplyConfigFile(options, daemonFlags)
+}
+
+// reloadConfigFile reads the daemon configuration file again and applies the
+// options which can be changed while the daemon is running: labels, debug,
+// the default log driver and its options, the registry mirrors, the insecure
+// registries and the cluster discovery. The options which are not in the
+// file anymore keep their current value.
+func (cli *DaemonCli) reloadConfigFile(d *daemon.Daemon, registryService *registry.Service, isSet func(name string) bool) error {
+	options, err := daemon.ReadConfigFile(cli.ConfigFile)
+	if err != nil {
+		return err
+	}
+	if err := daemon.ValidateConfigFile(options, daemonFlags, isSet); err != nil {
+		return err
+	}
+
+	// Parse the options again on flags of their own, so that the current
+	// configuration stays untouched if they are invalid.
+	flags := flag.NewFlagSet("reload", flag.ContinueOnError)
+	config := new(daemon.Config)
+	config.LogConfig.Config = make(map[string]string)
+	config.InstallFlags(flags, absentFromHelp)
+	mirrors := opts.NewListOpts(registry.ValidateMirror)
+	flags.Var(&mirrors, []string{"-registry-mirror"}, "")
+	insecureRegistries := opts.NewListOpts(registry.ValidateIndexName)
+	flags.Var(&insecureRegistries, []string{"-insecure-registry"}, "")
+	var debug bool
+	flags.BoolVar(&debug, []string{"D", "-debug"}, false, "")
+	if err := daemon.ApplyConfigFile(options, flags); err != nil {
+		return err
+	}
+
+	reloaded := func(name string) bool {
+		_, ok := options[name]
+		return ok
+	}
+	if err := d.Reload(config, reloaded); err != nil {
+		return err
+	}
+
+	if reloaded("registry-mirror") || reloaded("insecure-registry") {
+		if reloaded("registry-mirror") {
+			cli.registryOptions.Mirrors = mirrors
+		}
+		if reloaded("insecure-registry") {
+			cli.registryOptions.InsecureRegistries = insecureRegistries
+		}
+		registryService.Reconfigure(cli.registryOptions)
+	}
+
+	if reloaded("debug") {
+		if debug {
+			os.Setenv("DEBUG", "1")
+			logrus.SetLevel(logrus.DebugLevel)
+		} else {
+			os.Unsetenv("DEBUG")
+			lvl, err := logrus.ParseLevel(commonFlags.LogLevel)
+			if err != nil {
+				lvl = logrus.InfoLevel
+			}
+			logrus.SetLevel(lvl)
+		}
+	}
+	return nil
+}
+
 // shutdownDaemon just wraps daemon.Shutdown() to handle a timeout in case
 // d.Shutdown() is waiting too long to kill container or worst it's
-// blocked there
-func shutdownDaemon(d *daemon.Daemon, timeout time.Duration) {
+// blocked there. The timeout leaves the containers their stop timeout.
+func shutdownDaemon(d *daemon.Daemon) {
+	timeout := time.Duration(d.ShutdownTimeout()) * time.Second
 	ch := make(chan struct{})
 	go func() {
 		d.Shutdown()
@@ -308,7 +421,29 @@ func shutdownDaemon(d *daemon.Daemon, timeout time.Duration) {
 	select {
 	case <-ch:
 		logrus.Debug("Clean shutdown succeeded")
-	case <-time.After(timeout * time.Second):
+	case <-time.After(timeout):
 		logrus.Error("Force shutdown daemon")
 	}
 }
+
+// startMetricsServer serves the metrics of the daemon in the Prometheus text
+// format on /metrics at addr.
+func startMetricsServer(d *daemon.Daemon, addr string) error {
+	if err := d.RegisterMetrics(); err != nil {
+		return err
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		logrus.Infof("Serving the metrics on %s", l.Addr())
+		if err := http.Serve(l, mux); err != nil {
+			logrus.Errorf("Metrics server error: %v", err)
+		}
+	}()
+	return nil
+}
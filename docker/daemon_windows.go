@@ -31,3 +31,8 @@ func getDaemonConfDir() string {
 // notifySystem sends a message to the host when the server is ready to be used
 func notifySystem() {
 }
+
+// setupConfigReloadTrap doesn't do anything on windows, the configuration
+// file is only read when the daemon starts.
+func setupConfigReloadTrap(reload func()) {
+}
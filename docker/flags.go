@@ -24,6 +24,7 @@ var dockerCommands = []command{
 	{"attach", "Attach to a running container"},
 	{"build", "Build an image from a Dockerfile"},
 	{"commit", "Create a new image from a container's changes"},
+	{"container", "Manage containers"},
 	{"cp", "Copy files/folders between a container and the local filesystem"},
 	{"create", "Create a new container"},
 	{"diff", "Inspect changes on a container's filesystem"},
@@ -31,6 +32,7 @@ var dockerCommands = []command{
 	{"exec", "Run a command in a running container"},
 	{"export", "Export a container's filesystem as a tar archive"},
 	{"history", "Show the history of an image"},
+	{"image", "Manage images"},
 	{"images", "List images"},
 	{"import", "Import the contents from a tarball to create a filesystem image"},
 	{"info", "Display system-wide information"},
@@ -55,9 +57,11 @@ var dockerCommands = []command{
 	{"start", "Start one or more stopped containers"},
 	{"stats", "Display a live stream of container(s) resource usage statistics"},
 	{"stop", "Stop a running container"},
+	{"system", "Manage Docker"},
 	{"tag", "Tag an image into a repository"},
 	{"top", "Display the running processes of a container"},
 	{"unpause", "Unpause all processes within a container"},
+	{"update", "Update resources of one or more containers"},
 	{"version", "Show the Docker version information"},
 	{"volume", "Manage Docker volumes"},
 	{"wait", "Block until a container stops, then print its exit code"},
@@ -127,6 +127,15 @@ var (
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
 
+	// ErrorCodeBlkioDevice is generated when a device given for a blkio
+	// setting of a container is not a block device of the host.
+	ErrorCodeBlkioDevice = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "BLKIODEVICE",
+		Message:        "invalid block device %q for blkio setting: %v",
+		Description:    "The device given for a blkio setting of a container is not a block device of the host",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
 	// ErrorCodeEmptyEndpoint is generated when the endpoint for a port
 	// map is nil.
 	ErrorCodeEmptyEndpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
@@ -402,6 +411,15 @@ var (
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
 
+	// ErrorCodeTmpfsDup is generated when a tmpfs is mounted on the
+	// destination of a volume.
+	ErrorCodeTmpfsDup = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "TMPFSDUP",
+		Message:        "Duplicate mount point %s: it is both a tmpfs and a volume",
+		Description:    "An attempt was made to mount a tmpfs on the destination of a volume",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
 	// ErrorCodeVolumeDup is generated when we try to mount two volumes
 	// to the same path.
 	ErrorCodeVolumeDup = errcode.Register(errGroup, errcode.ErrorDescriptor{
@@ -827,4 +845,22 @@ var (
 		Description:    "While trying to delete a container, there was an error trying to delete one of its volumes",
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
+
+	// ErrorCodeCantUpdate is generated when we try to update the resource
+	// limits of a container but failed.
+	ErrorCodeCantUpdate = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CANTUPDATE",
+		Message:        "Cannot update container %s: %v",
+		Description:    "An error occurred while trying to update the resource limits of a container",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeUpdateKernelMemory is generated when we try to update the
+	// kernel memory limit of a running container.
+	ErrorCodeUpdateKernelMemory = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "UPDATEKERNELMEMORY",
+		Message:        "Cannot update kernel memory of running container %s, please stop it first",
+		Description:    "The kernel memory limit can only be changed while the container is stopped",
+		HTTPStatusCode: http.StatusConflict,
+	})
 )
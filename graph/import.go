@@ -74,6 +74,10 @@ func (s *TagStore) Import(src string, repo string, tag string, msg string, inCon
 		logID = utils.ImageReference(logID, tag)
 	}
 
-	s.eventsService.Log("import", logID, "")
+	var refName string
+	if repo != "" {
+		refName = utils.ImageReference(repo, tag)
+	}
+	s.logImageEvent(logID, refName, "import")
 	return nil
 }
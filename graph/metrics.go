@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	pullBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "engine_daemon_image_pull_bytes_total",
+		Help: "The number of bytes of the image layers downloaded from the registries.",
+	})
+	pushBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "engine_daemon_image_push_bytes_total",
+		Help: "The number of bytes of the image layers uploaded to the registries.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(pullBytes)
+	prometheus.MustRegister(pushBytes)
+}
+
+// countingReader adds the number of bytes read to a counter.
+type countingReader struct {
+	io.ReadCloser
+	counter prometheus.Counter
+}
+
+func newCountingReader(r io.ReadCloser, counter prometheus.Counter) io.ReadCloser {
+	return &countingReader{ReadCloser: r, counter: counter}
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.counter.Add(float64(n))
+	return n, err
+}
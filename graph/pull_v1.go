@@ -319,7 +319,7 @@ func (p *v1Puller) pullImage(out io.Writer, imgID, endpoint string) (layersDownl
 
 				err = p.graph.Register(img,
 					progressreader.New(progressreader.Config{
-						In:        layer,
+						In:        newCountingReader(layer, pullBytes),
 						Out:       broadcaster,
 						Formatter: p.sf,
 						Size:      imgSize,
@@ -145,7 +145,7 @@ func (p *v2Puller) download(di *downloadInfo) {
 	}
 
 	reader := progressreader.New(progressreader.Config{
-		In:        ioutil.NopCloser(io.TeeReader(layerDownload, verifier)),
+		In:        newCountingReader(ioutil.NopCloser(io.TeeReader(layerDownload, verifier)), pullBytes),
 		Out:       di.broadcaster,
 		Formatter: p.sf,
 		Size:      di.size,
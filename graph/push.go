@@ -115,7 +115,7 @@ func (s *TagStore) Push(localName string, imagePushConfig *ImagePushConfig) erro
 
 		}
 
-		s.eventsService.Log("push", repoInfo.LocalName, "")
+		s.logImageEvent(repoInfo.LocalName, repoInfo.LocalName, "push")
 		return nil
 	}
 
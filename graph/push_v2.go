@@ -239,7 +239,7 @@ func (p *v2Pusher) pushV2Image(bs distribution.BlobService, img *image.Image) (d
 	tee := io.TeeReader(arch, digester.Hash())
 
 	reader := progressreader.New(progressreader.Config{
-		In:        ioutil.NopCloser(tee), // we'll take care of close here.
+		In:        newCountingReader(ioutil.NopCloser(tee), pushBytes), // we'll take care of close here.
 		Out:       out,
 		Formatter: p.sf,
 
@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	"github.com/docker/distribution/digest"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/graph/tags"
 	"github.com/docker/docker/image"
@@ -414,6 +415,20 @@ func (store *TagStore) GetRepoRefs() map[string][]string {
 }
 
 // validateRepoName validates the name of a repository.
+// logImageEvent logs an event of the image identified by id, with the
+// reference the action applies to, if any, as its name.
+func (store *TagStore) logImageEvent(id, refName, action string) {
+	attributes := map[string]string{}
+	if refName != "" {
+		attributes["name"] = refName
+	}
+	actor := eventtypes.Actor{
+		ID:         id,
+		Attributes: attributes,
+	}
+	store.eventsService.Log(action, eventtypes.ImageEventType, actor)
+}
+
 func validateRepoName(name string) error {
 	if name == "" {
 		return fmt.Errorf("Repository name can't be empty")
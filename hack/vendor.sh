@@ -49,8 +49,17 @@ clone git github.com/opencontainers/runc fba07bce72e72ce5b2dd618e4f67dd86ccb49c8
 # libcontainer deps (see src/github.com/docker/libcontainer/update-vendor.sh)
 clone git github.com/coreos/go-systemd v3
 clone git github.com/godbus/dbus v2
+clone git github.com/seccomp/libseccomp-golang 1b506fc7c24e
 clone git github.com/syndtr/gocapability 66ef2aa7a23ba682594e2b6f74cf40c0692b49fb
 clone git github.com/golang/protobuf 655cdfa588ea
+
+# prometheus metrics
+clone git github.com/prometheus/client_golang v0.9.0
+clone git github.com/prometheus/client_model 6f3806018612
+clone git github.com/prometheus/common 7e9e6cabbd39
+clone git github.com/prometheus/procfs 1dc9a6cbc91a
+clone git github.com/beorn7/perks v1.0.1
+clone git github.com/matttproud/golang_protobuf_extensions v1.0.1
 clone git github.com/Graylog2/go-gelf 6c62a85f1d47a67f2a5144c0e745b325889a8120
 
 clone git github.com/fluent/fluent-logger-golang v1.0.0
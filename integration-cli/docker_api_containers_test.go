@@ -1525,3 +1525,52 @@ func (s *DockerSuite) TestContainersApiGetContainersJSONEmpty(c *check.C) {
 		c.Fatalf("Expected empty response to be `[]`, got %q", string(body))
 	}
 }
+
+func (s *DockerSuite) TestContainerApiCreateAutoRemoveWithRestartPolicy(c *check.C) {
+	config := map[string]interface{}{
+		"Image": "busybox",
+		"HostConfig": map[string]interface{}{
+			"AutoRemove":    true,
+			"RestartPolicy": map[string]interface{}{"Name": "always"},
+		},
+	}
+
+	status, body, err := sockRequest("POST", "/containers/create", config)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.Equals, http.StatusInternalServerError)
+	c.Assert(string(body), check.Matches, "(?s).*Can't create 'AutoRemove' container with restart policy.*")
+}
+
+func (s *DockerSuite) TestContainerApiWaitAutoRemove(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	config := map[string]interface{}{
+		"Image":      "busybox",
+		"Cmd":        []string{"sh", "-c", "exit 3"},
+		"HostConfig": map[string]interface{}{"AutoRemove": true},
+	}
+
+	status, body, err := sockRequest("POST", "/containers/create", config)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.Equals, http.StatusCreated)
+	var container types.ContainerCreateResponse
+	c.Assert(json.Unmarshal(body, &container), check.IsNil)
+
+	// The wait is acknowledged before the container is started, and
+	// returns its exit code once it has been removed.
+	res, reader, err := sockRequestRaw("POST", "/containers/"+container.ID+"/wait", nil, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(res.StatusCode, check.Equals, http.StatusOK)
+	defer reader.Close()
+
+	status, _, err = sockRequest("POST", "/containers/"+container.ID+"/start", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.Equals, http.StatusNoContent)
+
+	var wait types.ContainerWaitResponse
+	c.Assert(json.NewDecoder(reader).Decode(&wait), check.IsNil)
+	c.Assert(wait.StatusCode, check.Equals, 3)
+
+	status, _, err = sockRequest("GET", "/containers/"+container.ID+"/json", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.Equals, http.StatusNotFound)
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-check/check"
@@ -27,3 +32,68 @@ func (s *DockerSuite) TestEventsApiEmptyOutput(c *check.C) {
 		c.Fatal("timeout waiting for events api to respond, should have responded immediately")
 	}
 }
+
+func (s *DockerSuite) TestEventsApiTypedAndLegacy(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
+	dockerCmd(c, "volume", "create", "--name", "apieventsvolume")
+	dockerCmd(c, "run", "--name", "apieventscontainer", "busybox", "true")
+	until := daemonTime(c).Unix()
+
+	type event struct {
+		Status string `json:"status"`
+		ID     string `json:"id"`
+		From   string `json:"from"`
+		Type   string
+		Action string
+		Actor  struct {
+			ID         string
+			Attributes map[string]string
+		}
+	}
+	decodeEvents := func(endpoint string) []event {
+		status, body, err := sockRequest("GET", endpoint, nil)
+		c.Assert(err, check.IsNil)
+		c.Assert(status, check.Equals, http.StatusOK)
+		var events []event
+		dec := json.NewDecoder(bytes.NewReader(body))
+		for {
+			var ev event
+			if err := dec.Decode(&ev); err == io.EOF {
+				break
+			} else {
+				c.Assert(err, check.IsNil)
+			}
+			events = append(events, ev)
+		}
+		return events
+	}
+
+	var volumeCreate, containerDie bool
+	for _, ev := range decodeEvents(fmt.Sprintf("/events?since=%d&until=%d", since, until)) {
+		switch {
+		case ev.Type == "volume" && ev.Action == "create" && ev.Actor.ID == "apieventsvolume":
+			c.Assert(ev.Actor.Attributes["driver"], check.Equals, "local")
+			c.Assert(ev.Status, check.Equals, "")
+			volumeCreate = true
+		case ev.Type == "container" && ev.Action == "die" && ev.Actor.Attributes["name"] == "apieventscontainer":
+			c.Assert(ev.Actor.Attributes["exitCode"], check.Equals, "0")
+			c.Assert(ev.Actor.Attributes["image"], check.Equals, "busybox")
+			c.Assert(ev.Status, check.Equals, "die")
+			c.Assert(ev.From, check.Equals, "busybox")
+			containerDie = true
+		}
+	}
+	c.Assert(volumeCreate, check.Equals, true)
+	c.Assert(containerDie, check.Equals, true)
+
+	// The clients of older versions of the API only get the container and
+	// image events, without a type nor an actor.
+	for _, ev := range decodeEvents(fmt.Sprintf("/v1.20/events?since=%d&until=%d", since, until)) {
+		c.Assert(ev.Type, check.Equals, "")
+		c.Assert(ev.Action, check.Equals, "")
+		c.Assert(ev.Actor.ID, check.Equals, "")
+		c.Assert(strings.Contains(ev.ID, "apieventsvolume"), check.Equals, false)
+		c.Assert(ev.Status, check.Not(check.Equals), "")
+	}
+}
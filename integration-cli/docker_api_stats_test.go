@@ -146,3 +146,19 @@ func (s *DockerSuite) TestApiStatsContainerNotFound(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(status, check.Equals, http.StatusNotFound)
 }
+
+func (s *DockerSuite) TestApiStatsLogMessagesDropped(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-opt", "mode=non-blocking", "busybox", "top")
+	id := strings.TrimSpace(out)
+	c.Assert(waitRun(id), check.IsNil)
+
+	_, body, err := sockRequestRaw("GET", fmt.Sprintf("/containers/%s/stats?stream=false", id), nil, "")
+	c.Assert(err, check.IsNil)
+	var st map[string]interface{}
+	err = json.NewDecoder(body).Decode(&st)
+	c.Assert(err, check.IsNil)
+	body.Close()
+
+	c.Assert(st["log_messages_dropped"], check.Equals, float64(0))
+}
@@ -0,0 +1,32 @@
+// +build !windows
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestApiUpdateContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, memoryLimitSupport)
+
+	name := "apiUpdateContainer"
+	hostConfig := map[string]interface{}{
+		"Memory":     314572800,
+		"MemorySwap": 524288000,
+	}
+	dockerCmd(c, "run", "-d", "--name", name, "-m", "200M", "busybox", "top")
+	status, _, err := sockRequest("POST", "/containers/"+name+"/update", hostConfig)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+
+	memory, err := inspectField(name, "HostConfig.Memory")
+	c.Assert(err, checker.IsNil)
+	c.Assert(memory, checker.Equals, "314572800")
+	memorySwap, err := inspectField(name, "HostConfig.MemorySwap")
+	c.Assert(err, checker.IsNil)
+	c.Assert(memorySwap, checker.Equals, "524288000")
+}
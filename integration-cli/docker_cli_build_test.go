@@ -1898,8 +1898,8 @@ func (s *DockerSuite) TestBuildCancellationKillsSleep(c *check.C) {
 	go func() {
 		cid := <-containerID
 
-		matchStart := regexp.MustCompile(cid + `(.*) start$`)
-		matchDie := regexp.MustCompile(cid + `(.*) die$`)
+		matchStart := regexp.MustCompile(` start ` + cid)
+		matchDie := regexp.MustCompile(` die ` + cid)
 
 		//
 		// Read lines of `docker events` looking for container start and stop.
@@ -5674,6 +5674,33 @@ func (s *DockerSuite) TestBuildStopSignal(c *check.C) {
 	}
 }
 
+func (s *DockerSuite) TestBuildStopTimeout(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "test_build_stop_timeout"
+	_, err := buildImage(name,
+		`FROM busybox
+		 STOPTIMEOUT 30`,
+		true)
+	c.Assert(err, check.IsNil)
+	res, err := inspectFieldJSON(name, "Config.StopTimeout")
+	c.Assert(err, check.IsNil)
+	c.Assert(res, check.Equals, "30")
+
+	// The containers of the image inherit its stop timeout.
+	dockerCmd(c, "create", "--name", "stop_timeout", name)
+	res, err = inspectFieldJSON("stop_timeout", "Config.StopTimeout")
+	c.Assert(err, check.IsNil)
+	c.Assert(res, check.Equals, "30")
+
+	_, err = buildImage(name,
+		`FROM busybox
+		 STOPTIMEOUT forever`,
+		true)
+	if err == nil || !strings.Contains(err.Error(), "STOPTIMEOUT requires a number of seconds") {
+		c.Fatalf("Expected a non-numeric STOPTIMEOUT to fail the build, got %v", err)
+	}
+}
+
 func (s *DockerSuite) TestBuildBuildTimeArg(c *check.C) {
 	testRequires(c, DaemonIsLinux)
 	imgName := "bldargtest"
@@ -8,14 +8,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/docker/libnetwork/iptables"
 	"github.com/docker/libtrust"
 	"github.com/go-check/check"
@@ -1715,3 +1718,219 @@ func (s *DockerDaemonSuite) TestDaemonCorruptedFluentdAddress(c *check.C) {
 		c.Fatalf("Expected %q message; but doesn't exist in log: %q, err: %v", expected, out, err)
 	}
 }
+
+func (s *DockerDaemonSuite) TestDaemonLiveRestore(c *check.C) {
+	c.Assert(s.d.StartWithBusybox("--live-restore"), check.IsNil)
+
+	out, err := s.d.Cmd("run", "-d", "--name", "live", "--net", "host", "busybox", "sh", "-c", "while true; do echo tick; sleep 1; done")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("run", "-d", "--name", "bridged", "busybox", "top")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("run", "-d", "--name", "exiting", "--net", "none", "busybox", "sh", "-c", "while [ ! -f /stop ]; do sleep 1; done; exit 3")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	pid, err := s.d.Cmd("inspect", "-f", "{{.State.Pid}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(pid))
+
+	c.Assert(s.d.Restart("--live-restore"), check.IsNil)
+
+	// The container on the host network kept running, the other one was stopped
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}} {{.State.Pid}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), check.Equals, "true "+strings.TrimSpace(pid))
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}}", "bridged")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), check.Equals, "false")
+
+	// The output is collected again after the restart
+	out, err = s.d.Cmd("logs", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	before := strings.Count(out, "tick")
+	time.Sleep(3 * time.Second)
+	out, err = s.d.Cmd("logs", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	if after := strings.Count(out, "tick"); after <= before {
+		c.Fatalf("Expected logs of the restored container to grow, got %d lines before and %d after", before, after)
+	}
+
+	// The exit code of a restored container is recorded by its init process
+	out, err = s.d.Cmd("exec", "exiting", "touch", "/stop")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("wait", "exiting")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), check.Equals, "3")
+
+	// The restored container is supervised by the new daemon
+	out, err = s.d.Cmd("stop", "-t", "1", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), check.Equals, "false")
+}
+
+func (s *DockerDaemonSuite) TestDaemonLiveRestoreExitedWhileDown(c *check.C) {
+	c.Assert(s.d.StartWithBusybox("--live-restore"), check.IsNil)
+
+	dir, err := ioutil.TempDir("", "live-restore")
+	c.Assert(err, check.IsNil)
+	defer os.RemoveAll(dir)
+
+	script := "while [ ! -f /ctl/stop ]; do sleep 0.1; done; exit 0"
+	out, err := s.d.Cmd("run", "-d", "--name", "onfailure", "--net", "none", "--restart", "on-failure", "-v", dir+":/ctl", "busybox", "sh", "-c", script)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("run", "-d", "--name", "autoremove", "--net", "none", "--rm", "-v", dir+":/ctl", "busybox", "sh", "-c", script)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	c.Assert(s.d.Stop(), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "stop"), nil, 0644), check.IsNil)
+	time.Sleep(time.Second)
+	c.Assert(s.d.Start("--live-restore"), check.IsNil)
+
+	// The containers exited successfully while the daemon was down, so
+	// they are neither restarted nor killed
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}} {{.State.ExitCode}} {{.RestartCount}}", "onfailure")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), check.Equals, "false 0 0")
+	out, err = s.d.Cmd("ps", "-a", "-q", "--filter", "name=autoremove")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), check.Equals, "")
+}
+
+func (s *DockerDaemonSuite) TestDaemonConfigFileReload(c *check.C) {
+	configFile := filepath.Join(s.d.folder, "daemon.json")
+	c.Assert(ioutil.WriteFile(configFile, []byte(`{"label": ["foo=bar"]}`), 0644), check.IsNil)
+
+	c.Assert(s.d.Start("--config-file", configFile), check.IsNil)
+
+	out, err := s.d.Cmd("info")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, check.Matches, "(?s).*foo=bar.*")
+
+	c.Assert(ioutil.WriteFile(configFile, []byte(`{"label": ["foo=baz"], "debug": true}`), 0644), check.IsNil)
+	c.Assert(s.d.cmd.Process.Signal(syscall.SIGHUP), check.IsNil)
+
+	var info string
+	for i := 0; i < 50; i++ {
+		info, err = s.d.Cmd("info")
+		c.Assert(err, check.IsNil, check.Commentf(info))
+		if strings.Contains(info, "foo=baz") {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(info, check.Matches, "(?s).*foo=baz.*")
+	c.Assert(info, check.Not(check.Matches), "(?s).*foo=bar.*")
+	c.Assert(info, check.Matches, "(?s).*Debug mode \\(server\\): true.*")
+
+	// An invalid file is rejected and the configuration stays the same
+	c.Assert(ioutil.WriteFile(configFile, []byte(`{"label": ["foo=qux"], "log-driver": "nosuchdriver"}`), 0644), check.IsNil)
+	c.Assert(s.d.cmd.Process.Signal(syscall.SIGHUP), check.IsNil)
+	time.Sleep(time.Second)
+	out, err = s.d.Cmd("info")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, check.Matches, "(?s).*foo=baz.*")
+}
+
+func (s *DockerDaemonSuite) TestDaemonConfigFileConflicts(c *check.C) {
+	configFile := filepath.Join(s.d.folder, "daemon.json")
+	c.Assert(ioutil.WriteFile(configFile, []byte(`{"label": ["foo=bar"]}`), 0644), check.IsNil)
+
+	c.Assert(s.d.Start("--config-file", configFile, "--label", "foo=baz"), check.NotNil)
+	content, _ := ioutil.ReadFile(s.d.logFile.Name())
+	c.Assert(string(content), check.Matches, "(?s).*set both as flags and in the configuration file: label.*")
+
+	c.Assert(s.d.Start("--config-file", filepath.Join(s.d.folder, "missing.json")), check.NotNil)
+}
+
+func (s *DockerDaemonSuite) TestDaemonUserNamespaceRemap(c *check.C) {
+	testRequires(c, SameHostDaemon, NativeExecDriver, userNamespaceRemap)
+	c.Assert(s.d.StartWithBusybox("--userns-remap", "dockremap"), check.IsNil)
+
+	out, err := s.d.Cmd("info")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.Contains(out, "User Namespace Remapping:"), check.Equals, true, check.Commentf(out))
+	var containerID, hostUID, size int
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, " UID Map: 0 ") {
+			_, err = fmt.Sscanf(line, " UID Map: %d %d %d", &containerID, &hostUID, &size)
+			c.Assert(err, check.IsNil, check.Commentf(line))
+			break
+		}
+	}
+	c.Assert(hostUID > 0, check.Equals, true, check.Commentf(out))
+
+	// Root in the container owns the files it creates, which are owned by
+	// the remapped root on the host.
+	out, err = s.d.Cmd("run", "--name", "userns", "-v", "/data", "busybox", "sh", "-c", "touch /data/file && id -u && stat -c %u /data/file")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.Fields(out), check.DeepEquals, []string{"0", "0"})
+
+	source, err := s.d.Cmd("inspect", "-f", "{{(index .Mounts 0).Source}}", "userns")
+	c.Assert(err, check.IsNil, check.Commentf(source))
+	fi, err := os.Stat(filepath.Join(strings.TrimSpace(source), "file"))
+	c.Assert(err, check.IsNil)
+	c.Assert(int(fi.Sys().(*syscall.Stat_t).Uid), check.Equals, hostUID)
+
+	// Settings giving access to host resources are refused.
+	out, err = s.d.Cmd("run", "--privileged", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(strings.Contains(out, "Privileged mode is incompatible with user namespaces"), check.Equals, true, check.Commentf(out))
+}
+
+func (s *DockerDaemonSuite) TestDaemonEventsJournal(c *check.C) {
+	testRequires(c, SameHostDaemon)
+	c.Assert(s.d.StartWithBusybox(), check.IsNil)
+	since := time.Now().Unix()
+
+	// More events than the ones kept in memory are logged.
+	for i := 0; i < 40; i++ {
+		out, err := s.d.Cmd("create", "--name", fmt.Sprintf("journal%d", i), "busybox", "true")
+		c.Assert(err, check.IsNil, check.Commentf(out))
+		out, err = s.d.Cmd("rm", fmt.Sprintf("journal%d", i))
+		c.Assert(err, check.IsNil, check.Commentf(out))
+	}
+
+	// The events are replayed from the journal after a restart.
+	c.Assert(s.d.Restart(), check.IsNil)
+	out, err := s.d.Cmd("events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", time.Now().Unix()))
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.Count(out, "container create "), check.Equals, 40, check.Commentf(out))
+	c.Assert(strings.Count(out, "container destroy "), check.Equals, 40, check.Commentf(out))
+
+	// Without the journal, only the events logged since the restart are kept.
+	c.Assert(s.d.Restart("--events-log-max-size=0"), check.IsNil)
+	out, err = s.d.Cmd("events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", time.Now().Unix()))
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.Contains(out, "container create "), check.Equals, false, check.Commentf(out))
+}
+
+func (s *DockerDaemonSuite) TestDaemonMetricsAddr(c *check.C) {
+	testRequires(c, SameHostDaemon)
+	c.Assert(s.d.StartWithBusybox("--metrics-addr=127.0.0.1:9323"), check.IsNil)
+
+	out, err := s.d.Cmd("run", "-d", "--name", "metrics", "busybox", "top")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	id := strings.TrimSpace(out)
+
+	// The stats of the containers are collected every second.
+	var metrics string
+	for i := 0; i < 10; i++ {
+		resp, err := http.Get("http://127.0.0.1:9323/metrics")
+		c.Assert(err, check.IsNil)
+		c.Assert(resp.StatusCode, check.Equals, http.StatusOK)
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		c.Assert(err, check.IsNil)
+		metrics = string(b)
+		if strings.Contains(metrics, id) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	c.Assert(metrics, checker.Contains, `engine_daemon_container_actions_total{action="start"} 1`)
+	c.Assert(metrics, checker.Contains, fmt.Sprintf(`engine_daemon_container_memory_usage_bytes{id="%s",name="metrics"}`, id))
+	c.Assert(metrics, checker.Contains, fmt.Sprintf(`engine_daemon_container_cpu_usage_seconds_total{id="%s",name="metrics"}`, id))
+	c.Assert(metrics, checker.Contains, `engine_daemon_api_request_duration_seconds_count{method="POST",route="/containers/{name:.*}/start"} 1`)
+	c.Assert(metrics, checker.Contains, "engine_daemon_events_subscribers ")
+}
@@ -80,19 +80,19 @@ func (s *DockerSuite) TestEventsContainerFailStartDie(c *check.C) {
 		c.Fatalf("Container run with command blerg should have failed, but it did not")
 	}
 
-	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	if len(events) <= 1 {
 		c.Fatalf("Missing expected event")
 	}
 
-	startEvent := strings.Fields(events[len(events)-3])
-	dieEvent := strings.Fields(events[len(events)-2])
+	startEvent := parseEventAction(c, events[len(events)-3])
+	dieEvent := parseEventAction(c, events[len(events)-2])
 
-	if startEvent[len(startEvent)-1] != "start" {
+	if startEvent != "start" {
 		c.Fatalf("event should be start, not %#v", startEvent)
 	}
-	if dieEvent[len(dieEvent)-1] != "die" {
+	if dieEvent != "die" {
 		c.Fatalf("event should be die, not %#v", dieEvent)
 	}
 
@@ -121,41 +121,43 @@ func (s *DockerSuite) TestEventsLimit(c *check.C) {
 		}
 	}
 
+	// The events are replayed from the journal of the daemon, beyond the
+	// last 64 ones kept in memory.
 	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
 	events := strings.Split(out, "\n")
 	nEvents := len(events) - 1
-	if nEvents != 64 {
-		c.Fatalf("events should be limited to 64, but received %d", nEvents)
+	if nEvents < 17*5 {
+		c.Fatalf("events should not be limited to 64, but received %d", nEvents)
 	}
 }
 
 func (s *DockerSuite) TestEventsContainerEvents(c *check.C) {
 	testRequires(c, DaemonIsLinux)
 	dockerCmd(c, "run", "--rm", "busybox", "true")
-	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	events = events[:len(events)-1]
 	if len(events) < 5 {
 		c.Fatalf("Missing expected event")
 	}
-	createEvent := strings.Fields(events[len(events)-5])
-	attachEvent := strings.Fields(events[len(events)-4])
-	startEvent := strings.Fields(events[len(events)-3])
-	dieEvent := strings.Fields(events[len(events)-2])
-	destroyEvent := strings.Fields(events[len(events)-1])
-	if createEvent[len(createEvent)-1] != "create" {
+	createEvent := parseEventAction(c, events[len(events)-5])
+	attachEvent := parseEventAction(c, events[len(events)-4])
+	startEvent := parseEventAction(c, events[len(events)-3])
+	dieEvent := parseEventAction(c, events[len(events)-2])
+	destroyEvent := parseEventAction(c, events[len(events)-1])
+	if createEvent != "create" {
 		c.Fatalf("event should be create, not %#v", createEvent)
 	}
-	if attachEvent[len(attachEvent)-1] != "attach" {
+	if attachEvent != "attach" {
 		c.Fatalf("event should be attach, not %#v", attachEvent)
 	}
-	if startEvent[len(startEvent)-1] != "start" {
+	if startEvent != "start" {
 		c.Fatalf("event should be start, not %#v", startEvent)
 	}
-	if dieEvent[len(dieEvent)-1] != "die" {
+	if dieEvent != "die" {
 		c.Fatalf("event should be die, not %#v", dieEvent)
 	}
-	if destroyEvent[len(destroyEvent)-1] != "destroy" {
+	if destroyEvent != "destroy" {
 		c.Fatalf("event should be destroy, not %#v", destroyEvent)
 	}
 
@@ -167,30 +169,30 @@ func (s *DockerSuite) TestEventsContainerEventsSinceUnixEpoch(c *check.C) {
 	timeBeginning := time.Unix(0, 0).Format(time.RFC3339Nano)
 	timeBeginning = strings.Replace(timeBeginning, "Z", ".000000000Z", -1)
 	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since='%s'", timeBeginning),
-		fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+		fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	events = events[:len(events)-1]
 	if len(events) < 5 {
 		c.Fatalf("Missing expected event")
 	}
-	createEvent := strings.Fields(events[len(events)-5])
-	attachEvent := strings.Fields(events[len(events)-4])
-	startEvent := strings.Fields(events[len(events)-3])
-	dieEvent := strings.Fields(events[len(events)-2])
-	destroyEvent := strings.Fields(events[len(events)-1])
-	if createEvent[len(createEvent)-1] != "create" {
+	createEvent := parseEventAction(c, events[len(events)-5])
+	attachEvent := parseEventAction(c, events[len(events)-4])
+	startEvent := parseEventAction(c, events[len(events)-3])
+	dieEvent := parseEventAction(c, events[len(events)-2])
+	destroyEvent := parseEventAction(c, events[len(events)-1])
+	if createEvent != "create" {
 		c.Fatalf("event should be create, not %#v", createEvent)
 	}
-	if attachEvent[len(attachEvent)-1] != "attach" {
+	if attachEvent != "attach" {
 		c.Fatalf("event should be attach, not %#v", attachEvent)
 	}
-	if startEvent[len(startEvent)-1] != "start" {
+	if startEvent != "start" {
 		c.Fatalf("event should be start, not %#v", startEvent)
 	}
-	if dieEvent[len(dieEvent)-1] != "die" {
+	if dieEvent != "die" {
 		c.Fatalf("event should be die, not %#v", dieEvent)
 	}
-	if destroyEvent[len(destroyEvent)-1] != "destroy" {
+	if destroyEvent != "destroy" {
 		c.Fatalf("event should be destroy, not %#v", destroyEvent)
 	}
 
@@ -209,19 +211,19 @@ func (s *DockerSuite) TestEventsImageUntagDelete(c *check.C) {
 	if err := deleteImages(name); err != nil {
 		c.Fatal(err)
 	}
-	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=image")
 	events := strings.Split(out, "\n")
 
 	events = events[:len(events)-1]
 	if len(events) < 2 {
 		c.Fatalf("Missing expected event")
 	}
-	untagEvent := strings.Fields(events[len(events)-2])
-	deleteEvent := strings.Fields(events[len(events)-1])
-	if untagEvent[len(untagEvent)-1] != "untag" {
+	untagEvent := parseEventAction(c, events[len(events)-2])
+	deleteEvent := parseEventAction(c, events[len(events)-1])
+	if untagEvent != "untag" {
 		c.Fatalf("untag should be untag, not %#v", untagEvent)
 	}
-	if deleteEvent[len(deleteEvent)-1] != "delete" {
+	if deleteEvent != "delete" {
 		c.Fatalf("delete should be delete, not %#v", deleteEvent)
 	}
 }
@@ -241,11 +243,9 @@ func (s *DockerSuite) TestEventsImageTag(c *check.C) {
 	if len(events) != 1 {
 		c.Fatalf("was expecting 1 event. out=%s", out)
 	}
-	event := strings.TrimSpace(events[0])
-	expectedStr := image + ": tag"
-
-	if !strings.HasSuffix(event, expectedStr) {
-		c.Fatalf("wrong event format. expected='%s' got=%s", expectedStr, event)
+	eventType, action, id, _ := parseEvent(c, events[0])
+	if eventType != "image" || action != "tag" || id != image {
+		c.Fatalf("wrong event. expected an image tag event for %s, got=%s", image, events[0])
 	}
 
 }
@@ -262,9 +262,8 @@ func (s *DockerSuite) TestEventsImagePull(c *check.C) {
 		fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
 
 	events := strings.Split(strings.TrimSpace(out), "\n")
-	event := strings.TrimSpace(events[len(events)-1])
-
-	if !strings.HasSuffix(event, "hello-world:latest: pull") {
+	event := events[len(events)-1]
+	if parseEventAction(c, event) != "pull" || parseEventActorID(c, event) != "hello-world:latest" {
 		c.Fatalf("Missing pull event - got:%q", event)
 	}
 
@@ -289,7 +288,7 @@ func (s *DockerSuite) TestEventsImageImport(c *check.C) {
 	go func() {
 		containerID := <-id
 
-		matchImport := regexp.MustCompile(containerID + `: import$`)
+		matchImport := regexp.MustCompile(`image import ` + containerID + `( |$)`)
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
 			if matchImport.MatchString(scanner.Text()) {
@@ -325,10 +324,9 @@ func (s *DockerSuite) TestEventsFilters(c *check.C) {
 		events := strings.Split(out, "\n")
 		events = events[:len(events)-1]
 		for _, event := range events {
-			eventFields := strings.Fields(event)
-			eventName := eventFields[len(eventFields)-1]
+			eventName := parseEventAction(c, event)
 			if ok, err := regexp.MatchString(match, eventName); err != nil || !ok {
-				c.Fatalf("event should match %s, got %#v, err: %v", match, eventFields, err)
+				c.Fatalf("event should match %s, got %#v, err: %v", match, event, err)
 			}
 		}
 	}
@@ -404,13 +402,8 @@ func (s *DockerSuite) TestEventsFilterContainer(c *check.C) {
 			return fmt.Errorf("expected 3 events, got %v", events)
 		}
 		for _, event := range events {
-			e := strings.Fields(event)
-			if len(e) < 3 {
-				return fmt.Errorf("got malformed event: %s", event)
-			}
-
 			// Check the id
-			parsedID := strings.TrimSuffix(e[1], ":")
+			parsedID := parseEventActorID(c, event)
 			if parsedID != id {
 				return fmt.Errorf("expected event for container id %s: %s - parsed container id: %s", id, event, parsedID)
 			}
@@ -458,10 +451,10 @@ func (s *DockerSuite) TestEventsStreaming(c *check.C) {
 	go func() {
 		containerID := <-id
 
-		matchCreate := regexp.MustCompile(containerID + `: \(from busybox:latest\) create$`)
-		matchStart := regexp.MustCompile(containerID + `: \(from busybox:latest\) start$`)
-		matchDie := regexp.MustCompile(containerID + `: \(from busybox:latest\) die$`)
-		matchDestroy := regexp.MustCompile(containerID + `: \(from busybox:latest\) destroy$`)
+		matchCreate := regexp.MustCompile(`container create ` + containerID + ` \(.*image=busybox:latest.*\)$`)
+		matchStart := regexp.MustCompile(`container start ` + containerID + ` \(.*image=busybox:latest.*\)$`)
+		matchDie := regexp.MustCompile(`container die ` + containerID + ` \(.*image=busybox:latest.*\)$`)
+		matchDestroy := regexp.MustCompile(`container destroy ` + containerID + ` \(.*image=busybox:latest.*\)$`)
 
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
@@ -525,7 +518,7 @@ func (s *DockerSuite) TestEventsCommit(c *check.C) {
 	dockerCmd(c, "stop", cID)
 
 	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, " commit\n") {
+	if !strings.Contains(out, " commit ") {
 		c.Fatalf("Missing 'commit' log event\n%s", out)
 	}
 }
@@ -558,14 +551,14 @@ func (s *DockerSuite) TestEventsCopy(c *check.C) {
 	dockerCmd(c, "cp", "cptest:/tmp/file", tempFile.Name())
 
 	out, _ := dockerCmd(c, "events", "--since=0", "-f", "container=cptest", "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, " archive-path\n") {
+	if !strings.Contains(out, " archive-path ") {
 		c.Fatalf("Missing 'archive-path' log event\n%s", out)
 	}
 
 	dockerCmd(c, "cp", tempFile.Name(), "cptest:/tmp/filecopy")
 
 	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container=cptest", "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, " extract-to-dir\n") {
+	if !strings.Contains(out, " extract-to-dir ") {
 		c.Fatalf("Missing 'extract-to-dir' log event\n%s", out)
 	}
 }
@@ -586,7 +579,7 @@ func (s *DockerSuite) TestEventsResize(c *check.C) {
 	dockerCmd(c, "stop", cID)
 
 	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, " resize\n") {
+	if !strings.Contains(out, " resize ") {
 		c.Fatalf("Missing 'resize' log event\n%s", out)
 	}
 }
@@ -623,7 +616,7 @@ func (s *DockerSuite) TestEventsAttach(c *check.C) {
 	dockerCmd(c, "stop", cID)
 
 	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, " attach\n") {
+	if !strings.Contains(out, " attach ") {
 		c.Fatalf("Missing 'attach' log event\n%s", out)
 	}
 }
@@ -636,7 +629,7 @@ func (s *DockerSuite) TestEventsRename(c *check.C) {
 	dockerCmd(c, "rename", "oldName", "newName")
 
 	out, _ := dockerCmd(c, "events", "--since=0", "-f", "container=newName", "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, " rename\n") {
+	if !strings.Contains(out, " rename ") {
 		c.Fatalf("Missing 'rename' log event\n%s", out)
 	}
 }
@@ -653,7 +646,7 @@ func (s *DockerSuite) TestEventsTop(c *check.C) {
 	dockerCmd(c, "stop", cID)
 
 	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, " top\n") {
+	if !strings.Contains(out, " top ") {
 		c.Fatalf("Missing 'top' log event\n%s", out)
 	}
 }
@@ -682,7 +675,90 @@ func (s *DockerRegistrySuite) TestEventsImageFilterPush(c *check.C) {
 	dockerCmd(c, "push", repoName)
 
 	out, _ = dockerCmd(c, "events", "--since=0", "-f", "image="+repoName, "-f", "event=push", "--until="+strconv.Itoa(int(since)))
-	if !strings.Contains(out, repoName+": push\n") {
+	if !strings.Contains(out, "image push "+repoName) {
 		c.Fatalf("Missing 'push' log event for image %s\n%s", repoName, out)
 	}
 }
+
+func (s *DockerSuite) TestEventsFilterType(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
+	dockerCmd(c, "tag", "busybox", "eventsfiltertype:tag")
+	dockerCmd(c, "run", "--rm", "-v", "eventsfiltertype:/foo", "busybox", "true")
+	until := daemonTime(c).Unix()
+
+	for _, eventType := range []string{"image", "container", "volume"} {
+		out, _ := dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", until), "--filter", "type="+eventType)
+		events := strings.Split(strings.TrimSpace(out), "\n")
+		c.Assert(len(events) > 0 && events[0] != "", check.Equals, true, check.Commentf("no %s event", eventType))
+		for _, event := range events {
+			t, _, _, _ := parseEvent(c, event)
+			c.Assert(t, check.Equals, eventType, check.Commentf(event))
+		}
+	}
+}
+
+func (s *DockerSuite) TestEventsFilterLabels(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
+	out, _ := dockerCmd(c, "run", "-d", "--label", "eventfilter=true", "busybox", "true")
+	labeled := strings.TrimSpace(out)
+	out, _ = dockerCmd(c, "run", "-d", "--label", "eventfilter=false", "busybox", "true")
+	other := strings.TrimSpace(out)
+	dockerCmd(c, "wait", labeled)
+	dockerCmd(c, "wait", other)
+
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "label=eventfilter=true")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(len(events) >= 3, check.Equals, true, check.Commentf(out))
+	for _, event := range events {
+		_, _, id, attributes := parseEvent(c, event)
+		c.Assert(id, check.Equals, labeled)
+		c.Assert(attributes["eventfilter"], check.Equals, "true")
+	}
+}
+
+func (s *DockerSuite) TestEventsVolumeEvents(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
+	dockerCmd(c, "volume", "create", "--name", "eventsvolume")
+	dockerCmd(c, "run", "--name", "eventsvolumecontainer", "-v", "eventsvolume:/foo", "busybox", "true")
+	dockerCmd(c, "rm", "eventsvolumecontainer")
+	dockerCmd(c, "volume", "rm", "eventsvolume")
+
+	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "volume=eventsvolume")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	var actions []string
+	for _, event := range events {
+		eventType, action, id, attributes := parseEvent(c, event)
+		c.Assert(eventType, check.Equals, "volume")
+		c.Assert(id, check.Equals, "eventsvolume")
+		c.Assert(attributes["driver"], check.Equals, "local")
+		actions = append(actions, action)
+	}
+	c.Assert(actions, check.DeepEquals, []string{"create", "mount", "unmount", "destroy"})
+}
+
+func (s *DockerSuite) TestEventsContainerDieKillAttributes(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
+	dockerCmdWithError("run", "--name", "eventsexitcode", "busybox", "sh", "-c", "exit 3")
+	out, _ := dockerCmd(c, "run", "-d", "--name", "eventskill", "busybox", "top")
+	c.Assert(waitRun(strings.TrimSpace(out)), check.IsNil)
+	// top ignores SIGWINCH, the container is still running to be killed.
+	dockerCmd(c, "kill", "-s", "WINCH", "eventskill")
+	dockerCmd(c, "kill", "eventskill")
+
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "event=die", "--filter", "container=eventsexitcode")
+	_, _, _, attributes := parseEvent(c, strings.TrimSpace(out))
+	c.Assert(attributes["exitCode"], check.Equals, "3")
+	c.Assert(attributes["name"], check.Equals, "eventsexitcode")
+
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "event=kill", "--filter", "container=eventskill")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, check.HasLen, 2, check.Commentf(out))
+	_, _, _, attributes = parseEvent(c, events[0])
+	c.Assert(attributes["signal"], check.Equals, "28")
+	_, _, _, attributes = parseEvent(c, events[1])
+	c.Assert(attributes["signal"], check.Equals, "9")
+}
@@ -79,24 +79,24 @@ func (s *DockerSuite) TestEventsOOMDisableFalse(c *check.C) {
 		c.Fatalf("Missing expected event")
 	}
 
-	createEvent := strings.Fields(events[len(events)-5])
-	attachEvent := strings.Fields(events[len(events)-4])
-	startEvent := strings.Fields(events[len(events)-3])
-	oomEvent := strings.Fields(events[len(events)-2])
-	dieEvent := strings.Fields(events[len(events)-1])
-	if createEvent[len(createEvent)-1] != "create" {
+	createEvent := parseEventAction(c, events[len(events)-5])
+	attachEvent := parseEventAction(c, events[len(events)-4])
+	startEvent := parseEventAction(c, events[len(events)-3])
+	oomEvent := parseEventAction(c, events[len(events)-2])
+	dieEvent := parseEventAction(c, events[len(events)-1])
+	if createEvent != "create" {
 		c.Fatalf("event should be create, not %#v", createEvent)
 	}
-	if attachEvent[len(attachEvent)-1] != "attach" {
+	if attachEvent != "attach" {
 		c.Fatalf("event should be attach, not %#v", attachEvent)
 	}
-	if startEvent[len(startEvent)-1] != "start" {
+	if startEvent != "start" {
 		c.Fatalf("event should be start, not %#v", startEvent)
 	}
-	if oomEvent[len(oomEvent)-1] != "oom" {
+	if oomEvent != "oom" {
 		c.Fatalf("event should be oom, not %#v", oomEvent)
 	}
-	if dieEvent[len(dieEvent)-1] != "die" {
+	if dieEvent != "die" {
 		c.Fatalf("event should be die, not %#v", dieEvent)
 	}
 }
@@ -125,21 +125,21 @@ func (s *DockerSuite) TestEventsOOMDisableTrue(c *check.C) {
 			c.Fatalf("Missing expected event")
 		}
 
-		createEvent := strings.Fields(events[len(events)-4])
-		attachEvent := strings.Fields(events[len(events)-3])
-		startEvent := strings.Fields(events[len(events)-2])
-		oomEvent := strings.Fields(events[len(events)-1])
+		createEvent := parseEventAction(c, events[len(events)-4])
+		attachEvent := parseEventAction(c, events[len(events)-3])
+		startEvent := parseEventAction(c, events[len(events)-2])
+		oomEvent := parseEventAction(c, events[len(events)-1])
 
-		if createEvent[len(createEvent)-1] != "create" {
+		if createEvent != "create" {
 			c.Fatalf("event should be create, not %#v", createEvent)
 		}
-		if attachEvent[len(attachEvent)-1] != "attach" {
+		if attachEvent != "attach" {
 			c.Fatalf("event should be attach, not %#v", attachEvent)
 		}
-		if startEvent[len(startEvent)-1] != "start" {
+		if startEvent != "start" {
 			c.Fatalf("event should be start, not %#v", startEvent)
 		}
-		if oomEvent[len(oomEvent)-1] != "oom" {
+		if oomEvent != "oom" {
 			c.Fatalf("event should be oom, not %#v", oomEvent)
 		}
 
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func getHealth(c *check.C, name string) *types.Health {
+	out, err := inspectFieldJSON(name, "State.Health")
+	c.Assert(err, checker.IsNil)
+	var health types.Health
+	c.Assert(json.Unmarshal([]byte(out), &health), checker.IsNil)
+	return &health
+}
+
+func getContainerID(c *check.C, name string) string {
+	id, err := getIDByName(name)
+	c.Assert(err, checker.IsNil)
+	return id
+}
+
+func (s *DockerSuite) TestHealth(c *check.C) {
+	testRequires(c, DaemonIsLinux) // busybox doesn't work on Windows
+
+	name := "fatty"
+	dockerCmd(c, "run", "-d", "--name", name,
+		"--health-cmd", "cat /status",
+		"--health-interval", "1s",
+		"--health-timeout", "30s",
+		"--health-retries", "1",
+		"busybox", "sh", "-c", "echo OK > /status && top")
+
+	// The container writes /status before the first probe, so it becomes healthy.
+	c.Assert(waitInspect(name, "{{.State.Health.Status}}", "healthy", 10*time.Second), checker.IsNil)
+
+	out, _ := dockerCmd(c, "ps", "-q", "--no-trunc", "--filter", "health=healthy")
+	c.Assert(strings.TrimSpace(out), checker.Contains, getContainerID(c, name))
+	out, _ = dockerCmd(c, "ps", "-q", "--no-trunc", "--filter", "health=unhealthy")
+	c.Assert(strings.TrimSpace(out), checker.Not(checker.Contains), getContainerID(c, name))
+
+	// Make it fail
+	dockerCmd(c, "exec", name, "rm", "/status")
+	c.Assert(waitInspect(name, "{{.State.Health.Status}}", "unhealthy", 10*time.Second), checker.IsNil)
+
+	last := getHealth(c, name)
+	c.Assert(last.FailingStreak > 0, checker.Equals, true)
+	c.Assert(last.Log, checker.Not(checker.HasLen), 0)
+	c.Assert(last.Log[len(last.Log)-1].ExitCode, checker.Equals, 1)
+	c.Assert(last.Log[len(last.Log)-1].Output, checker.Contains, "No such file")
+
+	out, _ = dockerCmd(c, "ps", "-q", "--no-trunc", "--filter", "health=unhealthy")
+	c.Assert(strings.TrimSpace(out), checker.Contains, getContainerID(c, name))
+
+	// Fix it again
+	dockerCmd(c, "exec", name, "sh", "-c", "echo OK > /status")
+	c.Assert(waitInspect(name, "{{.State.Health.Status}}", "healthy", 10*time.Second), checker.IsNil)
+
+	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "container="+name)
+	c.Assert(out, checker.Contains, "health_status: healthy")
+	c.Assert(out, checker.Contains, "health_status: unhealthy")
+}
+
+func (s *DockerSuite) TestHealthTimeout(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	name := "test_health_timeout"
+	dockerCmd(c, "run", "-d", "--name", name,
+		"--health-cmd", "sleep 10",
+		"--health-interval", "1s",
+		"--health-timeout", "1s",
+		"--health-retries", "1",
+		"busybox", "top")
+
+	c.Assert(waitInspect(name, "{{.State.Health.Status}}", "unhealthy", 20*time.Second), checker.IsNil)
+
+	last := getHealth(c, name)
+	c.Assert(last.Log, checker.Not(checker.HasLen), 0)
+	c.Assert(last.Log[0].ExitCode, checker.Equals, -1)
+	c.Assert(last.Log[0].Output, checker.Contains, "Health check exceeded timeout")
+}
+
+func (s *DockerSuite) TestHealthNone(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	name := "test_health_none"
+	dockerCmd(c, "run", "-d", "--name", name, "busybox", "top")
+
+	out, _ := dockerCmd(c, "ps", "-q", "--no-trunc", "--filter", "health=none")
+	c.Assert(strings.TrimSpace(out), checker.Contains, getContainerID(c, name))
+
+	out, _, err := dockerCmdWithError("ps", "--filter", "health=sick")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Unrecognised filter value for health")
+}
+
+func (s *DockerSuite) TestHealthInvalidOptions(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	out, _, err := dockerCmdWithError("run", "-d", "--no-healthcheck", "--health-cmd", "true", "busybox", "top")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "--no-healthcheck conflicts with --health-* options")
+
+	out, _, err = dockerCmdWithError("run", "-d", "--health-cmd", "true", "--health-retries", "-1", "busybox", "top")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+}
@@ -119,10 +119,14 @@ func (s *DockerSuite) TestHelpTextVerify(c *check.C) {
 
 		// Add some 'two word' commands - would be nice to automatically
 		// calculate this list - somehow
+		cmdsToTest = append(cmdsToTest, "container prune")
+		cmdsToTest = append(cmdsToTest, "image prune")
 		cmdsToTest = append(cmdsToTest, "volume create")
 		cmdsToTest = append(cmdsToTest, "volume inspect")
 		cmdsToTest = append(cmdsToTest, "volume ls")
+		cmdsToTest = append(cmdsToTest, "volume prune")
 		cmdsToTest = append(cmdsToTest, "volume rm")
+		cmdsToTest = append(cmdsToTest, "system df")
 
 		for _, cmd := range cmdsToTest {
 			var stderr string
@@ -259,7 +263,7 @@ func (s *DockerSuite) TestHelpTextVerify(c *check.C) {
 		}
 
 		// Number of commands for standard release and experimental release
-		standard := 40
+		standard := 43
 		experimental := 1
 		expected := standard + experimental
 		if isLocalDaemon {
@@ -0,0 +1,202 @@
+// +build !windows
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/plugins/logdriver"
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+const testLogDriverSpec = "/etc/docker/plugins/test-external-log-driver.spec"
+
+func init() {
+	check.Suite(&DockerExternalLogDriverSuite{
+		ds: &DockerSuite{},
+	})
+}
+
+type DockerExternalLogDriverSuite struct {
+	server *httptest.Server
+	ds     *DockerSuite
+	d      *Daemon
+
+	mu      sync.Mutex
+	lines   map[string][]logdriver.LogEntry // lines are the entries read, by container ID
+	stopped int
+}
+
+func (s *DockerExternalLogDriverSuite) SetUpTest(c *check.C) {
+	s.d = NewDaemon(c)
+	s.mu.Lock()
+	s.lines = make(map[string][]logdriver.LogEntry)
+	s.stopped = 0
+	s.mu.Unlock()
+}
+
+func (s *DockerExternalLogDriverSuite) TearDownTest(c *check.C) {
+	s.d.Stop()
+	s.ds.TearDownTest(c)
+}
+
+func (s *DockerExternalLogDriverSuite) SetUpSuite(c *check.C) {
+	mux := http.NewServeMux()
+	s.server = httptest.NewServer(mux)
+
+	type containerInfo struct {
+		ContainerID string
+	}
+
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{"Implements": ["LogDriver"]}`)
+	})
+
+	mux.HandleFunc("/LogDriver.StartLogging", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			File string
+			Info containerInfo
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		f, err := os.Open(req.File)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		go func() {
+			defer f.Close()
+			dec := logdriver.NewLogEntryDecoder(f)
+			for {
+				var entry logdriver.LogEntry
+				if err := dec.Decode(&entry); err != nil {
+					return
+				}
+				s.mu.Lock()
+				s.lines[req.Info.ContainerID] = append(s.lines[req.Info.ContainerID], entry)
+				s.mu.Unlock()
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{}`)
+	})
+
+	mux.HandleFunc("/LogDriver.StopLogging", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.stopped++
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{}`)
+	})
+
+	mux.HandleFunc("/LogDriver.ValidateLogOpts", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Config map[string]string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		resp := map[string]string{}
+		for k := range req.Config {
+			if k != "tag" {
+				resp["Err"] = fmt.Sprintf("unknown log opt '%s' for test-external-log-driver", k)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("/LogDriver.Capabilities", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{"Cap": {"ReadLogs": true}}`)
+	})
+
+	mux.HandleFunc("/LogDriver.ReadLogs", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Info containerInfo
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		enc := logdriver.NewLogEntryEncoder(w)
+		for i := range s.lines[req.Info.ContainerID] {
+			enc.Encode(&s.lines[req.Info.ContainerID][i])
+		}
+	})
+
+	if err := os.MkdirAll("/etc/docker/plugins", 0755); err != nil {
+		c.Fatal(err)
+	}
+	if err := ioutil.WriteFile(testLogDriverSpec, []byte(s.server.URL), 0644); err != nil {
+		c.Fatal(err)
+	}
+}
+
+func (s *DockerExternalLogDriverSuite) TearDownSuite(c *check.C) {
+	s.server.Close()
+
+	if err := os.Remove(testLogDriverSpec); err != nil && !os.IsNotExist(err) {
+		c.Fatal(err)
+	}
+}
+
+func (s *DockerExternalLogDriverSuite) TestExternalLogDriver(c *check.C) {
+	c.Assert(s.d.StartWithBusybox(), check.IsNil)
+
+	out, err := s.d.Cmd("run", "--name", "test-logs", "--log-driver", "test-external-log-driver", "busybox", "sh", "-c", "echo hello; echo world >&2")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("inspect", "--format", "{{.Id}}", "test-logs")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	id := strings.TrimSpace(out)
+
+	// The logger is closed once the container exited.
+	var lines []logdriver.LogEntry
+	var stopped int
+	for i := 0; i < 50; i++ {
+		s.mu.Lock()
+		lines, stopped = s.lines[id], s.stopped
+		s.mu.Unlock()
+		if stopped > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(lines, checker.HasLen, 2)
+	c.Assert(stopped, checker.Equals, 1)
+
+	// docker logs reads the lines back from the plugin.
+	out, err = s.d.Cmd("logs", "test-logs")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "hello\n")
+	c.Assert(out, checker.Contains, "world\n")
+}
+
+func (s *DockerExternalLogDriverSuite) TestExternalLogDriverValidateLogOpts(c *check.C) {
+	c.Assert(s.d.StartWithBusybox(), check.IsNil)
+
+	out, err := s.d.Cmd("run", "--log-driver", "test-external-log-driver", "--log-opt", "foo=bar", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "unknown log opt 'foo' for test-external-log-driver")
+
+	out, err = s.d.Cmd("run", "--log-driver", "test-external-log-driver", "--log-opt", "tag=test", "busybox", "true")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+}
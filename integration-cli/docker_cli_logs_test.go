@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/docker/docker/pkg/timeutils"
 	"github.com/go-check/check"
 )
@@ -374,3 +375,75 @@ func (s *DockerSuite) TestLogsFollowGoroutinesNoOutput(c *check.C) {
 		}
 	}
 }
+
+func (s *DockerSuite) TestLogsNonBlockingMode(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-opt", "mode=non-blocking", "--log-opt", "max-buffer-size=4k", "busybox", "sh", "-c", "for i in $(seq 1 100); do echo $i; done")
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", id)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(lines[len(lines)-1], check.Equals, "100")
+
+	dropped, err := inspectField(id, "LogMessagesDropped")
+	c.Assert(err, check.IsNil)
+	c.Assert(dropped, check.Not(check.Equals), "")
+
+	out, _, err = dockerCmdWithError("run", "--log-opt", "max-buffer-size=4k", "busybox", "true")
+	c.Assert(err, check.NotNil)
+	c.Assert(out, checker.Contains, "max-buffer-size option is only supported with 'mode=non-blocking'")
+}
+
+func (s *DockerSuite) TestLogsFromLocalCache(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-driver=gelf", "--log-opt", "gelf-address=udp://127.0.0.1:12201", "--log-opt", "cache-enabled=true", "busybox", "sh", "-c", "echo hello; echo world")
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", "--tail=1", id)
+	c.Assert(out, check.Equals, "world\n")
+
+	out, _ = dockerCmd(c, "run", "-d", "--log-driver=gelf", "--log-opt", "gelf-address=udp://127.0.0.1:12201", "busybox", "true")
+	id = strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _, err := dockerCmdWithError("logs", id)
+	c.Assert(err, check.NotNil)
+	c.Assert(out, checker.Contains, "configured logging reader does not support reading")
+}
+
+func (s *DockerSuite) TestLogsLocalDriver(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-driver=local", "--log-opt", "max-size=1k", "--log-opt", "max-file=3", "busybox", "sh", "-c", "for i in $(seq 1 100); do echo line$i; done")
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", "--tail=3", id)
+	c.Assert(out, check.Equals, "line98\nline99\nline100\n")
+
+	out, _, err := dockerCmdWithError("run", "--log-driver=local", "--log-opt", "compress=maybe", "busybox", "true")
+	c.Assert(err, check.NotNil)
+	c.Assert(out, checker.Contains, "invalid value for compress")
+}
+
+func (s *DockerSuite) TestLogsMultilineRecords(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-opt", `multiline-pattern=^\S`, "busybox", "sh", "-c", "echo 'panic: boom'; echo '  at main'; echo '  at init'; echo next")
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	// The lines of a record are logged as a single message, with a single
+	// timestamp.
+	out, _ = dockerCmd(c, "logs", "-t", id)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(lines, checker.HasLen, 4)
+	c.Assert(strings.HasSuffix(lines[0], " panic: boom"), check.Equals, true, check.Commentf("got %q", lines[0]))
+	c.Assert(lines[1], check.Equals, "  at main")
+	c.Assert(lines[2], check.Equals, "  at init")
+	c.Assert(strings.HasSuffix(lines[3], " next"), check.Equals, true, check.Commentf("got %q", lines[3]))
+
+	out, _, err := dockerCmdWithError("run", "--log-opt", "multiline-max-size=1k", "busybox", "true")
+	c.Assert(err, check.NotNil)
+	c.Assert(out, checker.Contains, "multiline-max-size option is only supported with multiline-pattern")
+}
@@ -31,13 +31,13 @@ func (s *DockerSuite) TestPause(c *check.C) {
 		c.Fatalf("Missing expected event")
 	}
 
-	pauseEvent := strings.Fields(events[len(events)-3])
-	unpauseEvent := strings.Fields(events[len(events)-2])
+	pauseEvent := parseEventAction(c, events[len(events)-3])
+	unpauseEvent := parseEventAction(c, events[len(events)-2])
 
-	if pauseEvent[len(pauseEvent)-1] != "pause" {
+	if pauseEvent != "pause" {
 		c.Fatalf("event should be pause, not %#v", pauseEvent)
 	}
-	if unpauseEvent[len(unpauseEvent)-1] != "unpause" {
+	if unpauseEvent != "unpause" {
 		c.Fatalf("event should be unpause, not %#v", unpauseEvent)
 	}
 
@@ -71,20 +71,20 @@ func (s *DockerSuite) TestPauseMultipleContainers(c *check.C) {
 		c.Fatalf("Missing expected event")
 	}
 
-	pauseEvents := make([][]string, len(containers))
-	unpauseEvents := make([][]string, len(containers))
+	pauseEvents := make([]string, len(containers))
+	unpauseEvents := make([]string, len(containers))
 	for i := range containers {
-		pauseEvents[i] = strings.Fields(events[len(events)-len(containers)*2-1+i])
-		unpauseEvents[i] = strings.Fields(events[len(events)-len(containers)-1+i])
+		pauseEvents[i] = parseEventAction(c, events[len(events)-len(containers)*2-1+i])
+		unpauseEvents[i] = parseEventAction(c, events[len(events)-len(containers)-1+i])
 	}
 
 	for _, pauseEvent := range pauseEvents {
-		if pauseEvent[len(pauseEvent)-1] != "pause" {
+		if pauseEvent != "pause" {
 			c.Fatalf("event should be pause, not %#v", pauseEvent)
 		}
 	}
 	for _, unpauseEvent := range unpauseEvents {
-		if unpauseEvent[len(unpauseEvent)-1] != "unpause" {
+		if unpauseEvent != "unpause" {
 			c.Fatalf("event should be unpause, not %#v", unpauseEvent)
 		}
 	}
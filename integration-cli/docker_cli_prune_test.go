@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestPruneContainers(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "--label", "prune=yes", "busybox", "true")
+	labeledID := strings.TrimSpace(out)
+	out, _ = dockerCmd(c, "run", "busybox", "true")
+	stoppedID := strings.TrimSpace(out)
+	out, _ = dockerCmd(c, "run", "-d", "busybox", "top")
+	runningID := strings.TrimSpace(out)
+
+	// Without an answer, nothing is removed.
+	out, _ = dockerCmd(c, "container", "prune")
+	c.Assert(out, checker.Contains, "Are you sure you want to continue?")
+	c.Assert(out, check.Not(checker.Contains), labeledID)
+
+	out, _ = dockerCmd(c, "container", "prune", "-f", "--filter", "label=prune=yes")
+	c.Assert(out, checker.Contains, labeledID)
+	c.Assert(out, check.Not(checker.Contains), stoppedID)
+	c.Assert(out, checker.Contains, "Total reclaimed space:")
+
+	out, _ = dockerCmd(c, "container", "prune", "-f")
+	c.Assert(out, checker.Contains, stoppedID)
+	c.Assert(out, check.Not(checker.Contains), runningID)
+
+	out, _ = dockerCmd(c, "ps", "-a", "-q", "--no-trunc")
+	c.Assert(out, checker.Contains, runningID)
+	c.Assert(out, check.Not(checker.Contains), labeledID)
+	c.Assert(out, check.Not(checker.Contains), stoppedID)
+}
+
+func (s *DockerSuite) TestPruneImages(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "prune_image"
+	danglingID, err := buildImage(name, "FROM busybox\nLABEL prune=yes\nRUN echo first", true)
+	c.Assert(err, check.IsNil)
+	taggedID, err := buildImage(name, "FROM busybox\nLABEL prune=yes\nRUN echo second", true)
+	c.Assert(err, check.IsNil)
+
+	// Only the dangling image is removed by default.
+	out, _ := dockerCmd(c, "image", "prune", "-f")
+	c.Assert(out, checker.Contains, "Deleted: "+danglingID)
+	c.Assert(out, check.Not(checker.Contains), taggedID)
+
+	// The images used by a container are kept.
+	dockerCmd(c, "create", name)
+	out, _ = dockerCmd(c, "image", "prune", "-a", "-f", "--filter", "label=prune=yes")
+	c.Assert(out, check.Not(checker.Contains), taggedID)
+
+	dockerCmd(c, "container", "prune", "-f")
+	out, _ = dockerCmd(c, "image", "prune", "-a", "-f", "--filter", "label=prune=yes")
+	c.Assert(out, checker.Contains, "Untagged: "+name+":latest")
+	c.Assert(out, checker.Contains, "Deleted: "+taggedID)
+
+	out, _ = dockerCmd(c, "images", "-q", "--no-trunc")
+	c.Assert(out, check.Not(checker.Contains), danglingID)
+	c.Assert(out, check.Not(checker.Contains), taggedID)
+}
+
+func (s *DockerSuite) TestPruneImagesCommittedLabel(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "prune_commit", "busybox", "true")
+	out, _ := dockerCmd(c, "commit", "--change", "LABEL prune=committed", "prune_commit", "prune_committed")
+	committedID := strings.TrimSpace(out)
+	dockerCmd(c, "rm", "prune_commit")
+
+	// The labels of the image are matched, not the ones of the container
+	// it was committed from.
+	out, _ = dockerCmd(c, "image", "prune", "-a", "-f", "--filter", "label=prune=committed")
+	c.Assert(out, checker.Contains, "Deleted: "+committedID)
+}
+
+func (s *DockerSuite) TestPruneVolumes(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "volume", "create", "--name", "prune_unused")
+	dockerCmd(c, "run", "-v", "prune_used:/data", "busybox", "true")
+
+	out, _ := dockerCmd(c, "volume", "prune", "-f")
+	c.Assert(out, checker.Contains, "prune_unused")
+	c.Assert(out, check.Not(checker.Contains), "prune_used")
+
+	out, _ = dockerCmd(c, "volume", "ls", "-q")
+	c.Assert(out, check.Not(checker.Contains), "prune_unused")
+	c.Assert(out, checker.Contains, "prune_used")
+
+	dockerCmd(c, "volume", "create", "--name", "prune_labeled", "--label", "prune=yes")
+	dockerCmd(c, "volume", "create", "--name", "prune_unlabeled")
+	out, _ = dockerCmd(c, "volume", "prune", "-f", "--filter", "label=prune=yes")
+	c.Assert(out, checker.Contains, "prune_labeled")
+	c.Assert(out, check.Not(checker.Contains), "prune_unlabeled")
+
+	out, _ = dockerCmd(c, "volume", "prune", "-f", "--filter", "until=1h")
+	c.Assert(out, check.Not(checker.Contains), "prune_unlabeled")
+}
+
+func (s *DockerSuite) TestPruneAPI(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--label", "prune=api", "busybox", "true")
+
+	status, body, err := sockRequest("POST", "/containers/prune?filters="+url.QueryEscape(`{"label":["prune=api"]}`), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	var report types.ContainersPruneReport
+	c.Assert(json.Unmarshal(body, &report), check.IsNil)
+	c.Assert(report.ContainersDeleted, checker.HasLen, 1)
+
+	status, body, err = sockRequest("POST", "/volumes/prune?filters="+url.QueryEscape(`{"dangling":["true"]}`), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, checker.Equals, http.StatusInternalServerError)
+	c.Assert(string(body), checker.Contains, "Invalid filter 'dangling'")
+}
@@ -2633,6 +2633,26 @@ func (s *DockerSuite) TestRunContainerWithRmFlagCannotStartContainer(c *check.C)
 	}
 }
 
+// run container with --rm and -d should remove container once it exits
+func (s *DockerSuite) TestRunContainerWithRmFlagDetached(c *check.C) {
+	name := "petals"
+	dockerCmd(c, "run", "-d", "--name", name, "--rm", "busybox", "sh", "-c", "sleep 1")
+
+	for i := 0; ; i++ {
+		out, err := getAllContainers()
+		if err != nil {
+			c.Fatal(out, err)
+		}
+		if out == "" {
+			break
+		}
+		if i == 100 {
+			c.Fatal("Expected container to be removed once it exited", out)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 func (s *DockerSuite) TestRunPidHostWithChildIsKillable(c *check.C) {
 	// Not applicable on Windows as uses Unix specific functionality
 	testRequires(c, DaemonIsLinux)
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -272,6 +273,230 @@ func (s *DockerSuite) TestRunWithBlkioInvalidWeight(c *check.C) {
 	}
 }
 
+func (s *DockerSuite) TestRunWithPidsLimit(c *check.C) {
+	testRequires(c, pidsLimit)
+
+	file := "/sys/fs/cgroup/pids/pids.max"
+	out, _ := dockerCmd(c, "run", "--name", "skittles", "--pids-limit", "2", "busybox", "cat", file)
+	c.Assert(strings.TrimSpace(out), checker.Equals, "2")
+
+	out, err := inspectField("skittles", "HostConfig.PidsLimit")
+	c.Assert(err, check.IsNil)
+	c.Assert(out, checker.Equals, "2", check.Commentf("setting the pids limit failed"))
+}
+
+func (s *DockerSuite) TestExecWithPidsLimit(c *check.C) {
+	testRequires(c, pidsLimit)
+
+	dockerCmd(c, "run", "-d", "--name", "skittles", "--pids-limit", "2", "busybox", "top")
+
+	// The exec'd processes are counted in the pids cgroup of the container
+	out, _ := dockerCmd(c, "exec", "skittles", "cat", "/sys/fs/cgroup/pids/pids.current")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "2")
+
+	out, _, err := dockerCmdWithError("exec", "skittles", "sh", "-c", "true & wait")
+	c.Assert(err, checker.NotNil, check.Commentf("exec forked beyond the pids limit: %s", out))
+}
+
+func (s *DockerSuite) TestRunWithBlkioInvalidWeightDevice(c *check.C) {
+	testRequires(c, blkioWeight)
+	out, _, err := dockerCmdWithError("run", "--blkio-weight-device", "/dev/sda:5", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "invalid weight for device")
+}
+
+func (s *DockerSuite) TestRunWithBlkioInvalidDeviceReadBps(c *check.C) {
+	testRequires(c, blkioWeight)
+	out, _, err := dockerCmdWithError("run", "--device-read-bps", "/dev/sda:500x", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "invalid rate for device")
+
+	out, _, err = dockerCmdWithError("run", "--device-read-bps", "sda:500", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "bad format for device path")
+}
+
+func (s *DockerSuite) TestRunWithBlkioInvalidDeviceWriteIOps(c *check.C) {
+	testRequires(c, blkioWeight)
+	out, _, err := dockerCmdWithError("run", "--device-write-iops", "/dev/sda:-1", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "invalid rate for device")
+}
+
+func (s *DockerSuite) TestRunWithBlkioNotABlockDevice(c *check.C) {
+	testRequires(c, blkioWeight)
+	out, _, err := dockerCmdWithError("run", "--device-read-iops", "/dev/null:100", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "not a block device")
+}
+
+func (s *DockerSuite) TestRunTmpfsMounts(c *check.C) {
+	// --tmpfs is not supported on Windows
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "--name", "test", "--read-only", "--tmpfs", "/run", "--tmpfs", "/tmp:size=1m,mode=1777", "--tmpfs", "/exec:exec", "busybox", "sh", "-c", "touch /run/foo /tmp/foo && grep tmpfs /proc/mounts")
+	c.Assert(out, checker.Contains, "tmpfs /run tmpfs rw,nosuid,nodev,noexec")
+	// The given options are merged with the default ones
+	c.Assert(out, checker.Contains, "tmpfs /tmp tmpfs rw,nosuid,nodev,noexec")
+	c.Assert(out, checker.Contains, "size=1024k,mode=1777")
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "tmpfs /exec ") {
+			c.Assert(line, checker.Contains, "nosuid,nodev")
+			c.Assert(line, checker.Not(checker.Contains), "noexec")
+		}
+	}
+
+	out, err := inspectFieldJSON("test", "HostConfig.Tmpfs")
+	c.Assert(err, check.IsNil)
+	c.Assert(out, checker.Equals, `{"/exec":"exec","/run":"","/tmp":"size=1m,mode=1777"}`)
+
+	out, _, err = dockerCmdWithError("run", "--tmpfs", "/run:foo", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Invalid tmpfs option")
+}
+
+func (s *DockerSuite) TestRunTmpfsMountConflictWithVolume(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon)
+	out, _, err := dockerCmdWithError("run", "--tmpfs", "/data", "-v", "/tmp:/data", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Duplicate mount point /data")
+}
+
+func (s *DockerSuite) TestRunWithShmSize(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	name := "shm"
+	dockerCmd(c, "run", "-d", "--name", name, "--shm-size=1G", "busybox", "top")
+	out, _ := dockerCmd(c, "exec", name, "mount")
+	c.Assert(out, checker.Matches, "(?s).*shm on /dev/shm type tmpfs.*size=1048576k.*")
+
+	shmSize, err := inspectField(name, "HostConfig.ShmSize")
+	c.Assert(err, check.IsNil)
+	c.Assert(shmSize, checker.Equals, "1073741824")
+
+	// A container joining the IPC namespace gets the /dev/shm of its owner.
+	out, _ = dockerCmd(c, "run", "--ipc", "container:"+name, "busybox", "mount")
+	c.Assert(out, checker.Matches, "(?s).*shm on /dev/shm type tmpfs.*size=1048576k.*")
+}
+
+func (s *DockerSuite) TestRunWithInvalidShmSize(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _, err := dockerCmdWithError("run", "--shm-size=-1", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "SHM size must be greater than 0")
+}
+
+func (s *DockerSuite) TestRunWithSysctls(c *check.C) {
+	testRequires(c, NativeExecDriver)
+
+	name := "sysctls"
+	out, _ := dockerCmd(c, "run", "--name", name, "--sysctl", "net.ipv4.ip_forward=1", "--sysctl", "kernel.msgmax=65536", "busybox", "sh", "-c", "cat /proc/sys/net/ipv4/ip_forward /proc/sys/kernel/msgmax")
+	c.Assert(out, checker.Equals, "1\n65536\n")
+
+	sysctls, err := inspectFieldJSON(name, "HostConfig.Sysctls")
+	c.Assert(err, check.IsNil)
+	c.Assert(sysctls, checker.Contains, `"net.ipv4.ip_forward":"1"`)
+}
+
+func (s *DockerSuite) TestRunWithInvalidSysctls(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _, err := dockerCmdWithError("run", "--sysctl", "kernel.hostname=foo", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "sysctl 'kernel.hostname' is not whitelisted")
+
+	out, _, err = dockerCmdWithError("run", "--net=host", "--sysctl", "net.ipv4.ip_forward=1", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "cannot be set when the network namespace is shared")
+
+	out, _, err = dockerCmdWithError("run", "--ipc=host", "--sysctl", "kernel.msgmax=65536", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "cannot be set when the IPC namespace is shared")
+}
+
+func (s *DockerSuite) TestRunWithInit(c *check.C) {
+	testRequires(c, NativeExecDriver)
+
+	// The init is PID 1, it runs the command and exits with its status.
+	out, _ := dockerCmd(c, "run", "--init", "busybox", "cat", "/proc/1/cmdline")
+	c.Assert(strings.HasPrefix(out, "/dev/init\x00cat\x00"), checker.Equals, true, check.Commentf(out))
+	_, exitCode, _ := dockerCmdWithError("run", "--init", "busybox", "sh", "-c", "exit 3")
+	c.Assert(exitCode, checker.Equals, 3)
+}
+
+func (s *DockerSuite) TestRunInitForwardsSignalsAndReaps(c *check.C) {
+	testRequires(c, NativeExecDriver)
+
+	// Without an init, sleep is PID 1 and ignores SIGTERM.
+	name := "init"
+	dockerCmd(c, "run", "-d", "--name", name, "--init", "busybox", "sleep", "100")
+
+	// The orphans of exec'd processes are reaped by the init.
+	dockerCmd(c, "exec", name, "sh", "-c", "sleep 1 &")
+	time.Sleep(2 * time.Second)
+	out, _ := dockerCmd(c, "exec", name, "sh", "-c", "grep -l zombie /proc/[0-9]*/status || true")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "")
+
+	start := time.Now()
+	dockerCmd(c, "stop", "-t", "30", name)
+	c.Assert(time.Since(start) < 10*time.Second, checker.Equals, true, check.Commentf("the command did not stop on SIGTERM"))
+	exitCode, err := inspectField(name, "State.ExitCode")
+	c.Assert(err, check.IsNil)
+	c.Assert(exitCode, checker.Equals, "143")
+}
+
+func (s *DockerSuite) TestRunWithStorageOpt(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	out, _ := dockerCmd(c, "info")
+	switch {
+	case strings.Contains(out, "Storage Driver: aufs"), strings.Contains(out, "Storage Driver: vfs"):
+		// The drivers without quotas reject the size rather than ignoring it.
+		out, _, err := dockerCmdWithError("run", "--storage-opt", "size=1G", "busybox", "true")
+		c.Assert(err, checker.NotNil, check.Commentf(out))
+		c.Assert(out, checker.Contains, "--storage-opt is not supported")
+	case strings.Contains(out, "Storage Driver: devicemapper"):
+		// The root filesystem is grown from the default of 10G.
+		out, _ = dockerCmd(c, "run", "--storage-opt", "size=20G", "busybox", "df", "-P", "/")
+		fields := strings.Fields(strings.Split(strings.TrimSpace(out), "\n")[1])
+		size, err := strconv.Atoi(fields[1])
+		c.Assert(err, check.IsNil, check.Commentf(out))
+		c.Assert(size > 15*1024*1024, checker.Equals, true, check.Commentf("the root filesystem was not grown: %s", out))
+
+		out, _, err = dockerCmdWithError("run", "--storage-opt", "size=1G", "busybox", "true")
+		c.Assert(err, checker.NotNil, check.Commentf(out))
+		c.Assert(out, checker.Contains, "cannot be smaller")
+	default:
+		c.Skip("Test requires the aufs, vfs or devicemapper storage driver")
+	}
+
+	out, _, err := dockerCmdWithError("run", "--storage-opt", "size", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "bad format for storage option")
+}
+
+func (s *DockerSuite) TestRunWithStopTimeout(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	// sleep is PID 1 and ignores SIGTERM, it is killed after the stop timeout
+	// of the container rather than the default of 10 seconds.
+	name := "stop_timeout"
+	dockerCmd(c, "run", "-d", "--name", name, "--stop-timeout", "1", "busybox", "sleep", "100")
+	timeout, err := inspectFieldJSON(name, "Config.StopTimeout")
+	c.Assert(err, check.IsNil)
+	c.Assert(timeout, checker.Equals, "1")
+
+	start := time.Now()
+	dockerCmd(c, "stop", name)
+	c.Assert(time.Since(start) < 5*time.Second, checker.Equals, true, check.Commentf("the stop timeout of the container was not used"))
+
+	start = time.Now()
+	dockerCmd(c, "restart", name)
+	c.Assert(time.Since(start) < 5*time.Second, checker.Equals, true, check.Commentf("the stop timeout of the container was not used"))
+
+	out, _, err := dockerCmdWithError("run", "--stop-timeout=-1", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "it cannot be negative")
+}
+
 func (s *DockerSuite) TestRunOOMExitCode(c *check.C) {
 	testRequires(c, oomControl)
 	errChan := make(chan error)
@@ -374,3 +599,58 @@ func (s *DockerSuite) TestRunSwapLessThanMemoryLimit(c *check.C) {
 		c.Fatalf("Expected output to contain %q, not %q", out, expected)
 	}
 }
+
+func (s *DockerSuite) TestRunSeccompProfileDenyChmod(c *check.C) {
+	testRequires(c, NativeExecDriver, seccompEnabled)
+	profile := `{
+	"defaultAction": "SCMP_ACT_ALLOW",
+	"syscalls": [
+		{"name": "chmod", "action": "SCMP_ACT_ERRNO", "args": []},
+		{"name": "fchmodat", "action": "SCMP_ACT_ERRNO", "args": []}
+	]
+}`
+	tmpFile, err := ioutil.TempFile("", "profile.json")
+	c.Assert(err, check.IsNil)
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write([]byte(profile))
+	c.Assert(err, check.IsNil)
+	tmpFile.Close()
+
+	out, _, err := dockerCmdWithError("run", "--name", "seccomp", "--security-opt", "seccomp="+tmpFile.Name(), "busybox", "chmod", "400", "/etc/hostname")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Operation not permitted")
+
+	profileName, err := inspectField("seccomp", "SeccompProfile")
+	c.Assert(err, check.IsNil)
+	c.Assert(profileName, check.Equals, tmpFile.Name())
+}
+
+func (s *DockerSuite) TestRunSeccompDefaultProfile(c *check.C) {
+	testRequires(c, NativeExecDriver, seccompEnabled)
+	// The default profile denies mount even with CAP_SYS_ADMIN.
+	out, _, err := dockerCmdWithError("run", "--name", "default", "--cap-add", "SYS_ADMIN", "busybox", "mount", "-t", "tmpfs", "none", "/tmp")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Operation not permitted")
+	profileName, err := inspectField("default", "SeccompProfile")
+	c.Assert(err, check.IsNil)
+	c.Assert(profileName, check.Equals, "default")
+
+	out, _ = dockerCmd(c, "run", "--name", "unconfined", "--cap-add", "SYS_ADMIN", "--security-opt", "seccomp=unconfined", "busybox", "mount", "-t", "tmpfs", "none", "/tmp")
+	profileName, err = inspectField("unconfined", "SeccompProfile")
+	c.Assert(err, check.IsNil)
+	c.Assert(profileName, check.Equals, "unconfined")
+}
+
+func (s *DockerSuite) TestRunSeccompInvalidProfile(c *check.C) {
+	testRequires(c, seccompEnabled)
+	tmpFile, err := ioutil.TempFile("", "profile.json")
+	c.Assert(err, check.IsNil)
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write([]byte(`{"defaultAction": "SCMP_ACT_NOPE"}`))
+	c.Assert(err, check.IsNil)
+	tmpFile.Close()
+
+	out, _, err := dockerCmdWithError("create", "--security-opt", "seccomp="+tmpFile.Name(), "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Invalid seccomp profile")
+}
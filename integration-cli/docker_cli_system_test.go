@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestSystemDf(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "df_writer", "-v", "df_volume:/data", "busybox", "sh", "-c",
+		"dd if=/dev/zero of=/file bs=1024 count=1024 && dd if=/dev/zero of=/data/file bs=1024 count=512")
+
+	out, _ := dockerCmd(c, "system", "df")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(lines, checker.HasLen, 4, check.Commentf(out))
+	c.Assert(lines[0], checker.Contains, "RECLAIMABLE")
+	c.Assert(lines[1], checker.Contains, "Images")
+	c.Assert(lines[2], checker.Contains, "Containers")
+	c.Assert(lines[3], checker.Contains, "Local Volumes")
+
+	out, _ = dockerCmd(c, "system", "df", "-v")
+	c.Assert(out, checker.Contains, "Images space usage:")
+	c.Assert(out, checker.Contains, "df_writer")
+	c.Assert(out, checker.Contains, "df_volume")
+
+	status, body, err := sockRequest("GET", "/system/df", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	var du types.DiskUsage
+	c.Assert(json.Unmarshal(body, &du), check.IsNil)
+	c.Assert(du.LayersSize >= du.ReclaimableLayersSize, checker.Equals, true)
+
+	var foundContainer, foundVolume, foundImage bool
+	for _, ctr := range du.Containers {
+		if len(ctr.Names) == 1 && ctr.Names[0] == "/df_writer" {
+			foundContainer = true
+			c.Assert(ctr.Running, checker.Equals, false)
+			c.Assert(ctr.SizeRw >= 1024*1024, checker.Equals, true, check.Commentf("SizeRw: %d", ctr.SizeRw))
+		}
+	}
+	for _, v := range du.Volumes {
+		if v.Name == "df_volume" {
+			foundVolume = true
+			c.Assert(v.RefCount, checker.Equals, 1)
+			c.Assert(v.Size >= 512*1024, checker.Equals, true, check.Commentf("Size: %d", v.Size))
+		}
+	}
+	for _, img := range du.Images {
+		for _, repoTag := range img.RepoTags {
+			if repoTag == "busybox:latest" {
+				foundImage = true
+				c.Assert(img.Containers > 0, checker.Equals, true)
+				c.Assert(img.Size >= img.SharedSize, checker.Equals, true)
+			}
+		}
+	}
+	c.Assert(foundContainer, checker.Equals, true, check.Commentf("df_writer not found in %s", body))
+	c.Assert(foundVolume, checker.Equals, true, check.Commentf("df_volume not found in %s", body))
+	c.Assert(foundImage, checker.Equals, true, check.Commentf("busybox not found in %s", body))
+}
@@ -0,0 +1,135 @@
+// +build !windows
+
+package main
+
+import (
+	"strings"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestUpdateRunningContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, memoryLimitSupport)
+
+	name := "test-update-container"
+	dockerCmd(c, "run", "-d", "--name", name, "-m", "300M", "busybox", "top")
+	dockerCmd(c, "update", "-m", "500M", name)
+
+	memory, err := inspectField(name, "HostConfig.Memory")
+	c.Assert(err, checker.IsNil)
+	c.Assert(memory, checker.Equals, "524288000")
+
+	file := "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+	out, _ := dockerCmd(c, "exec", name, "cat", file)
+	c.Assert(strings.TrimSpace(out), checker.Equals, "524288000")
+}
+
+func (s *DockerSuite) TestUpdateRunningContainerWithRestart(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, memoryLimitSupport)
+
+	name := "test-update-container"
+	dockerCmd(c, "run", "-d", "--name", name, "-m", "300M", "busybox", "top")
+	dockerCmd(c, "update", "-m", "500M", name)
+	dockerCmd(c, "restart", name)
+
+	memory, err := inspectField(name, "HostConfig.Memory")
+	c.Assert(err, checker.IsNil)
+	c.Assert(memory, checker.Equals, "524288000")
+
+	file := "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+	out, _ := dockerCmd(c, "exec", name, "cat", file)
+	c.Assert(strings.TrimSpace(out), checker.Equals, "524288000")
+}
+
+func (s *DockerSuite) TestUpdateStoppedContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, memoryLimitSupport)
+
+	name := "test-update-container"
+	file := "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+	dockerCmd(c, "run", "--name", name, "-m", "300M", "busybox", "cat", file)
+	dockerCmd(c, "update", "-m", "500M", name)
+
+	memory, err := inspectField(name, "HostConfig.Memory")
+	c.Assert(err, checker.IsNil)
+	c.Assert(memory, checker.Equals, "524288000")
+
+	out, _ := dockerCmd(c, "start", "-a", name)
+	c.Assert(strings.TrimSpace(out), checker.Equals, "524288000")
+}
+
+func (s *DockerSuite) TestUpdateUnlimitedSwapMemory(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, memoryLimitSupport)
+	testRequires(c, swapMemorySupport)
+
+	name := "test-update-container"
+	dockerCmd(c, "run", "-d", "--name", name, "-m", "300M", "--memory-swap", "500M", "busybox", "top")
+	dockerCmd(c, "update", "-m", "600M", "--memory-swap", "-1", name)
+
+	memorySwap, err := inspectField(name, "HostConfig.MemorySwap")
+	c.Assert(err, checker.IsNil)
+	c.Assert(memorySwap, checker.Equals, "-1")
+
+	out, _ := dockerCmd(c, "exec", name, "cat", "/sys/fs/cgroup/memory/memory.limit_in_bytes")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "629145600")
+	out, _ = dockerCmd(c, "exec", name, "cat", "/sys/fs/cgroup/memory/memory.memsw.limit_in_bytes")
+	c.Assert(strings.TrimSpace(out), checker.Not(checker.Equals), "524288000")
+}
+
+func (s *DockerSuite) TestUpdateCPUSharesAndQuota(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, cpuShare)
+	testRequires(c, cpuCfsQuota)
+
+	name := "test-update-container"
+	dockerCmd(c, "run", "-d", "--name", name, "--cpu-shares", "1000", "busybox", "top")
+	dockerCmd(c, "update", "--cpu-shares", "500", "--cpu-quota", "40000", name)
+
+	shares, err := inspectField(name, "HostConfig.CpuShares")
+	c.Assert(err, checker.IsNil)
+	c.Assert(shares, checker.Equals, "500")
+	quota, err := inspectField(name, "HostConfig.CpuQuota")
+	c.Assert(err, checker.IsNil)
+	c.Assert(quota, checker.Equals, "40000")
+
+	out, _ := dockerCmd(c, "exec", name, "cat", "/sys/fs/cgroup/cpu/cpu.shares")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "500")
+	out, _ = dockerCmd(c, "exec", name, "cat", "/sys/fs/cgroup/cpu/cpu.cfs_quota_us")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "40000")
+}
+
+func (s *DockerSuite) TestUpdateKernelMemoryRunningContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, kernelMemorySupport)
+
+	name := "test-update-container"
+	dockerCmd(c, "run", "-d", "--name", name, "--kernel-memory", "50M", "busybox", "top")
+	out, _, err := dockerCmdWithError("update", "--kernel-memory", "100M", name)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Cannot update kernel memory of running container")
+
+	dockerCmd(c, "stop", name)
+	dockerCmd(c, "update", "--kernel-memory", "100M", name)
+	kernelMemory, err := inspectField(name, "HostConfig.KernelMemory")
+	c.Assert(err, checker.IsNil)
+	c.Assert(kernelMemory, checker.Equals, "104857600")
+}
+
+func (s *DockerSuite) TestUpdateInvalidValue(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	testRequires(c, blkioWeight)
+
+	name := "test-update-container"
+	dockerCmd(c, "run", "-d", "--name", name, "busybox", "top")
+	out, _, err := dockerCmdWithError("update", "--blkio-weight", "5", name)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Range of blkio weight is from 10 to 1000")
+
+	out, _, err = dockerCmdWithError("update", name)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "You must provide one or more flags when using this command.")
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-check/check"
+)
+
+// eventCliRegexp matches the events printed by docker events:
+// <time> <type> <action> <actor ID> (<attributes>)
+// The action of the exec and health events is followed by their details.
+var eventCliRegexp = regexp.MustCompile(`^(\S+)\s+(\w+)\s+([\w-]+(?:: .*?)?)\s+(\S+)(?:\s+\(([^)]*)\))?$`)
+
+// parseEvent returns the type, the action, the actor ID and the attributes
+// of an event printed by docker events.
+func parseEvent(c *check.C, event string) (string, string, string, map[string]string) {
+	matches := eventCliRegexp.FindStringSubmatch(strings.TrimSpace(event))
+	if matches == nil {
+		c.Fatalf("malformed event: %q", event)
+	}
+	attributes := map[string]string{}
+	if matches[5] != "" {
+		for _, attr := range strings.Split(matches[5], ", ") {
+			kv := strings.SplitN(attr, "=", 2)
+			if len(kv) == 2 {
+				attributes[kv[0]] = kv[1]
+			}
+		}
+	}
+	return matches[2], matches[3], matches[4], attributes
+}
+
+// parseEventAction returns the action of an event printed by docker events.
+func parseEventAction(c *check.C, event string) string {
+	_, action, _, _ := parseEvent(c, event)
+	return action
+}
+
+// parseEventActorID returns the actor ID of an event printed by docker
+// events.
+func parseEventActorID(c *check.C, event string) string {
+	_, _, id, _ := parseEvent(c, event)
+	return id
+}
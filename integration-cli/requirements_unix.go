@@ -3,6 +3,11 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
 	"github.com/docker/docker/pkg/sysinfo"
 )
 
@@ -75,6 +80,40 @@ var (
 		},
 		"Test requires an environment that supports cgroup cpuset.",
 	}
+	pidsLimit = testRequirement{
+		func() bool {
+			return SysInfo.PidsLimit
+		},
+		"Test requires an environment that supports pids limit.",
+	}
+	userNamespaceRemap = testRequirement{
+		func() bool {
+			if _, err := os.Stat("/proc/self/ns/user"); err != nil {
+				return false
+			}
+			for _, f := range []string{"/etc/subuid", "/etc/subgid"} {
+				b, err := ioutil.ReadFile(f)
+				if err != nil || !strings.Contains(string(b), "dockremap:") {
+					return false
+				}
+			}
+			return true
+		},
+		"Test requires user namespaces and subordinate ids for the dockremap user.",
+	}
+	seccompEnabled = testRequirement{
+		func() bool {
+			out, err := exec.Command(dockerBinary, "create", "busybox", "true").Output()
+			if err != nil {
+				return false
+			}
+			id := strings.TrimSpace(string(out))
+			defer exec.Command(dockerBinary, "rm", id).Run()
+			out, err = exec.Command(dockerBinary, "inspect", "-f", "{{.SeccompProfile}}", id).Output()
+			return err == nil && strings.TrimSpace(string(out)) == "default"
+		},
+		"Test requires a daemon built with seccomp support.",
+	}
 )
 
 func init() {
@@ -333,6 +333,43 @@ func ValidateLabel(val string) (string, error) {
 	return val, nil
 }
 
+// ipcSysctls are the sysctls of the IPC namespace, along with the fs.mqueue.*
+// ones matched by prefix.
+var ipcSysctls = map[string]bool{
+	"kernel.msgmax":          true,
+	"kernel.msgmnb":          true,
+	"kernel.msgmni":          true,
+	"kernel.sem":             true,
+	"kernel.shmall":          true,
+	"kernel.shmmax":          true,
+	"kernel.shmmni":          true,
+	"kernel.shm_rmid_forced": true,
+}
+
+// IsIpcSysctl returns whether the sysctl key is namespaced by the IPC namespace.
+func IsIpcSysctl(key string) bool {
+	return ipcSysctls[key] || strings.HasPrefix(key, "fs.mqueue.")
+}
+
+// IsNetSysctl returns whether the sysctl key is namespaced by the network namespace.
+func IsNetSysctl(key string) bool {
+	return strings.HasPrefix(key, "net.")
+}
+
+// ValidateSysctl validates that the specified string is a sysctl in the
+// key=value form, whose key is namespaced, and returns it. The other sysctls
+// would change the settings of the host.
+func ValidateSysctl(val string) (string, error) {
+	arr := strings.SplitN(val, "=", 2)
+	if len(arr) != 2 || arr[0] == "" {
+		return "", fmt.Errorf("bad sysctl format: %s", val)
+	}
+	if !IsIpcSysctl(arr[0]) && !IsNetSysctl(arr[0]) {
+		return "", fmt.Errorf("sysctl '%s' is not whitelisted, only the net.* and IPC namespace sysctls are allowed", arr[0])
+	}
+	return val, nil
+}
+
 // ValidateHost validates that the specified string is a valid host and returns it.
 func ValidateHost(val string) (string, error) {
 	host, err := parsers.ParseDockerDaemonHost(DefaultTCPHost, DefaultUnixSocket, val)
@@ -423,6 +423,34 @@ func TestValidateLabel(t *testing.T) {
 	}
 }
 
+func TestValidateSysctl(t *testing.T) {
+	valid := []string{
+		"net.core.somaxconn=1024",
+		"net.ipv4.tcp_keepalive_time=600",
+		"kernel.shmmax=68719476736",
+		"kernel.sem=250 32000 100 128",
+		"fs.mqueue.msg_max=100",
+	}
+	invalid := map[string]string{
+		"net.core.somaxconn":   "bad sysctl format: net.core.somaxconn",
+		"=1":                   "bad sysctl format: =1",
+		"kernel.hostname=foo":  "sysctl 'kernel.hostname' is not whitelisted, only the net.* and IPC namespace sysctls are allowed",
+		"vm.swappiness=10":     "sysctl 'vm.swappiness' is not whitelisted, only the net.* and IPC namespace sysctls are allowed",
+		"kernel.msgmax.foo=10": "sysctl 'kernel.msgmax.foo' is not whitelisted, only the net.* and IPC namespace sysctls are allowed",
+	}
+
+	for _, value := range valid {
+		if actual, err := ValidateSysctl(value); err != nil || actual != value {
+			t.Fatalf("Expected [%v], got [%v,%v]", value, actual, err)
+		}
+	}
+	for value, errorMessage := range invalid {
+		if _, err := ValidateSysctl(value); err == nil || err.Error() != errorMessage {
+			t.Fatalf("Expected an error for %v with [%v], got [%v]", value, errorMessage, err)
+		}
+	}
+}
+
 func TestValidateHost(t *testing.T) {
 	invalid := map[string]string{
 		"anything":              "Invalid bind address format: anything",
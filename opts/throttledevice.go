@@ -0,0 +1,47 @@
+package opts
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/pkg/blkiodev"
+)
+
+// ValidatorThrottleFctType defines a validator function that parses a
+// device:rate pair.
+type ValidatorThrottleFctType func(val string) (*blkiodev.ThrottleDevice, error)
+
+// ThrottledeviceOpt defines a list of ThrottleDevices and a validation function.
+type ThrottledeviceOpt struct {
+	values    []*blkiodev.ThrottleDevice
+	validator ValidatorThrottleFctType
+}
+
+// NewThrottledeviceOpt creates a new ThrottledeviceOpt with the specified validator.
+func NewThrottledeviceOpt(validator ValidatorThrottleFctType) ThrottledeviceOpt {
+	return ThrottledeviceOpt{validator: validator}
+}
+
+// Set validates a ThrottleDevice and adds it to the list.
+func (opt *ThrottledeviceOpt) Set(val string) error {
+	v, err := opt.validator(val)
+	if err != nil {
+		return err
+	}
+	opt.values = append(opt.values, v)
+	return nil
+}
+
+// String returns ThrottledeviceOpt values as a string.
+func (opt *ThrottledeviceOpt) String() string {
+	var out []string
+	for _, v := range opt.values {
+		out = append(out, v.String())
+	}
+
+	return fmt.Sprintf("%v", out)
+}
+
+// GetList returns a slice of pointers to ThrottleDevices.
+func (opt *ThrottledeviceOpt) GetList() []*blkiodev.ThrottleDevice {
+	return opt.values
+}
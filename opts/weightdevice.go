@@ -0,0 +1,47 @@
+package opts
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/pkg/blkiodev"
+)
+
+// ValidatorWeightFctType defines a validator function that parses a
+// device:weight pair.
+type ValidatorWeightFctType func(val string) (*blkiodev.WeightDevice, error)
+
+// WeightdeviceOpt defines a list of WeightDevices and a validation function.
+type WeightdeviceOpt struct {
+	values    []*blkiodev.WeightDevice
+	validator ValidatorWeightFctType
+}
+
+// NewWeightdeviceOpt creates a new WeightdeviceOpt with the specified validator.
+func NewWeightdeviceOpt(validator ValidatorWeightFctType) WeightdeviceOpt {
+	return WeightdeviceOpt{validator: validator}
+}
+
+// Set validates a WeightDevice and adds it to the list.
+func (opt *WeightdeviceOpt) Set(val string) error {
+	v, err := opt.validator(val)
+	if err != nil {
+		return err
+	}
+	opt.values = append(opt.values, v)
+	return nil
+}
+
+// String returns WeightdeviceOpt values as a string.
+func (opt *WeightdeviceOpt) String() string {
+	var out []string
+	for _, v := range opt.values {
+		out = append(out, v.String())
+	}
+
+	return fmt.Sprintf("%v", out)
+}
+
+// GetList returns a slice of pointers to WeightDevices.
+func (opt *WeightdeviceOpt) GetList() []*blkiodev.WeightDevice {
+	return opt.values
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/pools"
 	"github.com/docker/docker/pkg/promise"
 	"github.com/docker/docker/pkg/system"
@@ -49,12 +50,20 @@ type (
 		// For each include when creating an archive, the included name will be
 		// replaced with the matching name from this map.
 		RebaseNames map[string]string
+		// The uid and gid mappings of a user namespace. When set, the owners
+		// of the files are translated from host ids to container ids when
+		// creating an archive, and back when unpacking it.
+		UIDMaps []idtools.IDMap
+		GIDMaps []idtools.IDMap
 	}
 
 	// Archiver allows the reuse of most utility functions of this package
-	// with a pluggable Untar function.
+	// with a pluggable Untar function. The files it unpacks are owned by
+	// the host ids their ids are mapped to in UIDMaps and GIDMaps.
 	Archiver struct {
-		Untar func(io.Reader, string, *TarOptions) error
+		Untar   func(io.Reader, string, *TarOptions) error
+		UIDMaps []idtools.IDMap
+		GIDMaps []idtools.IDMap
 	}
 
 	// breakoutError is used to differentiate errors related to breaking out
@@ -66,7 +75,7 @@ type (
 var (
 	// ErrNotImplemented is the error message of function not implemented.
 	ErrNotImplemented = errors.New("Function not implemented")
-	defaultArchiver   = &Archiver{Untar}
+	defaultArchiver   = &Archiver{Untar: Untar}
 )
 
 const (
@@ -194,6 +203,9 @@ type tarAppender struct {
 
 	// for hardlink mapping
 	SeenFiles map[uint64]string
+
+	UIDMaps []idtools.IDMap
+	GIDMaps []idtools.IDMap
 }
 
 // canonicalTarName provides a platform-independent and consistent posix-style
@@ -255,6 +267,17 @@ func (ta *tarAppender) addTarFile(path, name string) error {
 		}
 	}
 
+	// Translate the owner to the ids of the container, except for the
+	// whiteouts which are created by the kernel on the host.
+	if (ta.UIDMaps != nil || ta.GIDMaps != nil) && !strings.HasPrefix(filepath.Base(hdr.Name), ".wh.") {
+		if hdr.Uid, err = idtools.ToContainer(hdr.Uid, ta.UIDMaps); err != nil {
+			return err
+		}
+		if hdr.Gid, err = idtools.ToContainer(hdr.Gid, ta.GIDMaps); err != nil {
+			return err
+		}
+	}
+
 	capability, _ := system.Lgetxattr(path, "security.capability")
 	if capability != nil {
 		hdr.Xattrs = make(map[string]string)
@@ -427,6 +450,8 @@ func TarWithOptions(srcPath string, options *TarOptions) (io.ReadCloser, error)
 			TarWriter: tar.NewWriter(compressWriter),
 			Buffer:    pools.BufioWriter32KPool.Get(nil),
 			SeenFiles: make(map[uint64]string),
+			UIDMaps:   options.UIDMaps,
+			GIDMaps:   options.GIDMaps,
 		}
 
 		defer func() {
@@ -554,6 +579,10 @@ func Unpack(decompressedArchive io.Reader, dest string, options *TarOptions) err
 	defer pools.BufioReader32KPool.Put(trBuf)
 
 	var dirs []*tar.Header
+	remappedRootUID, remappedRootGID, err := idtools.GetRootUIDGID(options.UIDMaps, options.GIDMaps)
+	if err != nil {
+		return err
+	}
 
 	// Iterate through the files in the archive.
 loop:
@@ -586,7 +615,7 @@ loop:
 			parent := filepath.Dir(hdr.Name)
 			parentPath := filepath.Join(dest, parent)
 			if _, err := os.Lstat(parentPath); err != nil && os.IsNotExist(err) {
-				err = system.MkdirAll(parentPath, 0777)
+				err = idtools.MkdirAllNewAs(parentPath, 0777, remappedRootUID, remappedRootGID)
 				if err != nil {
 					return err
 				}
@@ -602,6 +631,10 @@ loop:
 			return breakoutError(fmt.Errorf("%q is outside of %q", hdr.Name, dest))
 		}
 
+		if err := remapIDs(hdr, options.UIDMaps, options.GIDMaps); err != nil {
+			return err
+		}
+
 		// If path exits we almost always just want to remove and replace it
 		// The only exception is when it is a directory *and* the file from
 		// the layer is also a directory. Then we want to merge them (i.e.
@@ -703,7 +736,7 @@ func (archiver *Archiver) TarUntar(src, dst string) error {
 		return err
 	}
 	defer archive.Close()
-	return archiver.Untar(archive, dst, nil)
+	return archiver.Untar(archive, dst, archiver.untarOptions())
 }
 
 // TarUntar is a convenience function which calls Tar and Untar, with the output of one piped into the other.
@@ -719,7 +752,7 @@ func (archiver *Archiver) UntarPath(src, dst string) error {
 		return err
 	}
 	defer archive.Close()
-	if err := archiver.Untar(archive, dst, nil); err != nil {
+	if err := archiver.Untar(archive, dst, archiver.untarOptions()); err != nil {
 		return err
 	}
 	return nil
@@ -745,7 +778,11 @@ func (archiver *Archiver) CopyWithTar(src, dst string) error {
 	}
 	// Create dst, copy src's content into it
 	logrus.Debugf("Creating dest directory: %s", dst)
-	if err := system.MkdirAll(dst, 0755); err != nil {
+	rootUID, rootGID, err := idtools.GetRootUIDGID(archiver.UIDMaps, archiver.GIDMaps)
+	if err != nil {
+		return err
+	}
+	if err := idtools.MkdirAllNewAs(dst, 0755, rootUID, rootGID); err != nil {
 		return err
 	}
 	logrus.Debugf("Calling TarUntar(%s, %s)", src, dst)
@@ -780,7 +817,11 @@ func (archiver *Archiver) CopyFileWithTar(src, dst string) (err error) {
 		dst = filepath.Join(dst, filepath.Base(src))
 	}
 	// Create the holding directory if necessary
-	if err := system.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+	rootUID, rootGID, err := idtools.GetRootUIDGID(archiver.UIDMaps, archiver.GIDMaps)
+	if err != nil {
+		return err
+	}
+	if err := idtools.MkdirAllNewAs(filepath.Dir(dst), 0700, rootUID, rootGID); err != nil {
 		return err
 	}
 
@@ -800,6 +841,9 @@ func (archiver *Archiver) CopyFileWithTar(src, dst string) (err error) {
 		}
 		hdr.Name = filepath.Base(dst)
 		hdr.Mode = int64(chmodTarEntry(os.FileMode(hdr.Mode)))
+		if err := remapIDs(hdr, archiver.UIDMaps, archiver.GIDMaps); err != nil {
+			return err
+		}
 
 		tw := tar.NewWriter(w)
 		defer tw.Close()
@@ -819,6 +863,26 @@ func (archiver *Archiver) CopyFileWithTar(src, dst string) (err error) {
 	return archiver.Untar(r, filepath.Dir(dst), nil)
 }
 
+// untarOptions returns the options to unpack with the id mappings of the
+// archiver, or nil if it has none.
+func (archiver *Archiver) untarOptions() *TarOptions {
+	if archiver.UIDMaps == nil && archiver.GIDMaps == nil {
+		return nil
+	}
+	return &TarOptions{UIDMaps: archiver.UIDMaps, GIDMaps: archiver.GIDMaps}
+}
+
+// remapIDs translates the owner of hdr from the ids of the container to the
+// ids of the host.
+func remapIDs(hdr *tar.Header, uidMaps, gidMaps []idtools.IDMap) error {
+	var err error
+	if hdr.Uid, err = idtools.ToHost(hdr.Uid, uidMaps); err != nil {
+		return err
+	}
+	hdr.Gid, err = idtools.ToHost(hdr.Gid, gidMaps)
+	return err
+}
+
 // CopyFileWithTar emulates the behavior of the 'cp' command-line
 // for a single file. It copies a regular file from path `src` to
 // path `dst`, and preserves all its metadata.
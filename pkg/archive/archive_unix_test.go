@@ -3,8 +3,16 @@
 package archive
 
 import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
+
+	"github.com/docker/docker/pkg/idtools"
 )
 
 func TestCanonicalTarNameForPath(t *testing.T) {
@@ -58,3 +66,58 @@ func TestChmodTarEntry(t *testing.T) {
 		}
 	}
 }
+
+func TestTarUntarWithIDMaps(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("chown requires root")
+	}
+	origin, err := ioutil.TempDir("", "docker-test-untar-origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(origin)
+	if err := ioutil.WriteFile(filepath.Join(origin, "file"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(filepath.Join(origin, "file"), 100001, 100002); err != nil {
+		t.Fatal(err)
+	}
+
+	idMaps := []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+
+	// The ids of the host are translated to the ids of the container in the archive.
+	rdr, err := TarWithOptions(origin, &TarOptions{IncludeFiles: []string{"file"}, UIDMaps: idMaps, GIDMaps: idMaps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, rdr); err != nil {
+		t.Fatal(err)
+	}
+	rdr.Close()
+	hdr, err := tar.NewReader(bytes.NewReader(buf.Bytes())).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Uid != 1 || hdr.Gid != 2 {
+		t.Fatalf("Expected the archived file to be owned by 1:2, got %d:%d", hdr.Uid, hdr.Gid)
+	}
+
+	// And back to the ids of the host when unpacking.
+	dest, err := ioutil.TempDir("", "docker-test-untar-dest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dest)
+	if err := Untar(&buf, dest, &TarOptions{UIDMaps: idMaps, GIDMaps: idMaps}); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(filepath.Join(dest, "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := fi.Sys().(*syscall.Stat_t)
+	if st.Uid != 100001 || st.Gid != 100002 {
+		t.Fatalf("Expected the unpacked file to be owned by 100001:100002, got %d:%d", st.Uid, st.Gid)
+	}
+}
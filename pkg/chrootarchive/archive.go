@@ -8,11 +8,17 @@ import (
 	"path/filepath"
 
 	"github.com/docker/docker/pkg/archive"
-	"github.com/docker/docker/pkg/system"
+	"github.com/docker/docker/pkg/idtools"
 )
 
 var chrootArchiver = &archive.Archiver{Untar: Untar}
 
+// NewArchiver returns an archiver which unpacks in a chroot, with files
+// owned by the host ids of the given id mappings.
+func NewArchiver(uidMaps, gidMaps []idtools.IDMap) *archive.Archiver {
+	return &archive.Archiver{Untar: Untar, UIDMaps: uidMaps, GIDMaps: gidMaps}
+}
+
 // Untar reads a stream of bytes from `archive`, parses it as a tar archive,
 // and unpacks it into the directory at `dest`.
 // The archive may be compressed with one of the following algorithms:
@@ -41,9 +47,14 @@ func untarHandler(tarArchive io.Reader, dest string, options *archive.TarOptions
 		options.ExcludePatterns = []string{}
 	}
 
+	rootUID, rootGID, err := idtools.GetRootUIDGID(options.UIDMaps, options.GIDMaps)
+	if err != nil {
+		return err
+	}
+
 	dest = filepath.Clean(dest)
 	if _, err := os.Stat(dest); os.IsNotExist(err) {
-		if err := system.MkdirAll(dest, 0777); err != nil {
+		if err := idtools.MkdirAllNewAs(dest, 0777, rootUID, rootGID); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,169 @@
+package idtools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IDMap contains a single entry for user namespace range remapping. A list of
+// IDMap entries is what is given to the kernel when creating a user namespace.
+type IDMap struct {
+	ContainerID int
+	HostID      int
+	Size        int
+}
+
+type subIDRange struct {
+	Start  int
+	Length int
+}
+
+type ranges []subIDRange
+
+func (e ranges) Len() int           { return len(e) }
+func (e ranges) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e ranges) Less(i, j int) bool { return e[i].Start < e[j].Start }
+
+const (
+	subuidFileName = "/etc/subuid"
+	subgidFileName = "/etc/subgid"
+)
+
+// MkdirAllAs creates a directory, along with any necessary parents, and
+// changes the ownership of the directory and of the parents it created to
+// the given uid and gid. The ownership of an existing directory is changed
+// as well.
+func MkdirAllAs(path string, mode os.FileMode, ownerUID, ownerGID int) error {
+	return mkdirAs(path, mode, ownerUID, ownerGID, true, true)
+}
+
+// MkdirAllNewAs is like MkdirAllAs, but leaves the ownership of an existing
+// directory untouched.
+func MkdirAllNewAs(path string, mode os.FileMode, ownerUID, ownerGID int) error {
+	return mkdirAs(path, mode, ownerUID, ownerGID, true, false)
+}
+
+// MkdirAs creates a directory and changes its ownership to the given uid and
+// gid. The parent directory must exist.
+func MkdirAs(path string, mode os.FileMode, ownerUID, ownerGID int) error {
+	return mkdirAs(path, mode, ownerUID, ownerGID, false, true)
+}
+
+// GetRootUIDGID returns the host uid and gid the root of the container is
+// mapped to. Without mappings, this is the real root, 0:0.
+func GetRootUIDGID(uidMap, gidMap []IDMap) (int, int, error) {
+	uid, err := ToHost(0, uidMap)
+	if err != nil {
+		return -1, -1, err
+	}
+	gid, err := ToHost(0, gidMap)
+	if err != nil {
+		return -1, -1, err
+	}
+	return uid, gid, nil
+}
+
+// ToContainer translates hostID to the ID it is mapped to in the container.
+// Without a mapping, hostID is returned as is.
+func ToContainer(hostID int, idMap []IDMap) (int, error) {
+	if idMap == nil {
+		return hostID, nil
+	}
+	for _, m := range idMap {
+		if hostID >= m.HostID && hostID < m.HostID+m.Size {
+			return m.ContainerID + (hostID - m.HostID), nil
+		}
+	}
+	return -1, fmt.Errorf("Host ID %d cannot be mapped to a container ID", hostID)
+}
+
+// ToHost translates containerID to the ID it is mapped to on the host.
+// Without a mapping, containerID is returned as is.
+func ToHost(containerID int, idMap []IDMap) (int, error) {
+	if idMap == nil {
+		return containerID, nil
+	}
+	for _, m := range idMap {
+		if containerID >= m.ContainerID && containerID < m.ContainerID+m.Size {
+			return m.HostID + (containerID - m.ContainerID), nil
+		}
+	}
+	return -1, fmt.Errorf("Container ID %d cannot be mapped to a host ID", containerID)
+}
+
+// CreateIDMappings returns the uid and gid mappings of the subordinate ids
+// given to username in /etc/subuid and to groupname in /etc/subgid. The
+// ranges are mapped one after the other, starting from 0 in the container.
+func CreateIDMappings(username, groupname string) ([]IDMap, []IDMap, error) {
+	subuidRanges, err := parseSubidFile(subuidFileName, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(subuidRanges) == 0 {
+		return nil, nil, fmt.Errorf("No subuid ranges found for user %q in %s", username, subuidFileName)
+	}
+	subgidRanges, err := parseSubidFile(subgidFileName, groupname)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(subgidRanges) == 0 {
+		return nil, nil, fmt.Errorf("No subgid ranges found for group %q in %s", groupname, subgidFileName)
+	}
+	return createIDMap(subuidRanges), createIDMap(subgidRanges), nil
+}
+
+func createIDMap(subidRanges ranges) []IDMap {
+	idMap := []IDMap{}
+	sort.Sort(subidRanges)
+	containerID := 0
+	for _, idrange := range subidRanges {
+		idMap = append(idMap, IDMap{
+			ContainerID: containerID,
+			HostID:      idrange.Start,
+			Size:        idrange.Length,
+		})
+		containerID += idrange.Length
+	}
+	return idMap
+}
+
+// parseSubidFile returns the ranges given to name in the subordinate id file
+// at path, made of "name:start:length" lines.
+func parseSubidFile(path, name string) (ranges, error) {
+	var rangeList ranges
+
+	f, err := os.Open(path)
+	if err != nil {
+		return rangeList, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.Split(text, ":")
+		if len(parts) != 3 {
+			return rangeList, fmt.Errorf("Cannot parse %s: invalid line %q", path, text)
+		}
+		if parts[0] != name {
+			continue
+		}
+		start, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return rangeList, fmt.Errorf("Cannot parse %s: invalid start id %q", path, parts[1])
+		}
+		length, err := strconv.Atoi(parts[2])
+		if err != nil || length <= 0 {
+			return rangeList, fmt.Errorf("Cannot parse %s: invalid length %q", path, parts[2])
+		}
+		rangeList = append(rangeList, subIDRange{start, length})
+	}
+	return rangeList, s.Err()
+}
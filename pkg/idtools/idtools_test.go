@@ -0,0 +1,95 @@
+package idtools
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseSubidFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "subid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	content := `# comment
+dockremap:200000:65536
+
+other:100000:65536
+dockremap:100000:1000
+`
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	r, err := parseSubidFile(f.Name(), "dockremap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ranges{{200000, 65536}, {100000, 1000}}
+	if !reflect.DeepEqual(r, expected) {
+		t.Fatalf("Expected ranges %v, got %v", expected, r)
+	}
+
+	idMap := createIDMap(r)
+	expectedMap := []IDMap{
+		{ContainerID: 0, HostID: 100000, Size: 1000},
+		{ContainerID: 1000, HostID: 200000, Size: 65536},
+	}
+	if !reflect.DeepEqual(idMap, expectedMap) {
+		t.Fatalf("Expected map %v, got %v", expectedMap, idMap)
+	}
+
+	if r, err := parseSubidFile(f.Name(), "nobody"); err != nil || len(r) != 0 {
+		t.Fatalf("Expected no ranges for an unknown user, got %v (%v)", r, err)
+	}
+
+	if err := ioutil.WriteFile(f.Name(), []byte("dockremap:100000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseSubidFile(f.Name(), "dockremap"); err == nil {
+		t.Fatal("Expected an error for an invalid line")
+	}
+}
+
+func TestIDMapping(t *testing.T) {
+	idMap := []IDMap{
+		{ContainerID: 0, HostID: 100000, Size: 1000},
+		{ContainerID: 1000, HostID: 200000, Size: 65536},
+	}
+
+	tests := []struct{ container, host int }{
+		{0, 100000},
+		{999, 100999},
+		{1000, 200000},
+		{66535, 265535},
+	}
+	for _, test := range tests {
+		host, err := ToHost(test.container, idMap)
+		if err != nil || host != test.host {
+			t.Fatalf("Expected container ID %d to map to host ID %d, got %d (%v)", test.container, test.host, host, err)
+		}
+		container, err := ToContainer(test.host, idMap)
+		if err != nil || container != test.container {
+			t.Fatalf("Expected host ID %d to map to container ID %d, got %d (%v)", test.host, test.container, container, err)
+		}
+	}
+
+	if _, err := ToHost(66536, idMap); err == nil {
+		t.Fatal("Expected an error for an unmapped container ID")
+	}
+	if _, err := ToContainer(0, idMap); err == nil {
+		t.Fatal("Expected an error for an unmapped host ID")
+	}
+
+	if id, err := ToHost(42, nil); err != nil || id != 42 {
+		t.Fatalf("Expected no translation without a map, got %d (%v)", id, err)
+	}
+
+	uid, gid, err := GetRootUIDGID(idMap, nil)
+	if err != nil || uid != 100000 || gid != 0 {
+		t.Fatalf("Expected root to map to 100000:0, got %d:%d (%v)", uid, gid, err)
+	}
+}
@@ -0,0 +1,47 @@
+// +build !windows
+
+package idtools
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/system"
+)
+
+func mkdirAs(path string, mode os.FileMode, ownerUID, ownerGID int, mkAll, chownExisting bool) error {
+	// paths holds the path asked for and, with mkAll, the parents which
+	// do not exist yet, so that all of them are chowned once created.
+	var paths []string
+	if _, err := os.Stat(path); err == nil {
+		if !chownExisting {
+			return nil
+		}
+		return os.Chown(path, ownerUID, ownerGID)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	paths = append(paths, path)
+
+	if mkAll {
+		for dirPath := filepath.Dir(path); dirPath != "/" && dirPath != "."; dirPath = filepath.Dir(dirPath) {
+			if _, err := os.Stat(dirPath); err != nil && os.IsNotExist(err) {
+				paths = append(paths, dirPath)
+			}
+		}
+		if err := system.MkdirAll(path, mode); err != nil && !os.IsExist(err) {
+			return err
+		}
+	} else {
+		if err := os.Mkdir(path, mode); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+
+	for _, p := range paths {
+		if err := os.Chown(p, ownerUID, ownerGID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
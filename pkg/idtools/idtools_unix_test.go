@@ -0,0 +1,55 @@
+// +build !windows
+
+package idtools
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func checkOwner(t *testing.T, path string, uid, gid int) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := fi.Sys().(*syscall.Stat_t)
+	if int(st.Uid) != uid || int(st.Gid) != gid {
+		t.Fatalf("Expected %s to be owned by %d:%d, got %d:%d", path, uid, gid, st.Uid, st.Gid)
+	}
+}
+
+func TestMkdirAllAs(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("chown requires root")
+	}
+	dir, err := ioutil.TempDir("", "mkdirall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "a", "b")
+	if err := MkdirAllAs(path, 0755, 100, 101); err != nil {
+		t.Fatal(err)
+	}
+	checkOwner(t, path, 100, 101)
+	checkOwner(t, filepath.Join(dir, "a"), 100, 101)
+	checkOwner(t, dir, 0, 0)
+
+	// The ownership of an existing directory is only changed by MkdirAllAs.
+	if err := MkdirAllNewAs(path, 0755, 200, 201); err != nil {
+		t.Fatal(err)
+	}
+	checkOwner(t, path, 100, 101)
+	if err := MkdirAllAs(path, 0755, 200, 201); err != nil {
+		t.Fatal(err)
+	}
+	checkOwner(t, path, 200, 201)
+
+	if err := MkdirAs(filepath.Join(dir, "c", "d"), 0755, 100, 101); err == nil {
+		t.Fatal("Expected an error when the parent does not exist")
+	}
+}
@@ -3,6 +3,7 @@ package mount
 import (
 	"os"
 	"path"
+	"strings"
 	"testing"
 )
 
@@ -22,6 +23,38 @@ func TestMountOptionsParsing(t *testing.T) {
 	}
 }
 
+func TestTmpfsOptionsParsing(t *testing.T) {
+	flag, data, err := ParseTmpfsOptions("noexec,nosuid,size=64m,mode=1777")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != "size=64m,mode=1777" {
+		t.Fatalf("Expected size=64m,mode=1777 got %s", data)
+	}
+	if expectedFlag := NOEXEC | NOSUID; flag != expectedFlag {
+		t.Fatalf("Expected %d got %d", expectedFlag, flag)
+	}
+
+	if _, _, err := ParseTmpfsOptions("size=64m,foo=bar"); err == nil {
+		t.Fatal("Expected an error for an invalid tmpfs option")
+	}
+}
+
+func TestMergeTmpfsOptions(t *testing.T) {
+	options := []string{"noexec", "nosuid", "nodev", "size=65536k", "exec", "size=1g", "mode=1777"}
+	merged, err := MergeTmpfsOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "nosuid,nodev,exec,size=1g,mode=1777"; strings.Join(merged, ",") != expected {
+		t.Fatalf("Expected %s got %s", expected, strings.Join(merged, ","))
+	}
+
+	if _, err := MergeTmpfsOptions([]string{"noexec", "foo=bar"}); err == nil {
+		t.Fatal("Expected an error for an invalid tmpfs option")
+	}
+}
+
 func TestMounted(t *testing.T) {
 	tmp := path.Join(os.TempDir(), "mount-tests")
 	if err := os.MkdirAll(tmp, 0777); err != nil {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -61,15 +62,37 @@ func (c *Client) Call(serviceMethod string, args interface{}, ret interface{}) e
 	return c.callWithRetry(serviceMethod, args, ret, true)
 }
 
+// CallWithoutRetry calls the specified method with the specified arguments
+// for the plugin, failing straight away if the plugin cannot be reached.
+func (c *Client) CallWithoutRetry(serviceMethod string, args interface{}, ret interface{}) error {
+	return c.callWithRetry(serviceMethod, args, ret, false)
+}
+
+// Stream calls the specified method with the specified arguments for the
+// plugin and returns the body of the response, for the methods streaming
+// their results. It does not retry.
+func (c *Client) Stream(serviceMethod string, args interface{}) (io.ReadCloser, error) {
+	return c.send(serviceMethod, args, false)
+}
+
 func (c *Client) callWithRetry(serviceMethod string, args interface{}, ret interface{}, retry bool) error {
+	body, err := c.send(serviceMethod, args, retry)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(&ret)
+}
+
+func (c *Client) send(serviceMethod string, args interface{}, retry bool) (io.ReadCloser, error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(args); err != nil {
-		return err
+		return nil, err
 	}
 
 	req, err := http.NewRequest("POST", "/"+serviceMethod, &buf)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.Header.Add("Accept", versionMimetype)
 	req.URL.Scheme = c.scheme
@@ -82,12 +105,12 @@ func (c *Client) callWithRetry(serviceMethod string, args interface{}, ret inter
 		resp, err := c.http.Do(req)
 		if err != nil {
 			if !retry {
-				return err
+				return nil, err
 			}
 
 			timeOff := backoff(retries)
 			if abort(start, timeOff) {
-				return err
+				return nil, err
 			}
 			retries++
 			logrus.Warnf("Unable to connect to plugin: %s, retrying in %v", c.addr, timeOff)
@@ -95,16 +118,16 @@ func (c *Client) callWithRetry(serviceMethod string, args interface{}, ret inter
 			continue
 		}
 
-		defer resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
 			remoteErr, err := ioutil.ReadAll(resp.Body)
 			if err != nil {
-				return &remoteError{err.Error(), serviceMethod}
+				return nil, &remoteError{err.Error(), serviceMethod}
 			}
-			return &remoteError{string(remoteErr), serviceMethod}
+			return nil, &remoteError{string(remoteErr), serviceMethod}
 		}
 
-		return json.NewDecoder(resp.Body).Decode(&ret)
+		return resp.Body, nil
 	}
 }
 
@@ -2,9 +2,11 @@ package plugins
 
 import (
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -121,3 +123,34 @@ func TestClientScheme(t *testing.T) {
 		}
 	}
 }
+
+func TestStream(t *testing.T) {
+	addr := setupRemotePluginServer()
+	defer teardownRemotePluginServer()
+
+	mux.HandleFunc("/Test.Stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", versionMimetype)
+		io.Copy(w, r.Body)
+	})
+	mux.HandleFunc("/Test.Fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "failed", http.StatusInternalServerError)
+	})
+
+	c, _ := NewClient(addr, tlsconfig.Options{InsecureSkipVerify: true})
+	body, err := c.Stream("Test.Stream", "streamed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "\"streamed\"\n" {
+		t.Fatalf("Expected the body to be streamed, got %q", b)
+	}
+
+	if _, err := c.Stream("Test.Fail", nil); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatalf("Expected the error of the plugin, got %v", err)
+	}
+}
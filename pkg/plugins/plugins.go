@@ -109,10 +109,6 @@ func (p *Plugin) activateWithLock() error {
 	return nil
 }
 
-func load(name string) (*Plugin, error) {
-	return loadWithRetry(name, true)
-}
-
 func loadWithRetry(name string, retry bool) (*Plugin, error) {
 	registry := newLocalRegistry()
 	start := time.Now()
@@ -151,19 +147,30 @@ func loadWithRetry(name string, retry bool) (*Plugin, error) {
 	}
 }
 
-func get(name string) (*Plugin, error) {
+func get(name string, retry bool) (*Plugin, error) {
 	storage.Lock()
 	pl, ok := storage.plugins[name]
 	storage.Unlock()
 	if ok {
 		return pl, pl.activate()
 	}
-	return load(name)
+	return loadWithRetry(name, retry)
 }
 
 // Get returns the plugin given the specified name and requested implementation.
 func Get(name, imp string) (*Plugin, error) {
-	pl, err := get(name)
+	return getImplementation(name, imp, true)
+}
+
+// GetWithoutRetry returns the plugin given the specified name and requested
+// implementation, failing straight away rather than waiting for the plugin
+// to be installed when it is not found.
+func GetWithoutRetry(name, imp string) (*Plugin, error) {
+	return getImplementation(name, imp, false)
+}
+
+func getImplementation(name, imp string, retry bool) (*Plugin, error) {
+	pl, err := get(name, retry)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +33,21 @@ func TestSendToMultipleSubs(t *testing.T) {
 	}
 }
 
+func TestSubscribeTopic(t *testing.T) {
+	p := NewPublisher(100*time.Millisecond, 10)
+	c := p.SubscribeTopic(func(v interface{}) bool {
+		return v.(string) != "skipped"
+	})
+
+	p.Publish("skipped")
+	p.Publish("hi")
+
+	msg := <-c
+	if msg.(string) != "hi" {
+		t.Fatalf("expected message hi but received %v", msg)
+	}
+}
+
 func TestEvictOneSub(t *testing.T) {
 	p := NewPublisher(100*time.Millisecond, 10)
 	s1 := p.Subscribe()
@@ -0,0 +1,435 @@
+package seccomp
+
+import (
+	"runtime"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// allowedSyscalls are the syscalls the default profile allows without
+// conditions. Among others, it leaves out the syscalls managing kernel
+// modules, mounts, namespaces, keyrings, the clock and the system, which
+// are either reserved to the host or not namespaced. Syscalls unknown to
+// an architecture are ignored on it.
+var allowedSyscalls = []string{
+	"accept",
+	"accept4",
+	"access",
+	"alarm",
+	"bind",
+	"brk",
+	"capget",
+	"capset",
+	"chdir",
+	"chmod",
+	"chown",
+	"chown32",
+	"chroot",
+	"clock_getres",
+	"clock_gettime",
+	"clock_nanosleep",
+	"close",
+	"connect",
+	"creat",
+	"dup",
+	"dup2",
+	"dup3",
+	"epoll_create",
+	"epoll_create1",
+	"epoll_ctl",
+	"epoll_ctl_old",
+	"epoll_pwait",
+	"epoll_wait",
+	"epoll_wait_old",
+	"eventfd",
+	"eventfd2",
+	"execve",
+	"execveat",
+	"exit",
+	"exit_group",
+	"faccessat",
+	"fadvise64",
+	"fadvise64_64",
+	"fallocate",
+	"fanotify_mark",
+	"fchdir",
+	"fchmod",
+	"fchmodat",
+	"fchown",
+	"fchown32",
+	"fchownat",
+	"fcntl",
+	"fcntl64",
+	"fdatasync",
+	"fgetxattr",
+	"flistxattr",
+	"flock",
+	"fork",
+	"fremovexattr",
+	"fsetxattr",
+	"fstat",
+	"fstat64",
+	"fstatat64",
+	"fstatfs",
+	"fstatfs64",
+	"fsync",
+	"ftruncate",
+	"ftruncate64",
+	"futex",
+	"futimesat",
+	"getcpu",
+	"getcwd",
+	"getdents",
+	"getdents64",
+	"getegid",
+	"getegid32",
+	"geteuid",
+	"geteuid32",
+	"getgid",
+	"getgid32",
+	"getgroups",
+	"getgroups32",
+	"getitimer",
+	"getpeername",
+	"getpgid",
+	"getpgrp",
+	"getpid",
+	"getppid",
+	"getpriority",
+	"getrandom",
+	"getresgid",
+	"getresgid32",
+	"getresuid",
+	"getresuid32",
+	"getrlimit",
+	"get_robust_list",
+	"getrusage",
+	"getsid",
+	"getsockname",
+	"getsockopt",
+	"get_thread_area",
+	"gettid",
+	"gettimeofday",
+	"getuid",
+	"getuid32",
+	"getxattr",
+	"inotify_add_watch",
+	"inotify_init",
+	"inotify_init1",
+	"inotify_rm_watch",
+	"io_cancel",
+	"ioctl",
+	"io_destroy",
+	"io_getevents",
+	"ioprio_get",
+	"ioprio_set",
+	"io_setup",
+	"io_submit",
+	"ipc",
+	"kill",
+	"lchown",
+	"lchown32",
+	"lgetxattr",
+	"link",
+	"linkat",
+	"listen",
+	"listxattr",
+	"llistxattr",
+	"_llseek",
+	"lremovexattr",
+	"lseek",
+	"lsetxattr",
+	"lstat",
+	"lstat64",
+	"madvise",
+	"memfd_create",
+	"mincore",
+	"mkdir",
+	"mkdirat",
+	"mknod",
+	"mknodat",
+	"mlock",
+	"mlockall",
+	"mmap",
+	"mmap2",
+	"mprotect",
+	"mq_getsetattr",
+	"mq_notify",
+	"mq_open",
+	"mq_timedreceive",
+	"mq_timedsend",
+	"mq_unlink",
+	"mremap",
+	"msgctl",
+	"msgget",
+	"msgrcv",
+	"msgsnd",
+	"msync",
+	"munlock",
+	"munlockall",
+	"munmap",
+	"nanosleep",
+	"newfstatat",
+	"_newselect",
+	"open",
+	"openat",
+	"pause",
+	"pipe",
+	"pipe2",
+	"poll",
+	"ppoll",
+	"prctl",
+	"pread64",
+	"preadv",
+	"prlimit64",
+	"pselect6",
+	"pwrite64",
+	"pwritev",
+	"read",
+	"readahead",
+	"readlink",
+	"readlinkat",
+	"readv",
+	"recv",
+	"recvfrom",
+	"recvmmsg",
+	"recvmsg",
+	"remap_file_pages",
+	"removexattr",
+	"rename",
+	"renameat",
+	"renameat2",
+	"restart_syscall",
+	"rmdir",
+	"rt_sigaction",
+	"rt_sigpending",
+	"rt_sigprocmask",
+	"rt_sigqueueinfo",
+	"rt_sigreturn",
+	"rt_sigsuspend",
+	"rt_sigtimedwait",
+	"rt_tgsigqueueinfo",
+	"sched_getaffinity",
+	"sched_getattr",
+	"sched_getparam",
+	"sched_get_priority_max",
+	"sched_get_priority_min",
+	"sched_getscheduler",
+	"sched_rr_get_interval",
+	"sched_setaffinity",
+	"sched_setattr",
+	"sched_setparam",
+	"sched_setscheduler",
+	"sched_yield",
+	"seccomp",
+	"select",
+	"semctl",
+	"semget",
+	"semop",
+	"semtimedop",
+	"send",
+	"sendfile",
+	"sendfile64",
+	"sendmmsg",
+	"sendmsg",
+	"sendto",
+	"setdomainname",
+	"setfsgid",
+	"setfsgid32",
+	"setfsuid",
+	"setfsuid32",
+	"setgid",
+	"setgid32",
+	"setgroups",
+	"setgroups32",
+	"sethostname",
+	"setitimer",
+	"setpgid",
+	"setpriority",
+	"setregid",
+	"setregid32",
+	"setresgid",
+	"setresgid32",
+	"setresuid",
+	"setresuid32",
+	"setreuid",
+	"setreuid32",
+	"setrlimit",
+	"set_robust_list",
+	"setsid",
+	"setsockopt",
+	"set_thread_area",
+	"set_tid_address",
+	"setuid",
+	"setuid32",
+	"setxattr",
+	"shmat",
+	"shmctl",
+	"shmdt",
+	"shmget",
+	"shutdown",
+	"sigaltstack",
+	"signalfd",
+	"signalfd4",
+	"sigreturn",
+	"socket",
+	"socketcall",
+	"socketpair",
+	"splice",
+	"stat",
+	"stat64",
+	"statfs",
+	"statfs64",
+	"symlink",
+	"symlinkat",
+	"sync",
+	"sync_file_range",
+	"syncfs",
+	"sysinfo",
+	"tee",
+	"tgkill",
+	"time",
+	"timer_create",
+	"timer_delete",
+	"timerfd_create",
+	"timerfd_gettime",
+	"timerfd_settime",
+	"timer_getoverrun",
+	"timer_gettime",
+	"timer_settime",
+	"times",
+	"tkill",
+	"truncate",
+	"truncate64",
+	"ugetrlimit",
+	"umask",
+	"uname",
+	"unlink",
+	"unlinkat",
+	"utime",
+	"utimensat",
+	"utimes",
+	"vfork",
+	"vmsplice",
+	"wait4",
+	"waitid",
+	"waitpid",
+	"write",
+	"writev",
+}
+
+// cloneNamespaceFlags are the flags of clone creating new namespaces.
+const cloneNamespaceFlags = 0x00020000 | // CLONE_NEWNS
+	0x04000000 | // CLONE_NEWUTS
+	0x08000000 | // CLONE_NEWIPC
+	0x10000000 | // CLONE_NEWUSER
+	0x20000000 | // CLONE_NEWPID
+	0x40000000 // CLONE_NEWNET
+
+// personalities are the execution domains processes may switch to: Linux,
+// 32-bit Linux, the 2.6 uname emulation of both, and the query of the
+// current personality.
+var personalities = []uint64{0x0, 0x8, 0x20000, 0x20008, 0xffffffff}
+
+// DefaultProfile returns the seccomp profile applied to the containers
+// which are not given one, for the architecture of the daemon.
+func DefaultProfile() *Seccomp {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Architectures: defaultArchitectures(),
+	}
+	for _, name := range allowedSyscalls {
+		profile.Syscalls = append(profile.Syscalls, &Syscall{
+			Name:   name,
+			Action: ActAllow,
+			Args:   []*Arg{},
+		})
+	}
+	profile.Syscalls = append(profile.Syscalls, archSyscalls()...)
+
+	// Threads and processes are allowed, new namespaces are not.
+	profile.Syscalls = append(profile.Syscalls, &Syscall{
+		Name:   "clone",
+		Action: ActAllow,
+		Args: []*Arg{
+			{
+				Index:    cloneFlagsIndex(),
+				Value:    cloneNamespaceFlags,
+				ValueTwo: 0,
+				Op:       OpMaskedEqual,
+			},
+		},
+	})
+
+	for _, p := range personalities {
+		profile.Syscalls = append(profile.Syscalls, &Syscall{
+			Name:   "personality",
+			Action: ActAllow,
+			Args: []*Arg{
+				{
+					Index: 0,
+					Value: p,
+					Op:    OpEqualTo,
+				},
+			},
+		})
+	}
+	return profile
+}
+
+// GetDefaultProfile returns the default profile, converted into the seccomp
+// configuration of libcontainer.
+func GetDefaultProfile() *configs.Seccomp {
+	config, err := setupSeccomp(DefaultProfile())
+	if err != nil {
+		// The default profile is valid, this is a programming error.
+		panic(err)
+	}
+	return config
+}
+
+// defaultArchitectures returns the architectures whose binaries run on the
+// architecture of the daemon.
+func defaultArchitectures() []Arch {
+	switch runtime.GOARCH {
+	case "amd64":
+		return []Arch{ArchX86_64, ArchX86, ArchX32}
+	case "arm64":
+		return []Arch{ArchAARCH64, ArchARM}
+	case "mips64":
+		return []Arch{ArchMIPS64, ArchMIPS64N32, ArchMIPS}
+	case "mips64le":
+		return []Arch{ArchMIPSEL64, ArchMIPSEL64N32, ArchMIPSEL}
+	}
+	return []Arch{}
+}
+
+// archSyscalls returns the syscalls the default profile allows which only
+// exist on the architecture of the daemon.
+func archSyscalls() []*Syscall {
+	var names []string
+	switch runtime.GOARCH {
+	case "amd64", "386":
+		names = []string{"arch_prctl", "modify_ldt"}
+	case "arm", "arm64":
+		names = []string{"breakpoint", "cacheflush", "set_tls"}
+	}
+	var syscalls []*Syscall
+	for _, name := range names {
+		syscalls = append(syscalls, &Syscall{
+			Name:   name,
+			Action: ActAllow,
+			Args:   []*Arg{},
+		})
+	}
+	return syscalls
+}
+
+// cloneFlagsIndex returns the index of the flags in the arguments of clone,
+// which come second on s390.
+func cloneFlagsIndex() uint {
+	if runtime.GOARCH == "s390x" {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,181 @@
+// Package seccomp parses seccomp profiles, which restrict the syscalls the
+// processes of a container can make, into the configuration of libcontainer.
+package seccomp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// Seccomp is a seccomp profile, as written in its JSON file.
+type Seccomp struct {
+	// DefaultAction is taken for the syscalls which match no rule.
+	DefaultAction Action `json:"defaultAction"`
+	// Architectures lists the architectures whose syscalls are filtered,
+	// in addition to the native architecture.
+	Architectures []Arch     `json:"architectures"`
+	Syscalls      []*Syscall `json:"syscalls"`
+}
+
+// Arch is the name of an architecture, as defined by libseccomp.
+type Arch string
+
+// Architectures supported in profiles.
+const (
+	ArchX86         Arch = "SCMP_ARCH_X86"
+	ArchX86_64      Arch = "SCMP_ARCH_X86_64"
+	ArchX32         Arch = "SCMP_ARCH_X32"
+	ArchARM         Arch = "SCMP_ARCH_ARM"
+	ArchAARCH64     Arch = "SCMP_ARCH_AARCH64"
+	ArchMIPS        Arch = "SCMP_ARCH_MIPS"
+	ArchMIPS64      Arch = "SCMP_ARCH_MIPS64"
+	ArchMIPS64N32   Arch = "SCMP_ARCH_MIPS64N32"
+	ArchMIPSEL      Arch = "SCMP_ARCH_MIPSEL"
+	ArchMIPSEL64    Arch = "SCMP_ARCH_MIPSEL64"
+	ArchMIPSEL64N32 Arch = "SCMP_ARCH_MIPSEL64N32"
+)
+
+// Action is the action taken when a syscall is matched.
+type Action string
+
+// Actions supported in profiles.
+const (
+	ActKill  Action = "SCMP_ACT_KILL"
+	ActTrap  Action = "SCMP_ACT_TRAP"
+	ActErrno Action = "SCMP_ACT_ERRNO"
+	ActAllow Action = "SCMP_ACT_ALLOW"
+)
+
+// Operator compares an argument of a syscall to the values of a rule.
+type Operator string
+
+// Operators supported in profiles.
+const (
+	OpNotEqual     Operator = "SCMP_CMP_NE"
+	OpLessThan     Operator = "SCMP_CMP_LT"
+	OpLessEqual    Operator = "SCMP_CMP_LE"
+	OpEqualTo      Operator = "SCMP_CMP_EQ"
+	OpGreaterEqual Operator = "SCMP_CMP_GE"
+	OpGreaterThan  Operator = "SCMP_CMP_GT"
+	OpMaskedEqual  Operator = "SCMP_CMP_MASKED_EQ"
+)
+
+// Arg is a condition on an argument of a syscall. With OpMaskedEqual, the
+// argument masked by Value must be equal to ValueTwo.
+type Arg struct {
+	Index    uint     `json:"index"`
+	Value    uint64   `json:"value"`
+	ValueTwo uint64   `json:"valueTwo"`
+	Op       Operator `json:"op"`
+}
+
+// Syscall is a rule matching a syscall, and all the conditions of Args on
+// its arguments.
+type Syscall struct {
+	Name   string `json:"name"`
+	Action Action `json:"action"`
+	Args   []*Arg `json:"args"`
+}
+
+// syscallArgs is the number of arguments a syscall can be given.
+const syscallArgs = 6
+
+var archs = map[Arch]string{
+	ArchX86:         "x86",
+	ArchX86_64:      "amd64",
+	ArchX32:         "x32",
+	ArchARM:         "arm",
+	ArchAARCH64:     "arm64",
+	ArchMIPS:        "mips",
+	ArchMIPS64:      "mips64",
+	ArchMIPS64N32:   "mips64n32",
+	ArchMIPSEL:      "mipsel",
+	ArchMIPSEL64:    "mipsel64",
+	ArchMIPSEL64N32: "mipsel64n32",
+}
+
+var actions = map[Action]configs.Action{
+	ActKill:  configs.Kill,
+	ActTrap:  configs.Trap,
+	ActErrno: configs.Errno,
+	ActAllow: configs.Allow,
+}
+
+var operators = map[Operator]configs.Operator{
+	OpNotEqual:     configs.NotEqualTo,
+	OpLessThan:     configs.LessThan,
+	OpLessEqual:    configs.LessThanOrEqualTo,
+	OpEqualTo:      configs.EqualTo,
+	OpGreaterEqual: configs.GreaterThanOrEqualTo,
+	OpGreaterThan:  configs.GreaterThan,
+	OpMaskedEqual:  configs.MaskEqualTo,
+}
+
+// LoadProfile parses and validates the JSON seccomp profile in body.
+func LoadProfile(body []byte) (*configs.Seccomp, error) {
+	var profile Seccomp
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return nil, fmt.Errorf("Decoding seccomp profile failed: %v", err)
+	}
+	return setupSeccomp(&profile)
+}
+
+// setupSeccomp validates profile and converts it into the seccomp
+// configuration of libcontainer.
+func setupSeccomp(profile *Seccomp) (*configs.Seccomp, error) {
+	config := &configs.Seccomp{}
+
+	if profile.DefaultAction == "" {
+		return nil, fmt.Errorf("The seccomp profile has no default action")
+	}
+	defaultAction, ok := actions[profile.DefaultAction]
+	if !ok {
+		return nil, fmt.Errorf("Invalid default action %q in seccomp profile", profile.DefaultAction)
+	}
+	config.DefaultAction = defaultAction
+
+	for _, a := range profile.Architectures {
+		arch, ok := archs[a]
+		if !ok {
+			return nil, fmt.Errorf("Invalid architecture %q in seccomp profile", a)
+		}
+		config.Architectures = append(config.Architectures, arch)
+	}
+
+	for _, call := range profile.Syscalls {
+		if call == nil || call.Name == "" {
+			return nil, fmt.Errorf("A syscall of the seccomp profile has no name")
+		}
+		action, ok := actions[call.Action]
+		if !ok {
+			return nil, fmt.Errorf("Invalid action %q for syscall %s in seccomp profile", call.Action, call.Name)
+		}
+		newCall := &configs.Syscall{
+			Name:   call.Name,
+			Action: action,
+		}
+		for _, arg := range call.Args {
+			if arg == nil {
+				return nil, fmt.Errorf("Invalid argument condition for syscall %s in seccomp profile", call.Name)
+			}
+			if arg.Index >= syscallArgs {
+				return nil, fmt.Errorf("Invalid argument index %d for syscall %s in seccomp profile, syscalls have %d arguments", arg.Index, call.Name, syscallArgs)
+			}
+			op, ok := operators[arg.Op]
+			if !ok {
+				return nil, fmt.Errorf("Invalid operator %q for syscall %s in seccomp profile", arg.Op, call.Name)
+			}
+			newCall.Args = append(newCall.Args, &configs.Arg{
+				Index:    arg.Index,
+				Value:    arg.Value,
+				ValueTwo: arg.ValueTwo,
+				Op:       op,
+			})
+		}
+		config.Syscalls = append(config.Syscalls, newCall)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,82 @@
+package seccomp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestLoadProfile(t *testing.T) {
+	profile := `{
+	"defaultAction": "SCMP_ACT_ERRNO",
+	"architectures": ["SCMP_ARCH_X86_64", "SCMP_ARCH_X86"],
+	"syscalls": [
+		{"name": "read", "action": "SCMP_ACT_ALLOW", "args": []},
+		{"name": "personality", "action": "SCMP_ACT_ALLOW", "args": [{"index": 0, "value": 8, "valueTwo": 0, "op": "SCMP_CMP_GE"}]}
+	]
+}`
+	config, err := LoadProfile([]byte(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DefaultAction != configs.Errno {
+		t.Fatalf("Expected the default action to be Errno, got %v", config.DefaultAction)
+	}
+	if len(config.Architectures) != 2 || config.Architectures[0] != "amd64" || config.Architectures[1] != "x86" {
+		t.Fatalf("Unexpected architectures %v", config.Architectures)
+	}
+	if len(config.Syscalls) != 2 {
+		t.Fatalf("Expected 2 syscalls, got %d", len(config.Syscalls))
+	}
+	call := config.Syscalls[1]
+	if call.Name != "personality" || call.Action != configs.Allow || len(call.Args) != 1 {
+		t.Fatalf("Unexpected syscall %+v", call)
+	}
+	if arg := call.Args[0]; arg.Index != 0 || arg.Value != 8 || arg.Op != configs.GreaterThanOrEqualTo {
+		t.Fatalf("Unexpected argument condition %+v", arg)
+	}
+}
+
+func TestLoadInvalidProfile(t *testing.T) {
+	cases := []struct {
+		profile, expected string
+	}{
+		{`{`, "Decoding seccomp profile failed"},
+		{`{"syscalls": []}`, "no default action"},
+		{`{"defaultAction": "SCMP_ACT_NOPE"}`, "Invalid default action"},
+		{`{"defaultAction": "SCMP_ACT_ALLOW", "architectures": ["SCMP_ARCH_VAX"]}`, "Invalid architecture"},
+		{`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"action": "SCMP_ACT_ERRNO"}]}`, "has no name"},
+		{`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "mount", "action": "SCMP_ACT_TRACE"}]}`, "Invalid action"},
+		{`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "mount", "action": "SCMP_ACT_ERRNO", "args": [{"index": 6, "op": "SCMP_CMP_EQ"}]}]}`, "Invalid argument index"},
+		{`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "mount", "action": "SCMP_ACT_ERRNO", "args": [{"index": 0, "op": "SCMP_CMP_LIKE"}]}]}`, "Invalid operator"},
+	}
+	for _, c := range cases {
+		_, err := LoadProfile([]byte(c.profile))
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected an error containing %q for %s, got %v", c.expected, c.profile, err)
+		}
+	}
+}
+
+func TestDefaultProfile(t *testing.T) {
+	// The default profile goes through the same validation as the custom
+	// ones once written to JSON.
+	b, err := json.Marshal(DefaultProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadProfile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DefaultAction != configs.Errno {
+		t.Fatalf("Expected the default action to be Errno, got %v", config.DefaultAction)
+	}
+	for _, call := range config.Syscalls {
+		if call.Name == "mount" || call.Name == "unshare" {
+			t.Fatalf("Expected %s not to be allowed by the default profile", call.Name)
+		}
+	}
+}
@@ -686,7 +686,7 @@ func TestMirrorEndpointLookup(t *testing.T) {
 		}
 		return false
 	}
-	s := Service{Config: makeServiceConfig([]string{"my.mirror"}, nil)}
+	s := Service{config: makeServiceConfig([]string{"my.mirror"}, nil)}
 	imageName := IndexName + "/test/image"
 
 	pushAPIEndpoints, err := s.LookupPushEndpoints(imageName)
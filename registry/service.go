@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"net/http"
 	"net/url"
+	"sync"
 
 	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/docker/cliconfig"
@@ -12,17 +13,36 @@ import (
 // Service is a registry service. It tracks configuration data such as a list
 // of mirrors.
 type Service struct {
-	Config *ServiceConfig
+	mu     sync.RWMutex
+	config *ServiceConfig
 }
 
 // NewService returns a new instance of Service ready to be
 // installed into an engine.
 func NewService(options *Options) *Service {
 	return &Service{
-		Config: NewServiceConfig(options),
+		config: NewServiceConfig(options),
 	}
 }
 
+// ServiceConfig returns the configuration of the service. It is replaced as
+// a whole when the service is reconfigured, and must not be modified.
+func (s *Service) ServiceConfig() *ServiceConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// Reconfigure replaces the configuration of the service with the one of
+// options. The requests in progress keep the configuration they started
+// with.
+func (s *Service) Reconfigure(options *Options) {
+	config := NewServiceConfig(options)
+	s.mu.Lock()
+	s.config = config
+	s.mu.Unlock()
+}
+
 // Auth contacts the public registry with the provided credentials,
 // and returns OK if authentication was successful.
 // It can be used to verify the validity of a client's credentials.
@@ -75,12 +95,12 @@ func (s *Service) Search(term string, authConfig *cliconfig.AuthConfig, headers
 // ResolveRepository splits a repository name into its components
 // and configuration of the associated registry.
 func (s *Service) ResolveRepository(name string) (*RepositoryInfo, error) {
-	return s.Config.NewRepositoryInfo(name)
+	return s.ServiceConfig().NewRepositoryInfo(name)
 }
 
 // ResolveIndex takes indexName and returns index info
 func (s *Service) ResolveIndex(name string) (*IndexInfo, error) {
-	return s.Config.NewIndexInfo(name)
+	return s.ServiceConfig().NewIndexInfo(name)
 }
 
 // APIEndpoint represents a remote API endpoint
@@ -102,7 +122,7 @@ func (e APIEndpoint) ToV1Endpoint(metaHeaders http.Header) (*Endpoint, error) {
 
 // TLSConfig constructs a client TLS configuration based on server defaults
 func (s *Service) TLSConfig(hostname string) (*tls.Config, error) {
-	return newTLSConfig(hostname, s.Config.isSecureIndex(hostname))
+	return newTLSConfig(hostname, s.ServiceConfig().isSecureIndex(hostname))
 }
 
 func (s *Service) tlsConfigForMirror(mirror string) (*tls.Config, error) {
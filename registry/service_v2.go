@@ -13,7 +13,7 @@ func (s *Service) lookupV2Endpoints(repoName string) (endpoints []APIEndpoint, e
 	tlsConfig := &cfg
 	if strings.HasPrefix(repoName, DefaultNamespace+"/") {
 		// v2 mirrors
-		for _, mirror := range s.Config.Mirrors {
+		for _, mirror := range s.ServiceConfig().Mirrors {
 			mirrorTLSConfig, err := s.tlsConfigForMirror(mirror)
 			if err != nil {
 				return nil, err
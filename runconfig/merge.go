@@ -59,6 +59,28 @@ func Merge(userConf, imageConf *Config) error {
 	if userConf.WorkingDir == "" {
 		userConf.WorkingDir = imageConf.WorkingDir
 	}
+	if userConf.StopTimeout == nil {
+		userConf.StopTimeout = imageConf.StopTimeout
+	}
+	if imageConf.Healthcheck != nil {
+		if userConf.Healthcheck == nil {
+			healthcheck := *imageConf.Healthcheck
+			userConf.Healthcheck = &healthcheck
+		} else {
+			if len(userConf.Healthcheck.Test) == 0 {
+				userConf.Healthcheck.Test = imageConf.Healthcheck.Test
+			}
+			if userConf.Healthcheck.Interval == 0 {
+				userConf.Healthcheck.Interval = imageConf.Healthcheck.Interval
+			}
+			if userConf.Healthcheck.Timeout == 0 {
+				userConf.Healthcheck.Timeout = imageConf.Healthcheck.Timeout
+			}
+			if userConf.Healthcheck.Retries == 0 {
+				userConf.Healthcheck.Retries = imageConf.Healthcheck.Retries
+			}
+		}
+	}
 	if len(userConf.Volumes) == 0 {
 		userConf.Volumes = imageConf.Volumes
 	} else {
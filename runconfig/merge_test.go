@@ -2,6 +2,7 @@ package runconfig
 
 import (
 	"testing"
+	"time"
 
 	"github.com/docker/docker/pkg/nat"
 )
@@ -81,3 +82,62 @@ func TestMerge(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeHealthcheck(t *testing.T) {
+	configImage := &Config{
+		Healthcheck: &HealthConfig{
+			Test:     []string{"CMD-SHELL", "exit 0"},
+			Interval: 5 * time.Second,
+			Retries:  2,
+		},
+	}
+	configUser := &Config{
+		Healthcheck: &HealthConfig{
+			Interval: 1 * time.Second,
+		},
+	}
+
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+
+	hc := configUser.Healthcheck
+	if len(hc.Test) != 2 || hc.Test[1] != "exit 0" {
+		t.Fatalf("Expected the test to be inherited from the image, got %v", hc.Test)
+	}
+	if hc.Interval != 1*time.Second {
+		t.Fatalf("Expected the user interval to be kept, got %s", hc.Interval)
+	}
+	if hc.Retries != 2 {
+		t.Fatalf("Expected the retries to be inherited from the image, got %d", hc.Retries)
+	}
+
+	configUser = &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if hc := configUser.Healthcheck; hc == nil || hc.Interval != 5*time.Second || len(hc.Test) != 2 {
+		t.Fatalf("Expected the image healthcheck to be used, got %#v", hc)
+	}
+}
+
+func TestMergeStopTimeout(t *testing.T) {
+	imageTimeout, userTimeout := 30, 5
+	configImage := &Config{StopTimeout: &imageTimeout}
+
+	configUser := &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.StopTimeout == nil || *configUser.StopTimeout != 30 {
+		t.Fatalf("Expected the stop timeout to be inherited from the image, got %v", configUser.StopTimeout)
+	}
+
+	configUser = &Config{StopTimeout: &userTimeout}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if *configUser.StopTimeout != 5 {
+		t.Fatalf("Expected the user stop timeout to be kept, got %d", *configUser.StopTimeout)
+	}
+}
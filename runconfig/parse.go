@@ -2,11 +2,15 @@ package runconfig
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/blkiodev"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/nat"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/signal"
@@ -41,12 +45,22 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		// FIXME: use utils.ListOpts for attach and volumes?
 		flAttach  = opts.NewListOpts(opts.ValidateAttach)
 		flVolumes = opts.NewListOpts(opts.ValidatePath)
+		flTmpfs   = opts.NewListOpts(nil)
 		flLinks   = opts.NewListOpts(opts.ValidateLink)
 		flEnv     = opts.NewListOpts(opts.ValidateEnv)
 		flLabels  = opts.NewListOpts(opts.ValidateEnv)
 		flDevices = opts.NewListOpts(opts.ValidateDevice)
 
 		flUlimits = opts.NewUlimitOpt(nil)
+		flSysctls = opts.NewMapOpts(nil, opts.ValidateSysctl)
+
+		flStorageOpt = opts.NewMapOpts(nil, validateStorageOpt)
+
+		flBlkioWeightDevice = opts.NewWeightdeviceOpt(validateWeightDevice)
+		flDeviceReadBps     = opts.NewThrottledeviceOpt(validateThrottleBpsDevice)
+		flDeviceWriteBps    = opts.NewThrottledeviceOpt(validateThrottleBpsDevice)
+		flDeviceReadIOps    = opts.NewThrottledeviceOpt(validateThrottleIOpsDevice)
+		flDeviceWriteIOps   = opts.NewThrottledeviceOpt(validateThrottleIOpsDevice)
 
 		flPublish     = opts.NewListOpts(nil)
 		flExpose      = opts.NewListOpts(nil)
@@ -88,21 +102,36 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		flCpusetMems        = cmd.String([]string{"-cpuset-mems"}, "", "MEMs in which to allow execution (0-3, 0,1)")
 		flBlkioWeight       = cmd.Int64([]string{"-blkio-weight"}, 0, "Block IO (relative weight), between 10 and 1000")
 		flSwappiness        = cmd.Int64([]string{"-memory-swappiness"}, -1, "Tuning container memory swappiness (0 to 100)")
+		flPidsLimit         = cmd.Int64([]string{"-pids-limit"}, 0, "Tune container pids limit (set -1 for unlimited)")
 		flNetMode           = cmd.String([]string{"-net"}, "default", "Set the Network mode for the container")
 		flMacAddress        = cmd.String([]string{"-mac-address"}, "", "Container MAC address (e.g. 92:d0:c6:0a:29:33)")
 		flIpcMode           = cmd.String([]string{"-ipc"}, "", "IPC namespace to use")
+		flShmSize           = cmd.String([]string{"-shm-size"}, "", "Size of /dev/shm, default value is 64MB")
 		flRestartPolicy     = cmd.String([]string{"-restart"}, "no", "Restart policy to apply when a container exits")
 		flReadonlyRootfs    = cmd.Bool([]string{"-read-only"}, false, "Mount the container's root filesystem as read only")
 		flLoggingDriver     = cmd.String([]string{"-log-driver"}, "", "Logging driver for container")
 		flCgroupParent      = cmd.String([]string{"-cgroup-parent"}, "", "Optional parent cgroup for the container")
 		flVolumeDriver      = cmd.String([]string{"-volume-driver"}, "", "Optional volume driver for the container")
 		flStopSignal        = cmd.String([]string{"-stop-signal"}, signal.DefaultStopSignal, fmt.Sprintf("Signal to stop a container, %v by default", signal.DefaultStopSignal))
+		flStopTimeout       = cmd.Int([]string{"-stop-timeout"}, 0, "Timeout (in seconds) to stop a container")
+		flHealthCmd         = cmd.String([]string{"-health-cmd"}, "", "Command to run to check health")
+		flHealthInterval    = cmd.Duration([]string{"-health-interval"}, 0, "Time between running the check")
+		flHealthTimeout     = cmd.Duration([]string{"-health-timeout"}, 0, "Maximum time to allow one check to run")
+		flHealthRetries     = cmd.Int([]string{"-health-retries"}, 0, "Consecutive failures needed to report unhealthy")
+		flNoHealthcheck     = cmd.Bool([]string{"-no-healthcheck"}, false, "Disable any container-specified HEALTHCHECK")
+		flInit              = cmd.Bool([]string{"-init"}, false, "Run an init inside the container that forwards signals and reaps processes")
 	)
 
 	cmd.Var(&flAttach, []string{"a", "-attach"}, "Attach to STDIN, STDOUT or STDERR")
 	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume")
+	cmd.Var(&flTmpfs, []string{"-tmpfs"}, "Mount a tmpfs directory")
 	cmd.Var(&flLinks, []string{"#link", "-link"}, "Add link to another container")
 	cmd.Var(&flDevices, []string{"-device"}, "Add a host device to the container")
+	cmd.Var(&flBlkioWeightDevice, []string{"-blkio-weight-device"}, "Block IO weight (relative device weight)")
+	cmd.Var(&flDeviceReadBps, []string{"-device-read-bps"}, "Limit read rate (bytes per second) from a device")
+	cmd.Var(&flDeviceWriteBps, []string{"-device-write-bps"}, "Limit write rate (bytes per second) to a device")
+	cmd.Var(&flDeviceReadIOps, []string{"-device-read-iops"}, "Limit read rate (IO per second) from a device")
+	cmd.Var(&flDeviceWriteIOps, []string{"-device-write-iops"}, "Limit write rate (IO per second) to a device")
 	cmd.Var(&flLabels, []string{"l", "-label"}, "Set meta data on a container")
 	cmd.Var(&flLabelsFile, []string{"-label-file"}, "Read in a line delimited file of labels")
 	cmd.Var(&flEnv, []string{"e", "-env"}, "Set environment variables")
@@ -119,6 +148,8 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 	cmd.Var(&flCapDrop, []string{"-cap-drop"}, "Drop Linux capabilities")
 	cmd.Var(&flGroupAdd, []string{"-group-add"}, "Add additional groups to join")
 	cmd.Var(&flSecurityOpt, []string{"-security-opt"}, "Security Options")
+	cmd.Var(flSysctls, []string{"-sysctl"}, "Sysctl options")
+	cmd.Var(flStorageOpt, []string{"-storage-opt"}, "Storage driver options for the container")
 	cmd.Var(flUlimits, []string{"-ulimit"}, "Ulimit options")
 	cmd.Var(&flLoggingOpts, []string{"-log-opt"}, "Log driver options")
 
@@ -189,6 +220,17 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		}
 	}
 
+	var shmSize int64
+	if *flShmSize != "" {
+		shmSize, err = units.RAMInBytes(*flShmSize)
+		if err != nil {
+			return nil, nil, cmd, err
+		}
+		if shmSize <= 0 {
+			return nil, nil, cmd, fmt.Errorf("--shm-size: SHM size must be greater than 0")
+		}
+	}
+
 	swappiness := *flSwappiness
 	if swappiness != -1 && (swappiness < 0 || swappiness > 100) {
 		return nil, nil, cmd, fmt.Errorf("Invalid value: %d. Valid memory swappiness range is 0-100", swappiness)
@@ -278,6 +320,23 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		deviceMappings = append(deviceMappings, deviceMapping)
 	}
 
+	// parse tmpfs mounts, in the path[:options] form
+	tmpfs := make(map[string]string)
+	for _, t := range flTmpfs.GetAll() {
+		arr := strings.SplitN(t, ":", 2)
+		if !path.IsAbs(arr[0]) {
+			return nil, nil, cmd, fmt.Errorf("Invalid tmpfs destination %q: the path must be absolute", arr[0])
+		}
+		if len(arr) > 1 {
+			if _, _, err := mount.ParseTmpfsOptions(arr[1]); err != nil {
+				return nil, nil, cmd, err
+			}
+			tmpfs[arr[0]] = arr[1]
+		} else {
+			tmpfs[arr[0]] = ""
+		}
+	}
+
 	// collect all the environment variables for the container
 	envVariables, err := readKVStrings(flEnvFile.GetAll(), flEnv.GetAll())
 	if err != nil {
@@ -315,6 +374,23 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		return nil, nil, cmd, err
 	}
 
+	healthConfig, err := parseHealthConfig(*flHealthCmd, *flHealthInterval, *flHealthTimeout, *flHealthRetries, *flNoHealthcheck)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
+	// Without --stop-timeout, the timeout of the image or the default applies.
+	var stopTimeout *int
+	if cmd.IsSet("-stop-timeout") {
+		stopTimeout = flStopTimeout
+	}
+
+	// Without --init, the daemon default applies.
+	var useInit *bool
+	if cmd.IsSet("-init") {
+		useInit = flInit
+	}
+
 	config := &Config{
 		Hostname:        hostname,
 		Domainname:      domainname,
@@ -335,48 +411,61 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		WorkingDir:      *flWorkingDir,
 		Labels:          ConvertKVStringsToMap(labels),
 		StopSignal:      *flStopSignal,
+		StopTimeout:     stopTimeout,
+		Healthcheck:     healthConfig,
 	}
 
 	hostConfig := &HostConfig{
-		Binds:             binds,
-		ContainerIDFile:   *flContainerIDFile,
-		LxcConf:           lxcConf,
-		Memory:            flMemory,
-		MemoryReservation: MemoryReservation,
-		MemorySwap:        memorySwap,
-		KernelMemory:      KernelMemory,
-		CPUShares:         *flCPUShares,
-		CPUPeriod:         *flCPUPeriod,
-		CpusetCpus:        *flCpusetCpus,
-		CpusetMems:        *flCpusetMems,
-		CPUQuota:          *flCPUQuota,
-		BlkioWeight:       *flBlkioWeight,
-		OomKillDisable:    *flOomKillDisable,
-		MemorySwappiness:  flSwappiness,
-		Privileged:        *flPrivileged,
-		PortBindings:      portBindings,
-		Links:             flLinks.GetAll(),
-		PublishAllPorts:   *flPublishAll,
-		DNS:               flDNS.GetAll(),
-		DNSSearch:         flDNSSearch.GetAll(),
-		DNSOptions:        flDNSOptions.GetAll(),
-		ExtraHosts:        flExtraHosts.GetAll(),
-		VolumesFrom:       flVolumesFrom.GetAll(),
-		NetworkMode:       NetworkMode(*flNetMode),
-		IpcMode:           ipcMode,
-		PidMode:           pidMode,
-		UTSMode:           utsMode,
-		Devices:           deviceMappings,
-		CapAdd:            stringutils.NewStrSlice(flCapAdd.GetAll()...),
-		CapDrop:           stringutils.NewStrSlice(flCapDrop.GetAll()...),
-		GroupAdd:          flGroupAdd.GetAll(),
-		RestartPolicy:     restartPolicy,
-		SecurityOpt:       flSecurityOpt.GetAll(),
-		ReadonlyRootfs:    *flReadonlyRootfs,
-		Ulimits:           flUlimits.GetList(),
-		LogConfig:         LogConfig{Type: *flLoggingDriver, Config: loggingOpts},
-		CgroupParent:      *flCgroupParent,
-		VolumeDriver:      *flVolumeDriver,
+		Binds:                binds,
+		ContainerIDFile:      *flContainerIDFile,
+		LxcConf:              lxcConf,
+		Memory:               flMemory,
+		MemoryReservation:    MemoryReservation,
+		MemorySwap:           memorySwap,
+		KernelMemory:         KernelMemory,
+		CPUShares:            *flCPUShares,
+		CPUPeriod:            *flCPUPeriod,
+		CpusetCpus:           *flCpusetCpus,
+		CpusetMems:           *flCpusetMems,
+		CPUQuota:             *flCPUQuota,
+		BlkioWeight:          *flBlkioWeight,
+		BlkioWeightDevice:    flBlkioWeightDevice.GetList(),
+		BlkioDeviceReadBps:   flDeviceReadBps.GetList(),
+		BlkioDeviceWriteBps:  flDeviceWriteBps.GetList(),
+		BlkioDeviceReadIOps:  flDeviceReadIOps.GetList(),
+		BlkioDeviceWriteIOps: flDeviceWriteIOps.GetList(),
+		OomKillDisable:       *flOomKillDisable,
+		MemorySwappiness:     flSwappiness,
+		PidsLimit:            *flPidsLimit,
+		Privileged:           *flPrivileged,
+		PortBindings:         portBindings,
+		Links:                flLinks.GetAll(),
+		PublishAllPorts:      *flPublishAll,
+		DNS:                  flDNS.GetAll(),
+		DNSSearch:            flDNSSearch.GetAll(),
+		DNSOptions:           flDNSOptions.GetAll(),
+		ExtraHosts:           flExtraHosts.GetAll(),
+		VolumesFrom:          flVolumesFrom.GetAll(),
+		NetworkMode:          NetworkMode(*flNetMode),
+		IpcMode:              ipcMode,
+		ShmSize:              shmSize,
+		PidMode:              pidMode,
+		UTSMode:              utsMode,
+		Devices:              deviceMappings,
+		CapAdd:               stringutils.NewStrSlice(flCapAdd.GetAll()...),
+		CapDrop:              stringutils.NewStrSlice(flCapDrop.GetAll()...),
+		GroupAdd:             flGroupAdd.GetAll(),
+		RestartPolicy:        restartPolicy,
+		SecurityOpt:          flSecurityOpt.GetAll(),
+		ReadonlyRootfs:       *flReadonlyRootfs,
+		Init:                 useInit,
+		Tmpfs:                tmpfs,
+		Ulimits:              flUlimits.GetList(),
+		Sysctls:              flSysctls.GetAll(),
+		StorageOpt:           flStorageOpt.GetAll(),
+		LogConfig:            LogConfig{Type: *flLoggingDriver, Config: loggingOpts},
+		CgroupParent:         *flCgroupParent,
+		VolumeDriver:         *flVolumeDriver,
 	}
 
 	applyExperimentalFlags(expFlags, config, hostConfig)
@@ -427,6 +516,43 @@ func parseLoggingOpts(loggingDriver string, loggingOpts []string) (map[string]st
 	return loggingOptsMap, nil
 }
 
+// parseHealthConfig builds the healthcheck configuration from the --health-*
+// and --no-healthcheck flags. It returns nil when no option was given so that
+// the healthcheck of the image is inherited.
+func parseHealthConfig(cmd string, interval, timeout time.Duration, retries int, disable bool) (*HealthConfig, error) {
+	haveHealthSettings := cmd != "" || interval != 0 || timeout != 0 || retries != 0
+	if disable {
+		if haveHealthSettings {
+			return nil, fmt.Errorf("--no-healthcheck conflicts with --health-* options")
+		}
+		return &HealthConfig{Test: []string{"NONE"}}, nil
+	}
+	if !haveHealthSettings {
+		return nil, nil
+	}
+
+	if interval < 0 {
+		return nil, fmt.Errorf("--health-interval cannot be negative")
+	}
+	if timeout < 0 {
+		return nil, fmt.Errorf("--health-timeout cannot be negative")
+	}
+	if retries < 0 {
+		return nil, fmt.Errorf("--health-retries cannot be negative")
+	}
+
+	var test []string
+	if cmd != "" {
+		test = []string{"CMD-SHELL", cmd}
+	}
+	return &HealthConfig{
+		Test:     test,
+		Interval: interval,
+		Timeout:  timeout,
+		Retries:  retries,
+	}, nil
+}
+
 // ParseRestartPolicy returns the parsed policy or an error indicating what is incorrect
 func ParseRestartPolicy(policy string) (RestartPolicy, error) {
 	p := RestartPolicy{}
@@ -513,3 +639,81 @@ func ParseDevice(device string) (DeviceMapping, error) {
 	}
 	return deviceMapping, nil
 }
+
+// validateWeightDevice validates that the specified string has a valid
+// device-weight format, like /dev/sda:200.
+func validateWeightDevice(val string) (*blkiodev.WeightDevice, error) {
+	split := strings.SplitN(val, ":", 2)
+	if len(split) != 2 {
+		return nil, fmt.Errorf("bad format: %s", val)
+	}
+	if !strings.HasPrefix(split[0], "/dev/") {
+		return nil, fmt.Errorf("bad format for device path: %s", val)
+	}
+	weight, err := strconv.ParseUint(split[1], 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight for device: %s", val)
+	}
+	if weight > 0 && (weight < 10 || weight > 1000) {
+		return nil, fmt.Errorf("invalid weight for device: %s", val)
+	}
+
+	return &blkiodev.WeightDevice{
+		Path:   split[0],
+		Weight: uint16(weight),
+	}, nil
+}
+
+// validateStorageOpt validates that the specified string is a storage driver
+// option in the key=value form, like size=10G.
+func validateStorageOpt(val string) (string, error) {
+	if split := strings.SplitN(val, "=", 2); len(split) != 2 || split[0] == "" {
+		return "", fmt.Errorf("bad format for storage option: %s", val)
+	}
+	return val, nil
+}
+
+// validateThrottleBpsDevice validates that the specified string has a valid
+// device-rate format, like /dev/sda:1mb.
+func validateThrottleBpsDevice(val string) (*blkiodev.ThrottleDevice, error) {
+	split := strings.SplitN(val, ":", 2)
+	if len(split) != 2 {
+		return nil, fmt.Errorf("bad format: %s", val)
+	}
+	if !strings.HasPrefix(split[0], "/dev/") {
+		return nil, fmt.Errorf("bad format for device path: %s", val)
+	}
+	rate, err := units.RAMInBytes(split[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate for device: %s. The correct format is <device-path>:<number>[<unit>]. Number must be a positive integer. Unit is optional and can be kb, mb, or gb", val)
+	}
+	if rate < 0 {
+		return nil, fmt.Errorf("invalid rate for device: %s. The correct format is <device-path>:<number>[<unit>]. Number must be a positive integer. Unit is optional and can be kb, mb, or gb", val)
+	}
+
+	return &blkiodev.ThrottleDevice{
+		Path: split[0],
+		Rate: uint64(rate),
+	}, nil
+}
+
+// validateThrottleIOpsDevice validates that the specified string has a valid
+// device-rate format, like /dev/sda:1000.
+func validateThrottleIOpsDevice(val string) (*blkiodev.ThrottleDevice, error) {
+	split := strings.SplitN(val, ":", 2)
+	if len(split) != 2 {
+		return nil, fmt.Errorf("bad format: %s", val)
+	}
+	if !strings.HasPrefix(split[0], "/dev/") {
+		return nil, fmt.Errorf("bad format for device path: %s", val)
+	}
+	rate, err := strconv.ParseUint(split[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate for device: %s. The correct format is <device-path>:<number>. Number must be a positive integer", val)
+	}
+
+	return &blkiodev.ThrottleDevice{
+		Path: split[0],
+		Rate: rate,
+	}, nil
+}
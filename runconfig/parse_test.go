@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/nat"
@@ -242,6 +243,141 @@ func TestParseWithMemorySwap(t *testing.T) {
 	}
 }
 
+func TestParseWithPidsLimit(t *testing.T) {
+	if _, hostconfig := mustParse(t, ""); hostconfig.PidsLimit != 0 {
+		t.Fatalf("Expected the config to have no pids limit by default, got '%v'", hostconfig.PidsLimit)
+	}
+	if _, hostconfig := mustParse(t, "--pids-limit=100"); hostconfig.PidsLimit != 100 {
+		t.Fatalf("Expected the config to have '100' as PidsLimit, got '%v'", hostconfig.PidsLimit)
+	}
+	if _, hostconfig := mustParse(t, "--pids-limit=-1"); hostconfig.PidsLimit != -1 {
+		t.Fatalf("Expected the config to have '-1' as PidsLimit, got '%v'", hostconfig.PidsLimit)
+	}
+	if _, _, _, err := parseRun([]string{"--pids-limit=many", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with an invalid pids limit")
+	}
+}
+
+func TestParseWithShmSize(t *testing.T) {
+	if _, hostconfig := mustParse(t, ""); hostconfig.ShmSize != 0 {
+		t.Fatalf("Expected the config to have no shm size by default, got '%v'", hostconfig.ShmSize)
+	}
+	if _, hostconfig := mustParse(t, "--shm-size=128m"); hostconfig.ShmSize != 134217728 {
+		t.Fatalf("Expected the config to have '134217728' as ShmSize, got '%v'", hostconfig.ShmSize)
+	}
+	invalids := []string{"--shm-size=a128m", "--shm-size=0", "--shm-size=-1"}
+	for _, invalid := range invalids {
+		if _, _, _, err := parseRun([]string{invalid, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error with %s", invalid)
+		}
+	}
+}
+
+func TestParseWithBlkioDevices(t *testing.T) {
+	_, hostconfig := mustParse(t, "--blkio-weight-device=/dev/sda:300 --device-read-bps=/dev/sda:1mb --device-write-bps=/dev/sdb:1024 --device-read-iops=/dev/sda:100 --device-write-iops=/dev/sdb:200")
+	if len(hostconfig.BlkioWeightDevice) != 1 || hostconfig.BlkioWeightDevice[0].String() != "/dev/sda:300" {
+		t.Fatalf("Expected the config to have '/dev/sda:300' as BlkioWeightDevice, got '%v'", hostconfig.BlkioWeightDevice)
+	}
+	if len(hostconfig.BlkioDeviceReadBps) != 1 || hostconfig.BlkioDeviceReadBps[0].String() != "/dev/sda:1048576" {
+		t.Fatalf("Expected the config to have '/dev/sda:1048576' as BlkioDeviceReadBps, got '%v'", hostconfig.BlkioDeviceReadBps)
+	}
+	if len(hostconfig.BlkioDeviceWriteBps) != 1 || hostconfig.BlkioDeviceWriteBps[0].String() != "/dev/sdb:1024" {
+		t.Fatalf("Expected the config to have '/dev/sdb:1024' as BlkioDeviceWriteBps, got '%v'", hostconfig.BlkioDeviceWriteBps)
+	}
+	if len(hostconfig.BlkioDeviceReadIOps) != 1 || hostconfig.BlkioDeviceReadIOps[0].String() != "/dev/sda:100" {
+		t.Fatalf("Expected the config to have '/dev/sda:100' as BlkioDeviceReadIOps, got '%v'", hostconfig.BlkioDeviceReadIOps)
+	}
+	if len(hostconfig.BlkioDeviceWriteIOps) != 1 || hostconfig.BlkioDeviceWriteIOps[0].String() != "/dev/sdb:200" {
+		t.Fatalf("Expected the config to have '/dev/sdb:200' as BlkioDeviceWriteIOps, got '%v'", hostconfig.BlkioDeviceWriteIOps)
+	}
+
+	invalid := []string{
+		"--blkio-weight-device=/dev/sda",
+		"--blkio-weight-device=/dev/sda:5",
+		"--blkio-weight-device=/dev/sda:1001",
+		"--blkio-weight-device=sda:300",
+		"--device-read-bps=/dev/sda:1xb",
+		"--device-write-bps=/dev/sda:-1",
+		"--device-read-iops=/dev/sda:1mb",
+		"--device-write-iops=/dev/sda:-1",
+	}
+	for _, flag := range invalid {
+		if _, _, _, err := parseRun([]string{flag, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error with '%v'", flag)
+		}
+	}
+}
+
+func TestParseTmpfs(t *testing.T) {
+	_, hostconfig := mustParse(t, "--tmpfs=/run --tmpfs=/tmp:noexec,size=64m")
+	expected := map[string]string{"/run": "", "/tmp": "noexec,size=64m"}
+	if len(hostconfig.Tmpfs) != len(expected) {
+		t.Fatalf("Expected the config to have %v as Tmpfs, got %v", expected, hostconfig.Tmpfs)
+	}
+	for dest, options := range expected {
+		if hostconfig.Tmpfs[dest] != options {
+			t.Fatalf("Expected the config to have %v as Tmpfs, got %v", expected, hostconfig.Tmpfs)
+		}
+	}
+
+	if _, _, _, err := parseRun([]string{"--tmpfs=tmp", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with a relative tmpfs destination")
+	}
+	if _, _, _, err := parseRun([]string{"--tmpfs=/tmp:foo=bar", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with an invalid tmpfs option")
+	}
+}
+
+func TestParseSysctls(t *testing.T) {
+	_, hostconfig := mustParse(t, "--sysctl=net.core.somaxconn=1024 --sysctl=kernel.shmmax=68719476736")
+	expected := map[string]string{"net.core.somaxconn": "1024", "kernel.shmmax": "68719476736"}
+	if len(hostconfig.Sysctls) != len(expected) {
+		t.Fatalf("Expected the config to have %v as Sysctls, got %v", expected, hostconfig.Sysctls)
+	}
+	for key, value := range expected {
+		if hostconfig.Sysctls[key] != value {
+			t.Fatalf("Expected the config to have %v as Sysctls, got %v", expected, hostconfig.Sysctls)
+		}
+	}
+	if _, _, _, err := parseRun([]string{"--sysctl=kernel.hostname=foo", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with a sysctl which is not namespaced")
+	}
+}
+
+func TestParseStorageOpt(t *testing.T) {
+	_, hostconfig := mustParse(t, "--storage-opt size=20G")
+	if len(hostconfig.StorageOpt) != 1 || hostconfig.StorageOpt["size"] != "20G" {
+		t.Fatalf("Expected the config to have size=20G as StorageOpt, got %v", hostconfig.StorageOpt)
+	}
+	if _, _, _, err := parseRun([]string{"--storage-opt=size", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with a storage option without a value")
+	}
+}
+
+func TestParseStopTimeout(t *testing.T) {
+	if config, _ := mustParse(t, ""); config.StopTimeout != nil {
+		t.Fatalf("Expected the config to have no stop timeout by default, got '%v'", *config.StopTimeout)
+	}
+	if config, _ := mustParse(t, "--stop-timeout=30"); config.StopTimeout == nil || *config.StopTimeout != 30 {
+		t.Fatalf("Expected the config to have '30' as StopTimeout, got '%v'", config.StopTimeout)
+	}
+	if _, _, _, err := parseRun([]string{"--stop-timeout=long", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with an invalid stop timeout")
+	}
+}
+
+func TestParseInit(t *testing.T) {
+	if _, hostconfig := mustParse(t, ""); hostconfig.Init != nil {
+		t.Fatalf("Expected the config to leave Init to the daemon by default, got '%v'", *hostconfig.Init)
+	}
+	if _, hostconfig := mustParse(t, "--init"); hostconfig.Init == nil || !*hostconfig.Init {
+		t.Fatalf("Expected the config to have Init set to true, got '%v'", hostconfig.Init)
+	}
+	if _, hostconfig := mustParse(t, "--init=false"); hostconfig.Init == nil || *hostconfig.Init {
+		t.Fatalf("Expected the config to have Init set to false, got '%v'", hostconfig.Init)
+	}
+}
+
 func TestParseHostname(t *testing.T) {
 	hostname := "--hostname=hostname"
 	hostnameWithDomain := "--hostname=hostname.domainname"
@@ -490,3 +626,49 @@ func TestParseEntryPoint(t *testing.T) {
 		t.Fatalf("Expected entrypoint 'anything', got %v", config.Entrypoint)
 	}
 }
+
+func TestParseHealth(t *testing.T) {
+	checkOk := func(args ...string) *HealthConfig {
+		config, _, _, err := parseRun(args)
+		if err != nil {
+			t.Fatalf("%#v: %v", args, err)
+		}
+		return config.Healthcheck
+	}
+	checkError := func(expected string, args ...string) {
+		config, _, _, err := parseRun(args)
+		if err == nil {
+			t.Fatalf("Expected error, but got %#v", config)
+		}
+		if err.Error() != expected {
+			t.Fatalf("Expected %#v, got %#v", expected, err)
+		}
+	}
+
+	health := checkOk("--no-healthcheck", "img", "cmd")
+	if health == nil || len(health.Test) != 1 || health.Test[0] != "NONE" {
+		t.Fatalf("--no-healthcheck failed: %#v", health)
+	}
+
+	health = checkOk("--health-cmd=/check.sh -q", "img", "cmd")
+	if len(health.Test) != 2 || health.Test[0] != "CMD-SHELL" || health.Test[1] != "/check.sh -q" {
+		t.Fatalf("--health-cmd: got %#v", health.Test)
+	}
+	if health.Timeout != 0 {
+		t.Fatalf("--health-cmd: timeout = %s", health.Timeout)
+	}
+
+	checkError("--no-healthcheck conflicts with --health-* options",
+		"--no-healthcheck", "--health-cmd=/check.sh -q", "img", "cmd")
+
+	health = checkOk("--health-timeout=2s", "--health-retries=3", "--health-interval=4.5s", "img", "cmd")
+	if health.Timeout != 2*time.Second || health.Retries != 3 || health.Interval != 4500*time.Millisecond {
+		t.Fatalf("--health-*: got %#v", health)
+	}
+
+	checkError("--health-retries cannot be negative", "--health-retries=-1", "img", "cmd")
+
+	if health := checkOk("img", "cmd"); health != nil {
+		t.Fatalf("Expected no healthcheck by default, got %#v", health)
+	}
+}
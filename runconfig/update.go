@@ -0,0 +1,84 @@
+package runconfig
+
+import (
+	"fmt"
+
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/units"
+)
+
+// UpdateConfig holds the resource limits that can be changed on an existing
+// container. Fields left at their zero value are not modified.
+type UpdateConfig struct {
+	BlkioWeight  int64  // Block IO weight (relative weight vs. other containers)
+	CPUShares    int64  `json:"CpuShares"` // CPU shares (relative weight vs. other containers)
+	CPUQuota     int64  `json:"CpuQuota"`  // CPU CFS (Completely Fair Scheduler) quota
+	CpusetCpus   string // CpusetCpus 0-2, 0,1
+	Memory       int64  // Memory limit (in bytes)
+	MemorySwap   int64  // Total memory usage (memory + swap); set `-1` to disable swap
+	KernelMemory int64  // Kernel memory limit (in bytes)
+}
+
+// IsEmpty returns true if the UpdateConfig does not change any resource.
+func (c *UpdateConfig) IsEmpty() bool {
+	return *c == UpdateConfig{}
+}
+
+// ParseUpdate parses the specified args for the update command and generates
+// an UpdateConfig from it, together with the list of containers to update.
+// If no resource flag is specified or if specified args are not valid, it
+// will return an error.
+func ParseUpdate(cmd *flag.FlagSet, args []string) (*UpdateConfig, []string, error) {
+	var (
+		flBlkioWeight  = cmd.Int64([]string{"-blkio-weight"}, 0, "Block IO (relative weight), between 10 and 1000")
+		flCPUShares    = cmd.Int64([]string{"#c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
+		flCPUQuota     = cmd.Int64([]string{"-cpu-quota"}, 0, "Limit CPU CFS (Completely Fair Scheduler) quota")
+		flCpusetCpus   = cmd.String([]string{"-cpuset-cpus"}, "", "CPUs in which to allow execution (0-3, 0,1)")
+		flMemoryString = cmd.String([]string{"m", "-memory"}, "", "Memory limit")
+		flMemorySwap   = cmd.String([]string{"-memory-swap"}, "", "Total memory (memory + swap), '-1' to disable swap")
+		flKernelMemory = cmd.String([]string{"-kernel-memory"}, "", "Kernel memory limit")
+		err            error
+	)
+	cmd.Require(flag.Min, 1)
+	if err := cmd.ParseFlags(args, true); err != nil {
+		return nil, nil, err
+	}
+
+	config := &UpdateConfig{
+		BlkioWeight: *flBlkioWeight,
+		CPUShares:   *flCPUShares,
+		CPUQuota:    *flCPUQuota,
+		CpusetCpus:  *flCpusetCpus,
+	}
+
+	if *flMemoryString != "" {
+		config.Memory, err = units.RAMInBytes(*flMemoryString)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if *flMemorySwap != "" {
+		if *flMemorySwap == "-1" {
+			config.MemorySwap = -1
+		} else {
+			config.MemorySwap, err = units.RAMInBytes(*flMemorySwap)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if *flKernelMemory != "" {
+		config.KernelMemory, err = units.RAMInBytes(*flKernelMemory)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if config.IsEmpty() {
+		return nil, nil, fmt.Errorf("You must provide one or more flags when using this command.")
+	}
+
+	return config, cmd.Args(), nil
+}
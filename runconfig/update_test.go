@@ -0,0 +1,59 @@
+package runconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	flag "github.com/docker/docker/pkg/mflag"
+)
+
+func TestParseUpdate(t *testing.T) {
+	invalids := map[*arguments]error{
+		&arguments{[]string{"container"}}:                        fmt.Errorf("You must provide one or more flags when using this command."),
+		&arguments{[]string{"-unknown", "container"}}:            fmt.Errorf("flag provided but not defined: -unknown"),
+		&arguments{[]string{"--memory", "invalid", "container"}}: fmt.Errorf("invalid size: 'invalid'"),
+	}
+	valids := map[*arguments]*UpdateConfig{
+		&arguments{
+			[]string{"--cpu-shares", "512", "--cpu-quota", "50000", "--cpuset-cpus", "0-1", "container"},
+		}: {
+			CPUShares:  512,
+			CPUQuota:   50000,
+			CpusetCpus: "0-1",
+		},
+		&arguments{
+			[]string{"-m", "64m", "--memory-swap", "-1", "--kernel-memory", "32m", "--blkio-weight", "300", "container"},
+		}: {
+			Memory:       64 * 1024 * 1024,
+			MemorySwap:   -1,
+			KernelMemory: 32 * 1024 * 1024,
+			BlkioWeight:  300,
+		},
+	}
+	for invalid, expectedError := range invalids {
+		cmd := flag.NewFlagSet("update", flag.ContinueOnError)
+		cmd.ShortUsage = func() {}
+		cmd.SetOutput(ioutil.Discard)
+		_, _, err := ParseUpdate(cmd, invalid.args)
+		if err == nil || err.Error() != expectedError.Error() {
+			t.Fatalf("Expected an error [%v] for %v, got %v", expectedError, invalid, err)
+		}
+	}
+	for valid, expectedUpdateConfig := range valids {
+		cmd := flag.NewFlagSet("update", flag.ContinueOnError)
+		cmd.ShortUsage = func() {}
+		cmd.SetOutput(ioutil.Discard)
+		updateConfig, containers, err := ParseUpdate(cmd, valid.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expectedUpdateConfig, updateConfig) {
+			t.Fatalf("Expected [%v] for %v, got [%v]", expectedUpdateConfig, valid, updateConfig)
+		}
+		if len(containers) != 1 || containers[0] != "container" {
+			t.Fatalf("Expected [container] for %v, got %v", valid, containers)
+		}
+	}
+}
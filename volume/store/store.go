@@ -1,8 +1,12 @@
 package store
 
 import (
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/volume"
@@ -20,14 +24,25 @@ var (
 // reference counting of volumes in the system.
 func New() *VolumeStore {
 	return &VolumeStore{
-		vols: make(map[string]*volumeCounter),
+		vols:     make(map[string]*volumeCounter),
+		metadata: make(map[string]*volumeMetadata),
 	}
 }
 
 // VolumeStore is a struct that stores the list of volumes available and keeps track of their usage counts
 type VolumeStore struct {
-	vols map[string]*volumeCounter
-	mu   sync.Mutex
+	vols         map[string]*volumeCounter
+	metadata     map[string]*volumeMetadata // by volume name
+	metadataPath string                     // where the metadata is saved, if set
+	mu           sync.Mutex
+}
+
+// volumeMetadata is what the store knows of a volume besides its driver.
+// The creation time of the volumes which the store did not create is the
+// time it first listed them.
+type volumeMetadata struct {
+	Labels    map[string]string
+	CreatedAt time.Time
 }
 
 // volumeCounter keeps track of references to a volume
@@ -36,15 +51,74 @@ type volumeCounter struct {
 	count uint
 }
 
+// LoadMetadata loads the labels and the creation times of the volumes from
+// the file at path, where the store saves them from then on.
+func (s *VolumeStore) LoadMetadata(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metadataPath = path
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s.saveMetadata()
+		}
+		return err
+	}
+	metadata := make(map[string]*volumeMetadata)
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return err
+	}
+	for name, m := range metadata {
+		if _, exists := s.vols[name]; exists {
+			s.metadata[name] = m
+		}
+	}
+	return s.saveMetadata()
+}
+
+// saveMetadata saves the metadata of the volumes, if the store has a path to
+// save it to. It is called with the lock held.
+func (s *VolumeStore) saveMetadata() error {
+	if s.metadataPath == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.metadata)
+	if err != nil {
+		return err
+	}
+	tmp := s.metadataPath + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.metadataPath)
+}
+
+// addVolume adds a volume to the store along with its metadata, if it is not
+// known yet. It is called with the lock held.
+func (s *VolumeStore) addVolume(v volume.Volume, count uint, labels map[string]string) {
+	s.vols[v.Name()] = &volumeCounter{v, count}
+	if _, exists := s.metadata[v.Name()]; exists {
+		return
+	}
+	s.metadata[v.Name()] = &volumeMetadata{Labels: labels, CreatedAt: time.Now().UTC()}
+	if err := s.saveMetadata(); err != nil {
+		logrus.Errorf("Failed to save the metadata of volume %s: %v", v.Name(), err)
+	}
+}
+
 // AddAll adds a list of volumes to the store
 func (s *VolumeStore) AddAll(vols []volume.Volume) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	for _, v := range vols {
-		s.vols[v.Name()] = &volumeCounter{v, 0}
+		s.addVolume(v, 0, nil)
 	}
 }
 
-// Create tries to find an existing volume with the given name or create a new one from the passed in driver
-func (s *VolumeStore) Create(name, driverName string, opts map[string]string) (volume.Volume, error) {
+// Create tries to find an existing volume with the given name or create a new one from the passed in driver.
+// The labels are set on the volume only if it is created.
+func (s *VolumeStore) Create(name, driverName string, opts, labels map[string]string) (volume.Volume, error) {
 	s.mu.Lock()
 	if vc, exists := s.vols[name]; exists {
 		v := vc.Volume
@@ -65,7 +139,7 @@ func (s *VolumeStore) Create(name, driverName string, opts map[string]string) (v
 	}
 
 	s.mu.Lock()
-	s.vols[v.Name()] = &volumeCounter{v, 0}
+	s.addVolume(v, 0, labels)
 	s.mu.Unlock()
 
 	return v, nil
@@ -105,6 +179,10 @@ func (s *VolumeStore) Remove(v volume.Volume) error {
 		return err
 	}
 	delete(s.vols, name)
+	delete(s.metadata, name)
+	if err := s.saveMetadata(); err != nil {
+		logrus.Errorf("Failed to save the metadata of volume %s: %v", name, err)
+	}
 	return nil
 }
 
@@ -116,7 +194,7 @@ func (s *VolumeStore) Increment(v volume.Volume) {
 
 	vc, exists := s.vols[v.Name()]
 	if !exists {
-		s.vols[v.Name()] = &volumeCounter{v, 1}
+		s.addVolume(v, 1, nil)
 		return
 	}
 	vc.count++
@@ -149,6 +227,27 @@ func (s *VolumeStore) Count(v volume.Volume) uint {
 	return vc.count
 }
 
+// Labels returns the labels of the volume.
+func (s *VolumeStore) Labels(v volume.Volume) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, exists := s.metadata[v.Name()]; exists {
+		return m.Labels
+	}
+	return nil
+}
+
+// CreatedAt returns the creation time of the volume, or the time the store
+// first listed it if it did not create it.
+func (s *VolumeStore) CreatedAt(v volume.Volume) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, exists := s.metadata[v.Name()]; exists {
+		return m.CreatedAt
+	}
+	return time.Time{}
+}
+
 // List returns all the available volumes
 func (s *VolumeStore) List() []volume.Volume {
 	s.mu.Lock()
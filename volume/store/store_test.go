@@ -1,6 +1,10 @@
 package store
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/docker/docker/volume"
@@ -38,7 +42,7 @@ func TestGet(t *testing.T) {
 func TestCreate(t *testing.T) {
 	volumedrivers.Register(vt.FakeDriver{}, "fake")
 	s := New()
-	v, err := s.Create("fake1", "fake", nil)
+	v, err := s.Create("fake1", "fake", nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -49,11 +53,11 @@ func TestCreate(t *testing.T) {
 		t.Fatalf("Expected 1 volume in the store, got %v: %v", len(l), l)
 	}
 
-	if _, err := s.Create("none", "none", nil); err == nil {
+	if _, err := s.Create("none", "none", nil, nil); err == nil {
 		t.Fatalf("Expected unknown driver error, got nil")
 	}
 
-	_, err = s.Create("fakeError", "fake", map[string]string{"error": "create error"})
+	_, err = s.Create("fakeError", "fake", map[string]string{"error": "create error"}, nil)
 	if err == nil || err.Error() != "create error" {
 		t.Fatalf("Expected create error, got %v", err)
 	}
@@ -65,7 +69,7 @@ func TestRemove(t *testing.T) {
 	if err := s.Remove(vt.NoopVolume{}); err != ErrNoSuchVolume {
 		t.Fatalf("Expected ErrNoSuchVolume error, got %v", err)
 	}
-	v, err := s.Create("fake1", "fake", nil)
+	v, err := s.Create("fake1", "fake", nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -150,3 +154,53 @@ func TestFilterByDriver(t *testing.T) {
 		t.Fatalf("Expected 1 volume, got %v, %v", len(l), l)
 	}
 }
+
+func TestMetadata(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-volume-store-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, "metadata.json")
+
+	volumedrivers.Register(vt.FakeDriver{}, "fake")
+	s := New()
+	if err := s.LoadMetadata(path); err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string]string{"foo": "bar"}
+	v, err := s.Create("fake1", "fake", nil, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := s.Labels(v); !reflect.DeepEqual(l, labels) {
+		t.Fatalf("Expected labels %v, got %v", labels, l)
+	}
+	created := s.CreatedAt(v)
+	if created.IsZero() {
+		t.Fatal("Expected a creation time")
+	}
+
+	// The metadata of the volumes is kept across the stores.
+	s = New()
+	s.AddAll([]volume.Volume{v, vt.NewFakeVolume("fake2")})
+	if err := s.LoadMetadata(path); err != nil {
+		t.Fatal(err)
+	}
+	if l := s.Labels(v); !reflect.DeepEqual(l, labels) {
+		t.Fatalf("Expected labels %v, got %v", labels, l)
+	}
+	if c := s.CreatedAt(v); !c.Equal(created) {
+		t.Fatalf("Expected the creation time %v, got %v", created, c)
+	}
+	if c := s.CreatedAt(vt.NewFakeVolume("fake2")); c.IsZero() {
+		t.Fatal("Expected the time the volume was listed as its creation time")
+	}
+
+	if err := s.Remove(v); err != nil {
+		t.Fatal(err)
+	}
+	if l := s.Labels(v); l != nil {
+		t.Fatalf("Expected no labels for a removed volume, got %v", l)
+	}
+}